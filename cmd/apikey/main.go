@@ -0,0 +1,64 @@
+// Command apikey creates API key, the first admin key can be made only this way
+//
+//	go run cmd/apikey/main.go -name deploy -scopes admin
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/TRAD3R/tlog"
+	"github.com/trad3r/hskills/apirest/internal/config"
+	"github.com/trad3r/hskills/apirest/internal/repository/filters"
+	"github.com/trad3r/hskills/apirest/internal/service"
+	"github.com/trad3r/hskills/apirest/internal/storage"
+)
+
+func main() {
+	name := flag.String("name", "", "key name shown in key list")
+	scopes := flag.String("scopes", "", "comma separated scopes, e.g. posts:read,posts:write")
+	ttl := flag.Duration("ttl", 0, "key lifetime, the key never expires when zero")
+	flag.Parse()
+
+	if err := run(*name, *scopes, *ttl); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(name, scopes string, ttl time.Duration) error {
+	cfg := config.GetConfig()
+	logger := tlog.GetLogger(cfg.IsDebug)
+	ctx := context.Background()
+
+	db, err := storage.NewDB(ctx, cfg.DB.Url)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	addReq := filters.APIKeyAddRequest{Name: name}
+	if len(scopes) > 0 {
+		addReq.Scopes = strings.Split(scopes, ",")
+	}
+
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl)
+		addReq.ExpiresAt = &expiresAt
+	}
+
+	key, err := service.NewAPIKeyService(logger, db).APIKeyCreate(ctx, addReq)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(key)
+}
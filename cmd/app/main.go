@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,9 +12,14 @@ import (
 	"time"
 
 	"github.com/TRAD3R/tlog"
+	"github.com/trad3r/hskills/apirest/internal/auth"
+	"github.com/trad3r/hskills/apirest/internal/blobstore"
 	"github.com/trad3r/hskills/apirest/internal/config"
 	"github.com/trad3r/hskills/apirest/internal/handler"
+	"github.com/trad3r/hskills/apirest/internal/jobs"
+	"github.com/trad3r/hskills/apirest/internal/markdown"
 	"github.com/trad3r/hskills/apirest/internal/migrator"
+	"github.com/trad3r/hskills/apirest/internal/repository/postgres"
 	"github.com/trad3r/hskills/apirest/internal/service"
 	"github.com/trad3r/hskills/apirest/internal/storage"
 )
@@ -40,10 +46,52 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err := postgres.ConfigureSearch(ctx, db, cfg.Search.Config); err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
 	u := service.NewUserService(logger, db)
-	p := service.NewPostService(logger, db)
+	p := service.NewPostService(logger, db, markdown.New(cfg.Markdown.CacheSize))
 	up := service.NewUserPostService(u, p)
-	h := handler.NewHandler(u, p, up)
+	a := service.NewAuditService(logger, db)
+	t := service.NewTagService(logger, db)
+	c := service.NewCommentService(logger, db)
+	r := service.NewReactionService(logger, db)
+
+	store, err := newBlobStore(ctx, cfg)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	at := service.NewAttachmentService(logger, db, store, service.AttachmentLimits{
+		MaxSize:      cfg.Attachments.MaxSize,
+		AllowedTypes: cfg.Attachments.AllowedTypes,
+	})
+
+	if len(cfg.Auth.Secret) < 32 {
+		logger.Error("auth secret must be at least 32 bytes")
+		os.Exit(1)
+	}
+
+	au, err := service.NewAuthService(logger, db, auth.NewIssuer(cfg.Auth.Secret, cfg.Auth.AccessTTL), cfg.Auth.RefreshTTL)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	ak := service.NewAPIKeyService(logger, db)
+	h := handler.NewHandler(u, p, up, a, t, c, r, at, au, ak)
+
+	purger := jobs.NewPurger(logger, db, cfg.Purge.Retention)
+	go purger.Run(ctx, cfg.Purge.Interval)
+
+	publisher := jobs.NewPublisher(logger, db)
+	go publisher.Run(ctx, cfg.Scheduler.Interval)
+
+	cleaner := jobs.NewAttachmentCleaner(logger, db, store)
+	go cleaner.Run(ctx, cfg.Attachments.CleanupInterval)
 
 	logger.Info("listening on port 8080")
 
@@ -51,8 +99,8 @@ func main() {
 	s := http.Server{
 		Addr:              ":8080",
 		Handler:           h.Handlers(),
-		ReadTimeout:       time.Second * 3,
-		ReadHeaderTimeout: 0,
+		ReadTimeout:       time.Second * 3, // uploads and imports extend it for their bodies
+		ReadHeaderTimeout: time.Second * 3,
 		WriteTimeout:      0,
 		IdleTimeout:       0,
 		MaxHeaderBytes:    1e6,
@@ -71,3 +119,22 @@ func main() {
 		logger.Error("error closing server", "err", err.Error())
 	}
 }
+
+// newBlobStore opens attachment storage of configured backend
+func newBlobStore(ctx context.Context, cfg *config.Config) (blobstore.Store, error) {
+	switch cfg.Storage.Backend {
+	case "local":
+		return blobstore.NewLocal(cfg.Storage.Path)
+	case "s3":
+		return blobstore.NewS3(ctx, blobstore.S3Options{
+			Endpoint:  cfg.Storage.S3.Endpoint,
+			Region:    cfg.Storage.S3.Region,
+			AccessKey: cfg.Storage.S3.AccessKey,
+			SecretKey: cfg.Storage.S3.SecretKey,
+			Bucket:    cfg.Storage.S3.Bucket,
+			UseSSL:    cfg.Storage.S3.UseSSL,
+		})
+	}
+
+	return nil, fmt.Errorf("unknown storage backend %q", cfg.Storage.Backend)
+}
@@ -0,0 +1,97 @@
+// Command import loads users or posts from CSV or NDJSON file and prints validation report
+//
+//	go run cmd/import/main.go -type users -file users.csv -dry-run
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/TRAD3R/tlog"
+	"github.com/trad3r/hskills/apirest/internal/config"
+	"github.com/trad3r/hskills/apirest/internal/dataformat"
+	"github.com/trad3r/hskills/apirest/internal/markdown"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/service"
+	"github.com/trad3r/hskills/apirest/internal/storage"
+)
+
+func main() {
+	kind := flag.String("type", "", "imported data: users or posts")
+	file := flag.String("file", "", "path to .csv or .ndjson file")
+	dryRun := flag.Bool("dry-run", false, "validate rows without writing")
+	partial := flag.Bool("partial", false, "write valid rows even if some rows fail")
+	flag.Parse()
+
+	if err := run(*kind, *file, service.ImportOptions{DryRun: *dryRun, Atomic: !*partial}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(kind, file string, opts service.ImportOptions) error {
+	format, err := fileFormat(file)
+	if err != nil {
+		return err
+	}
+
+	cfg := config.GetConfig()
+	logger := tlog.GetLogger(cfg.IsDebug)
+	ctx := context.Background()
+
+	db, err := storage.NewDB(ctx, cfg.DB.Url)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	u := service.NewUserService(logger, db)
+	p := service.NewPostService(logger, db, markdown.New(cfg.Markdown.CacheSize))
+	up := service.NewUserPostService(u, p)
+
+	var report *models.ImportReport
+	switch kind {
+	case "users":
+		report, err = u.UserImportFrom(ctx, format, f, opts)
+	case "posts":
+		report, err = up.ImportPostsFrom(ctx, format, f, opts)
+	default:
+		return fmt.Errorf("unknown type %q, expected users or posts", kind)
+	}
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+
+	if report.Failed > 0 {
+		return fmt.Errorf("%d of %d rows failed", report.Failed, report.Total)
+	}
+
+	return nil
+}
+
+func fileFormat(file string) (string, error) {
+	switch filepath.Ext(file) {
+	case ".csv":
+		return dataformat.MIMECSV, nil
+	case ".ndjson", ".jsonl":
+		return dataformat.MIMENDJSON, nil
+	}
+
+	return "", fmt.Errorf("unknown format of %q, expected .csv or .ndjson file", file)
+}
@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// APIKeyPrefix starts every API key, so keys are told from access tokens and found by secret scanners
+const APIKeyPrefix = "ak_"
+
+// NewAPIKey returns random API key, its visible prefix and hash, only prefix and hash are stored.
+// Key looks like ak_<8 hex>_<secret>, prefix is ak_<8 hex>
+func NewAPIKey() (key string, prefix string, hash string, err error) {
+	id := make([]byte, 4)
+	secret := make([]byte, 32)
+
+	if _, err := rand.Read(id); err != nil {
+		return "", "", "", fmt.Errorf("error while generating API key: %w", err)
+	}
+
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", fmt.Errorf("error while generating API key: %w", err)
+	}
+
+	prefix = APIKeyPrefix + hex.EncodeToString(id)
+	key = prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+
+	return key, prefix, HashToken(key), nil
+}
+
+// IsAPIKey reports whether bearer token is an API key
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
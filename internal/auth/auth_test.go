@@ -0,0 +1,136 @@
+package auth_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trad3r/hskills/apirest/internal/auth"
+)
+
+func TestVerifyPassword(t *testing.T) {
+	t.Parallel()
+
+	hash, err := auth.HashPassword("correct horse")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$"))
+
+	other, err := auth.HashPassword("correct horse")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, other, "salt must differ")
+
+	testCases := []struct {
+		name     string
+		password string
+		hash     string
+		match    bool
+		err      error
+	}{
+		{name: "match", password: "correct horse", hash: hash, match: true},
+		{name: "mismatch", password: "battery staple", hash: hash},
+		{name: "empty password", password: "", hash: hash},
+		{name: "other algorithm", password: "correct horse", hash: strings.Replace(hash, "argon2id", "argon2i", 1), err: auth.ErrMalformedHash},
+		{name: "truncated", password: "correct horse", hash: hash[:strings.LastIndex(hash, "$")], err: auth.ErrMalformedHash},
+		{name: "not a hash", password: "correct horse", hash: "correct horse", err: auth.ErrMalformedHash},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			match, err := auth.VerifyPassword(tc.password, tc.hash)
+			assert.ErrorIs(t, err, tc.err)
+			assert.Equal(t, tc.match, match)
+		})
+	}
+}
+
+func TestIssuer(t *testing.T) {
+	t.Parallel()
+
+	issuer := auth.NewIssuer("0123456789abcdef0123456789abcdef", 15*time.Minute)
+
+	valid, err := issuer.Issue(7, 3, time.Now())
+	require.NoError(t, err)
+
+	expired, err := issuer.Issue(7, 3, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+
+	foreign, err := auth.NewIssuer("fedcba9876543210fedcba9876543210", time.Minute).Issue(7, 3, time.Now())
+	require.NoError(t, err)
+
+	claims, err := issuer.Parse(valid)
+	require.NoError(t, err)
+	assert.Equal(t, 7, claims.UserID())
+	assert.Equal(t, 3, claims.SessionID)
+
+	for name, token := range map[string]string{
+		"expired":      expired,
+		"other secret": foreign,
+		"tampered":     valid[:len(valid)-2] + "xx",
+		"garbage":      "not.a.token",
+	} {
+		_, err := issuer.Parse(token)
+		assert.ErrorIs(t, err, auth.ErrInvalidToken, name)
+	}
+}
+
+func TestNewRefreshToken(t *testing.T) {
+	t.Parallel()
+
+	token, hash, err := auth.NewRefreshToken()
+	require.NoError(t, err)
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, auth.HashToken(token))
+
+	other, _, err := auth.NewRefreshToken()
+	require.NoError(t, err)
+	assert.NotEqual(t, token, other)
+}
+
+func TestNewAPIKey(t *testing.T) {
+	t.Parallel()
+
+	key, prefix, hash, err := auth.NewAPIKey()
+	require.NoError(t, err)
+
+	assert.True(t, auth.IsAPIKey(key))
+	assert.True(t, strings.HasPrefix(key, prefix+"_"))
+	assert.Len(t, prefix, len(auth.APIKeyPrefix)+8)
+	assert.Equal(t, hash, auth.HashToken(key))
+
+	other, otherPrefix, _, err := auth.NewAPIKey()
+	require.NoError(t, err)
+	assert.NotEqual(t, key, other)
+	assert.NotEqual(t, prefix, otherPrefix)
+}
+
+func TestAllows(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		granted  []string
+		required string
+		allowed  bool
+	}{
+		{name: "granted", granted: []string{auth.ScopePostsRead}, required: auth.ScopePostsRead, allowed: true},
+		{name: "read does not grant write", granted: []string{auth.ScopePostsRead}, required: auth.ScopePostsWrite},
+		{name: "other resource", granted: []string{auth.ScopeUsersWrite}, required: auth.ScopePostsWrite},
+		{name: "admin grants everything", granted: []string{auth.ScopeAdmin}, required: auth.ScopeUsersWrite, allowed: true},
+		{name: "users are not admins", granted: auth.UserScopes, required: auth.ScopeAdmin},
+		{name: "anonymous reads", granted: auth.AnonymousScopes, required: auth.ScopePostsRead, allowed: true},
+		{name: "anonymous does not write", granted: auth.AnonymousScopes, required: auth.ScopePostsWrite},
+		{name: "nothing granted", required: auth.ScopeUsersRead},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.allowed, auth.Allows(tc.granted, tc.required))
+		})
+	}
+}
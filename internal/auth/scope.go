@@ -0,0 +1,29 @@
+package auth
+
+import "slices"
+
+const (
+	ScopeUsersRead  = "users:read"
+	ScopeUsersWrite = "users:write"
+	ScopePostsRead  = "posts:read"
+	ScopePostsWrite = "posts:write"
+	// ScopePostsModerate makes API key a moderator of posts of any author, keys own no posts
+	ScopePostsModerate = "posts:moderate"
+	// ScopeAdmin grants every other scope
+	ScopeAdmin = "admin"
+)
+
+// Scopes are scopes API keys may be given
+var Scopes = []string{ScopeUsersRead, ScopeUsersWrite, ScopePostsRead, ScopePostsWrite, ScopePostsModerate, ScopeAdmin}
+
+var (
+	// AnonymousScopes are granted to requests without credentials
+	AnonymousScopes = []string{ScopeUsersRead, ScopePostsRead}
+	// UserScopes are granted to users logged in with password
+	UserScopes = []string{ScopeUsersRead, ScopeUsersWrite, ScopePostsRead, ScopePostsWrite}
+)
+
+// Allows reports whether granted scopes include required one
+func Allows(granted []string, required string) bool {
+	return slices.Contains(granted, required) || slices.Contains(granted, ScopeAdmin)
+}
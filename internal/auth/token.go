@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const issuer = "apirest"
+
+var ErrInvalidToken = errors.New("token is invalid or expired")
+
+// Claims are claims of access token, subject is user ID
+type Claims struct {
+	SessionID int `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+// UserID returns ID of token subject
+func (c Claims) UserID() int {
+	id, _ := strconv.Atoi(c.Subject)
+	return id
+}
+
+// Issuer signs and verifies HS256 access tokens
+type Issuer struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+func NewIssuer(secret string, ttl time.Duration) *Issuer {
+	return &Issuer{
+		secret: []byte(secret),
+		ttl:    ttl,
+	}
+}
+
+// TTL is lifetime of issued tokens
+func (i *Issuer) TTL() time.Duration {
+	return i.ttl
+}
+
+// Issue returns access token of user session valid from now
+func (i *Issuer) Issue(userID int, sessionID int, now time.Time) (string, error) {
+	claims := Claims{
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Subject:   strconv.Itoa(userID),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(i.ttl)),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(i.secret)
+	if err != nil {
+		return "", fmt.Errorf("error while signing token: %w", err)
+	}
+
+	return token, nil
+}
+
+// Parse verifies access token and returns its claims
+func (i *Issuer) Parse(token string) (*Claims, error) {
+	var claims Claims
+
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (any, error) { return i.secret, nil },
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil || claims.UserID() == 0 {
+		return nil, ErrInvalidToken
+	}
+
+	return &claims, nil
+}
+
+// NewRefreshToken returns random opaque refresh token and its hash, only the hash is stored
+func NewRefreshToken() (token string, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("error while generating refresh token: %w", err)
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(b)
+
+	return token, HashToken(token), nil
+}
+
+// HashToken returns hex SHA-256 of refresh token or API key
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
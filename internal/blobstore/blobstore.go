@@ -0,0 +1,21 @@
+// Package blobstore keeps binary objects such as post attachments outside of the database
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned for a missing object key
+var ErrNotFound = errors.New("object is not found")
+
+// Store puts, reads and removes objects by key. Keys are slash separated paths made by the application
+type Store interface {
+	// Put writes size bytes of r under key, existing object is replaced
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens object for reading, the caller closes it
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes object, missing object is not an error
+	Delete(ctx context.Context, key string) error
+}
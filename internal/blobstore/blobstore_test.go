@@ -0,0 +1,81 @@
+package blobstore_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trad3r/hskills/apirest/internal/blobstore"
+	"github.com/trad3r/hskills/apirest/internal/testutils"
+)
+
+func TestStores(t *testing.T) {
+	testCases := []struct {
+		name  string
+		store func(t *testing.T) blobstore.Store
+	}{
+		{
+			name: "Local",
+			store: func(t *testing.T) blobstore.Store {
+				store, err := blobstore.NewLocal(t.TempDir())
+				require.NoError(t, err)
+
+				return store
+			},
+		},
+		{
+			name: "S3",
+			store: func(t *testing.T) blobstore.Store {
+				endpoint, accessKey, secretKey := testutils.PrepareMinio(t)
+
+				store, err := blobstore.NewS3(context.Background(), blobstore.S3Options{
+					Endpoint:  endpoint,
+					AccessKey: accessKey,
+					SecretKey: secretKey,
+					Bucket:    "attachments",
+				})
+				require.NoError(t, err)
+
+				return store
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := tc.store(t)
+
+			content := []byte("attachment content")
+			require.NoError(t, store.Put(ctx, "posts/1/a", bytes.NewReader(content), int64(len(content)), "text/plain"))
+
+			r, err := store.Get(ctx, "posts/1/a")
+			require.NoError(t, err)
+
+			got, err := io.ReadAll(r)
+			require.NoError(t, err)
+			require.NoError(t, r.Close())
+			assert.Equal(t, content, got)
+
+			require.NoError(t, store.Delete(ctx, "posts/1/a"))
+			require.NoError(t, store.Delete(ctx, "posts/1/a"))
+
+			_, err = store.Get(ctx, "posts/1/a")
+			assert.ErrorIs(t, err, blobstore.ErrNotFound)
+		})
+	}
+}
+
+func TestLocalRejectsEscapingKeys(t *testing.T) {
+	store, err := blobstore.NewLocal(t.TempDir())
+	require.NoError(t, err)
+
+	for _, key := range []string{"../secret", "/etc/passwd", "posts/../../x", ""} {
+		_, err := store.Get(context.Background(), key)
+		assert.Error(t, err, key)
+		assert.NotErrorIs(t, err, blobstore.ErrNotFound, key)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/ilyakaznacheev/cleanenv"
 	"log"
 	"sync"
+	"time"
 )
 
 type Config struct {
@@ -11,6 +12,50 @@ type Config struct {
 	DB      struct {
 		Url string `yaml:"url" required:"true"`
 	} `yaml:"db"`
+	Search struct {
+		// Config is a PostgreSQL text search configuration of posts, e.g. russian or english,
+		// changing it rebuilds the post search column on start
+		Config string `yaml:"config" env-default:"russian"`
+	} `yaml:"search"`
+	Markdown struct {
+		// CacheSize is how many rendered posts are kept in memory
+		CacheSize int `yaml:"cache_size" env-default:"1000"`
+	} `yaml:"markdown"`
+	Purge struct {
+		// Retention is how long deleted users and posts are kept in trash
+		Retention time.Duration `yaml:"retention" env-default:"720h"`
+		Interval  time.Duration `yaml:"interval" env-default:"1h"`
+	} `yaml:"purge"`
+	Scheduler struct {
+		// Interval is how often scheduled posts are checked, posts are published at most this late
+		Interval time.Duration `yaml:"interval" env-default:"1m"`
+	} `yaml:"scheduler"`
+	Storage struct {
+		// Backend is either local or s3
+		Backend string `yaml:"backend" env-default:"local"`
+		// Path is a directory of local backend
+		Path string `yaml:"path" env-default:"data/attachments"`
+		S3   struct {
+			Endpoint  string `yaml:"endpoint"`
+			Region    string `yaml:"region"`
+			AccessKey string `yaml:"access_key"`
+			SecretKey string `yaml:"secret_key"`
+			Bucket    string `yaml:"bucket" env-default:"attachments"`
+			UseSSL    bool   `yaml:"use_ssl" env-default:"true"`
+		} `yaml:"s3"`
+	} `yaml:"storage"`
+	Auth struct {
+		// Secret signs access tokens, it must be at least 32 bytes
+		Secret     string        `yaml:"secret" env:"AUTH_SECRET"`
+		AccessTTL  time.Duration `yaml:"access_ttl" env-default:"15m"`
+		RefreshTTL time.Duration `yaml:"refresh_ttl" env-default:"720h"`
+	} `yaml:"auth"`
+	Attachments struct {
+		MaxSize      int64    `yaml:"max_size" env-default:"10485760"`
+		AllowedTypes []string `yaml:"allowed_types" env-default:"image/png,image/jpeg,image/gif,image/webp,application/pdf,text/plain"`
+		// CleanupInterval is how often objects of removed attachments are deleted from storage
+		CleanupInterval time.Duration `yaml:"cleanup_interval" env-default:"5m"`
+	} `yaml:"attachments"`
 }
 
 var (
@@ -1,8 +1,46 @@
 package custom_errors
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrUserNotFound = errors.New("user is not found")
 	ErrPostNotFound = errors.New("post is not found")
+	ErrConflict     = errors.New("resource conflicts with existing data")
+	ErrInvalidBody  = errors.New("request body is malformed")
+
+	ErrPreconditionFailed   = errors.New("resource version does not match")
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	ErrBulkAborted          = errors.New("item is not applied because another item failed")
+	ErrNotAcceptable        = errors.New("none of accepted media types is supported")
+	ErrRevisionNotFound     = errors.New("post revision is not found")
+	ErrTagNotFound          = errors.New("tag is not found")
+	ErrCommentNotFound      = errors.New("comment is not found")
+	ErrTransitionNotAllowed = errors.New("post status transition is not allowed")
+	ErrAttachmentNotFound   = errors.New("attachment is not found")
+	ErrPayloadTooLarge      = errors.New("request payload is too large")
+	ErrUnauthorized         = errors.New("authentication is required")
+	ErrInvalidCredentials   = errors.New("login or password is wrong")
+	ErrInvalidToken         = errors.New("token is invalid or expired")
+	ErrForbidden            = errors.New("operation is not allowed")
+	ErrAPIKeyNotFound       = errors.New("API key is not found")
 )
+
+// ValidationError describes a request field that failed validation
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func NewValidationError(field string, message string) *ValidationError {
+	return &ValidationError{
+		Field:   field,
+		Message: message,
+	}
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
@@ -0,0 +1,157 @@
+// Package dataformat writes exported items as CSV or newline delimited JSON
+package dataformat
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/trad3r/hskills/apirest/internal/models"
+)
+
+const (
+	MIMECSV    = "text/csv"
+	MIMENDJSON = "application/x-ndjson"
+)
+
+// flushEvery is amount of items written between flushes to client
+const flushEvery = 500
+
+// Column is a CSV column of T
+type Column[T any] struct {
+	Name  string
+	Value func(item T) string
+}
+
+// Writer writes items in export format, Flush must be called after the last item
+type Writer[T any] interface {
+	Write(item T) error
+	Flush() error
+}
+
+// New returns writer of format. CSV writes columns, NDJSON writes keys of items, nil keys mean all keys
+func New[T any](format string, w io.Writer, columns []Column[T], keys []string) (Writer[T], error) {
+	switch format {
+	case MIMECSV:
+		return NewCSV(w, columns), nil
+	case MIMENDJSON:
+		return NewNDJSON[T](w, keys), nil
+	}
+
+	return nil, fmt.Errorf("unknown format %s", format)
+}
+
+type csvWriter[T any] struct {
+	w       io.Writer
+	csv     *csv.Writer
+	columns []Column[T]
+	header  bool
+	count   int
+}
+
+func NewCSV[T any](w io.Writer, columns []Column[T]) Writer[T] {
+	return &csvWriter[T]{w: w, csv: csv.NewWriter(w), columns: columns}
+}
+
+func (c *csvWriter[T]) Write(item T) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	record := make([]string, 0, len(c.columns))
+	for _, column := range c.columns {
+		record = append(record, column.Value(item))
+	}
+
+	if err := c.csv.Write(record); err != nil {
+		return err
+	}
+
+	c.count++
+	if c.count%flushEvery == 0 {
+		return c.Flush()
+	}
+
+	return nil
+}
+
+func (c *csvWriter[T]) Flush() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	c.csv.Flush()
+	if err := c.csv.Error(); err != nil {
+		return err
+	}
+
+	flush(c.w)
+
+	return nil
+}
+
+// writeHeader writes column names once, so empty export still has a header
+func (c *csvWriter[T]) writeHeader() error {
+	if c.header {
+		return nil
+	}
+
+	c.header = true
+
+	names := make([]string, 0, len(c.columns))
+	for _, column := range c.columns {
+		names = append(names, column.Name)
+	}
+
+	return c.csv.Write(names)
+}
+
+type ndjsonWriter[T any] struct {
+	w     io.Writer
+	enc   *json.Encoder
+	keys  []string
+	count int
+}
+
+func NewNDJSON[T any](w io.Writer, keys []string) Writer[T] {
+	return &ndjsonWriter[T]{w: w, enc: json.NewEncoder(w), keys: keys}
+}
+
+func (n *ndjsonWriter[T]) Write(item T) error {
+	var v any = item
+	if len(n.keys) > 0 {
+		projected, err := models.Project(item, n.keys)
+		if err != nil {
+			return err
+		}
+
+		v = projected
+	}
+
+	// Encode terminates every value with a newline
+	if err := n.enc.Encode(v); err != nil {
+		return err
+	}
+
+	n.count++
+	if n.count%flushEvery == 0 {
+		return n.Flush()
+	}
+
+	return nil
+}
+
+func (n *ndjsonWriter[T]) Flush() error {
+	flush(n.w)
+
+	return nil
+}
+
+// flush sends written data to HTTP client
+func flush(w io.Writer) {
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,72 @@
+package dataformat_test
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trad3r/hskills/apirest/internal/dataformat"
+	"github.com/trad3r/hskills/apirest/internal/models"
+)
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+
+	columns := []dataformat.Column[models.Post]{
+		{Name: "id", Value: func(p models.Post) string { return strconv.Itoa(p.ID) }},
+		{Name: "subject", Value: func(p models.Post) string { return p.Subject }},
+	}
+
+	testCases := []struct {
+		name     string
+		format   string
+		keys     []string
+		posts    []models.Post
+		expected string
+	}{
+		{
+			name:     "CSV",
+			format:   dataformat.MIMECSV,
+			posts:    []models.Post{{ID: 1, Subject: "first"}, {ID: 2, Subject: "with, comma"}},
+			expected: "id,subject\n1,first\n2,\"with, comma\"\n",
+		},
+		{
+			name:     "Empty CSV has header",
+			format:   dataformat.MIMECSV,
+			expected: "id,subject\n",
+		},
+		{
+			name:     "NDJSON",
+			format:   dataformat.MIMENDJSON,
+			keys:     []string{"id", "subject"},
+			posts:    []models.Post{{ID: 1, Subject: "first"}, {ID: 2, Subject: "second"}},
+			expected: "{\"id\":1,\"subject\":\"first\"}\n{\"id\":2,\"subject\":\"second\"}\n",
+		},
+		{
+			name:     "Empty NDJSON",
+			format:   dataformat.MIMENDJSON,
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			w, err := dataformat.New(tc.format, &buf, columns, tc.keys)
+			require.NoError(t, err)
+
+			for _, post := range tc.posts {
+				require.NoError(t, w.Write(post))
+			}
+
+			require.NoError(t, w.Flush())
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+
+	_, err := dataformat.New("application/xml", &bytes.Buffer{}, columns, nil)
+	assert.Error(t, err)
+}
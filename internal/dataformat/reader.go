@@ -0,0 +1,151 @@
+package dataformat
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// maxLineSize is the longest NDJSON line accepted
+const maxLineSize = 1 << 20
+
+// Field is a CSV column parser of T
+type Field[T any] struct {
+	Name string
+	Set  func(item *T, value string) error
+}
+
+// RowError is an error of one input row, reading may go on after it
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// Reader reads items in import format. Read returns input line of the item,
+// *RowError for a malformed row and io.EOF after the last one
+type Reader[T any] interface {
+	Read() (item T, line int, err error)
+}
+
+// NewReader returns reader of format. CSV header must consist of fields names, NDJSON lines are decoded strictly
+func NewReader[T any](format string, r io.Reader, fields []Field[T]) (Reader[T], error) {
+	switch format {
+	case MIMECSV:
+		return newCSVReader(r, fields)
+	case MIMENDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+		return &ndjsonReader[T]{scanner: scanner}, nil
+	}
+
+	return nil, fmt.Errorf("unknown format %s", format)
+}
+
+type csvReader[T any] struct {
+	csv    *csv.Reader
+	fields []Field[T]
+}
+
+func newCSVReader[T any](r io.Reader, fields []Field[T]) (*csvReader[T], error) {
+	c := csv.NewReader(r)
+	c.ReuseRecord = true
+
+	header, err := c.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("header is missing")
+		}
+
+		return nil, fmt.Errorf("invalid header: %w", err)
+	}
+
+	byName := make(map[string]Field[T], len(fields))
+	for _, f := range fields {
+		byName[f.Name] = f
+	}
+
+	columns := make([]Field[T], 0, len(header))
+	for _, name := range header {
+		f, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+
+		columns = append(columns, f)
+	}
+
+	// rows must have as many values as header has
+	c.FieldsPerRecord = len(columns)
+
+	return &csvReader[T]{csv: c, fields: columns}, nil
+}
+
+func (c *csvReader[T]) Read() (T, int, error) {
+	var item T
+
+	record, err := c.csv.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return item, parseErr.StartLine, &RowError{Line: parseErr.StartLine, Err: parseErr.Err}
+		}
+
+		return item, 0, err
+	}
+
+	line, _ := c.csv.FieldPos(0)
+
+	for i, value := range record {
+		if err := c.fields[i].Set(&item, value); err != nil {
+			return item, line, &RowError{Line: line, Err: fmt.Errorf("%s: %w", c.fields[i].Name, err)}
+		}
+	}
+
+	return item, line, nil
+}
+
+type ndjsonReader[T any] struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (n *ndjsonReader[T]) Read() (T, int, error) {
+	var item T
+
+	for n.scanner.Scan() {
+		n.line++
+
+		data := bytes.TrimSpace(n.scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		d := json.NewDecoder(bytes.NewReader(data))
+		d.DisallowUnknownFields()
+
+		if err := d.Decode(&item); err != nil {
+			return item, n.line, &RowError{Line: n.line, Err: err}
+		}
+
+		return item, n.line, nil
+	}
+
+	if err := n.scanner.Err(); err != nil {
+		return item, n.line, err
+	}
+
+	return item, n.line, io.EOF
+}
@@ -0,0 +1,113 @@
+package dataformat_test
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trad3r/hskills/apirest/internal/dataformat"
+)
+
+type row struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func TestReader(t *testing.T) {
+	t.Parallel()
+
+	fields := []dataformat.Field[row]{
+		{Name: "name", Set: func(r *row, v string) error { r.Name = v; return nil }},
+		{Name: "count", Set: func(r *row, v string) error {
+			var err error
+			r.Count, err = strconv.Atoi(v)
+			return err
+		}},
+	}
+
+	testCases := []struct {
+		name      string
+		format    string
+		input     string
+		rows      []row
+		lines     []int
+		errLines  []int
+		headerErr bool
+	}{
+		{
+			name:   "CSV",
+			format: dataformat.MIMECSV,
+			input:  "count,name\n1,first\n2,\"multi\nline\"\n3,third\n",
+			rows:   []row{{Name: "first", Count: 1}, {Name: "multi\nline", Count: 2}, {Name: "third", Count: 3}},
+			lines:  []int{2, 3, 5},
+		},
+		{
+			name:     "CSV row errors",
+			format:   dataformat.MIMECSV,
+			input:    "name,count\nfirst,x\nsecond\nthird,3\n",
+			rows:     []row{{Name: "third", Count: 3}},
+			lines:    []int{4},
+			errLines: []int{2, 3},
+		},
+		{
+			name:      "CSV unknown column",
+			format:    dataformat.MIMECSV,
+			input:     "name,total\n",
+			headerErr: true,
+		},
+		{
+			name:      "CSV without header",
+			format:    dataformat.MIMECSV,
+			input:     "",
+			headerErr: true,
+		},
+		{
+			name:     "NDJSON",
+			format:   dataformat.MIMENDJSON,
+			input:    "{\"name\":\"first\",\"count\":1}\n\n{\"name\":\"second\",\"extra\":1}\n{\"name\":\"third\"}\n",
+			rows:     []row{{Name: "first", Count: 1}, {Name: "third"}},
+			lines:    []int{1, 4},
+			errLines: []int{3},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := dataformat.NewReader(tc.format, strings.NewReader(tc.input), fields)
+			if tc.headerErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var rows []row
+			var lines, errLines []int
+
+			for {
+				item, line, err := r.Read()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+
+				var rowErr *dataformat.RowError
+				if errors.As(err, &rowErr) {
+					assert.Equal(t, line, rowErr.Line)
+					errLines = append(errLines, line)
+					continue
+				}
+				require.NoError(t, err)
+
+				rows = append(rows, item)
+				lines = append(lines, line)
+			}
+
+			assert.Equal(t, tc.rows, rows)
+			assert.Equal(t, tc.lines, lines)
+			assert.Equal(t, tc.errLines, errLines)
+		})
+	}
+}
@@ -2,22 +2,42 @@ package domain
 
 import (
 	"context"
+	"time"
+
 	"github.com/trad3r/hskills/apirest/internal/models"
 	"github.com/trad3r/hskills/apirest/internal/repository/filters"
 )
 
 type UserRepository interface {
-	GetList(ctx context.Context, filter filters.UserFilter) ([]models.User, error)
+	GetList(ctx context.Context, filter filters.UserFilter) (*models.Page[models.User], error)
+	Export(ctx context.Context, filter filters.UserFilter, fn func(user models.User) error) error
 	Add(ctx context.Context, user *models.User) error
-	Update(ctx context.Context, id int, userReq filters.UserUpdateRequest) error
+	Update(ctx context.Context, id int, userReq filters.UserUpdateRequest) (int, error)
 	Delete(ctx context.Context, id int) error
+	Restore(ctx context.Context, id int) (int, error)
+	Purge(ctx context.Context, before time.Time) (int64, error)
 	FindById(ctx context.Context, id int) (*models.User, error)
+	BulkAdd(ctx context.Context, users []*models.User, atomic bool) ([]error, error)
+	BulkUpdate(ctx context.Context, items []filters.UserBulkUpdateItem, atomic bool) ([]error, error)
+	BulkDelete(ctx context.Context, ids []int, atomic bool) ([]error, error)
+	Import(ctx context.Context, items []filters.UserImportItem, atomic bool) ([]models.ImportResult, error)
+	FindIdByExternalId(ctx context.Context, externalID string) (int, error)
 }
 
 type PostRepository interface {
-	GetList(ctx context.Context, filter filters.PostFilter) ([]models.Post, error)
+	GetList(ctx context.Context, filter filters.PostFilter) (*models.Page[models.Post], error)
+	Export(ctx context.Context, filter filters.PostFilter, fn func(post models.Post) error) error
 	Add(ctx context.Context, post *models.Post) error
-	Update(ctx context.Context, id int, postReq filters.PostUpdateRequest) error
+	Update(ctx context.Context, id int, postReq filters.PostUpdateRequest) (int, error)
 	Delete(ctx context.Context, id int) error
+	Restore(ctx context.Context, id int) (int, error)
+	Purge(ctx context.Context, before time.Time) (int64, error)
 	FindById(ctx context.Context, id int) (*models.Post, error)
+	Revisions(ctx context.Context, postID int, filter filters.RevisionFilter) (*models.Page[models.PostRevision], error)
+	Revision(ctx context.Context, postID int, revision int) (*models.PostRevision, error)
+	RestoreRevision(ctx context.Context, postID int, revision int, ifVersions []int) (int, error)
+	BulkAdd(ctx context.Context, posts []*models.Post, atomic bool) ([]error, error)
+	BulkUpdate(ctx context.Context, items []filters.PostBulkUpdateItem, atomic bool) ([]error, error)
+	BulkDelete(ctx context.Context, ids []int, atomic bool) ([]error, error)
+	Import(ctx context.Context, items []filters.PostImportItem, atomic bool) ([]models.ImportResult, error)
 }
@@ -0,0 +1,78 @@
+package etag
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Make returns strong entity tag of resource version
+func Make(id int, version int) string {
+	return fmt.Sprintf(`"%d-%d"`, id, version)
+}
+
+// ParseIfMatch returns resource versions listed in If-Match header.
+// any is true when header is "*". Weak and foreign tags never match, so they are skipped
+func ParseIfMatch(header string, id int) (versions []int, any bool) {
+	for _, tag := range splitTags(header) {
+		if tag == "*" {
+			return nil, true
+		}
+
+		if strings.HasPrefix(tag, "W/") {
+			continue
+		}
+
+		tagID, version, ok := parse(tag)
+		if ok && tagID == id {
+			versions = append(versions, version)
+		}
+	}
+
+	return versions, false
+}
+
+// NoneMatch reports whether If-None-Match header contains tag using weak comparison
+func NoneMatch(header string, tag string) bool {
+	for _, t := range splitTags(header) {
+		if t == "*" || strings.TrimPrefix(t, "W/") == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func splitTags(header string) []string {
+	var tags []string
+	for _, t := range strings.Split(header, ",") {
+		if t = strings.TrimSpace(t); len(t) > 0 {
+			tags = append(tags, t)
+		}
+	}
+
+	return tags
+}
+
+func parse(tag string) (id int, version int, ok bool) {
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, 0, false
+	}
+
+	idPart, versionPart, found := strings.Cut(tag[1:len(tag)-1], "-")
+	if !found {
+		return 0, 0, false
+	}
+
+	id, err := strconv.Atoi(idPart)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	version, err = strconv.Atoi(versionPart)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return id, version, true
+}
@@ -0,0 +1,50 @@
+package etag_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/trad3r/hskills/apirest/internal/etag"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		header   string
+		versions []int
+		any      bool
+	}{
+		{name: "empty", header: ""},
+		{name: "any", header: "*", any: true},
+		{name: "single", header: `"1-3"`, versions: []int{3}},
+		{name: "list", header: `"1-3", "1-4"`, versions: []int{3, 4}},
+		{name: "weak is skipped", header: `W/"1-3"`},
+		{name: "other resource is skipped", header: `"2-3", "1-5"`, versions: []int{5}},
+		{name: "malformed", header: `"abc", 1-3`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			versions, any := etag.ParseIfMatch(tc.header, 1)
+			assert.Equal(t, tc.versions, versions)
+			assert.Equal(t, tc.any, any)
+		})
+	}
+}
+
+func TestNoneMatch(t *testing.T) {
+	t.Parallel()
+
+	tag := etag.Make(1, 2)
+	assert.Equal(t, `"1-2"`, tag)
+
+	assert.True(t, etag.NoneMatch(`"1-2"`, tag))
+	assert.True(t, etag.NoneMatch(`"1-1", W/"1-2"`, tag))
+	assert.True(t, etag.NoneMatch("*", tag))
+	assert.False(t, etag.NoneMatch(`"1-1"`, tag))
+	assert.False(t, etag.NoneMatch("", tag))
+}
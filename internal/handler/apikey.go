@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) getAPIKeys(c *gin.Context) {
+	keys, err := h.apiKeyService.APIKeyList(c.Request)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+func (h *Handler) addAPIKey(c *gin.Context) {
+	key, err := h.apiKeyService.APIKeyAdd(c.Request)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, key)
+}
+
+func (h *Handler) revokeAPIKey(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	if err := h.apiKeyService.APIKeyRevoke(id, c.Request); err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) rotateAPIKey(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	key, err := h.apiKeyService.APIKeyRotate(id, c.Request)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, key)
+}
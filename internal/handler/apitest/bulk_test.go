@@ -0,0 +1,41 @@
+package apitest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/trad3r/hskills/apirest/internal/auth"
+)
+
+func TestUserManagementAccess(t *testing.T) {
+	t.Parallel()
+
+	srv, key := newTestServer(t)
+	writer := newAPIKey(t, srv, key, auth.ScopeUsersRead, auth.ScopeUsersWrite)
+
+	users := []byte(`[{"name":"Bulk User","phonenumber":"+70000000002"}]`)
+
+	testCases := []struct {
+		name   string
+		key    string
+		method string
+		path   string
+		body   []byte
+		status int
+	}{
+		{name: "Anonymous adds users", method: http.MethodPost, path: "/users/bulk", body: users, status: http.StatusUnauthorized},
+		{name: "Writer adds users", key: writer, method: http.MethodPost, path: "/users/bulk", body: users, status: http.StatusForbidden},
+		{name: "Writer deletes users", key: writer, method: http.MethodDelete, path: "/users/bulk", body: []byte(`[2]`), status: http.StatusForbidden},
+		{name: "Admin adds users", key: key, method: http.MethodPost, path: "/users/bulk", body: users, status: http.StatusCreated},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := doRequest(t, srv, tc.key, tc.method, tc.path, tc.body)
+			defer res.Body.Close()
+
+			assert.Equal(t, tc.status, res.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package apitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trad3r/hskills/apirest/internal/models"
+)
+
+func TestHiddenPostCommentsAreFrozen(t *testing.T) {
+	t.Parallel()
+
+	srv, key := newTestServer(t)
+
+	res := doRequest(t, srv, "", http.MethodPost, "/user", []byte(`{"name":"Commenter","phonenumber":"+70000000002","login":"commenter","password":"secret-password"}`))
+	res.Body.Close()
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	res = doRequest(t, srv, "", http.MethodPost, "/auth/login", []byte(`{"login":"commenter","password":"secret-password"}`))
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var tokens models.TokenPair
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&tokens))
+	user := tokens.AccessToken
+
+	// the user comments on two posts, one is unpublished and the other deleted afterwards
+	comments := make(map[int]int)
+	for _, postID := range []int{1, 2} {
+		res := doRequest(t, srv, user, http.MethodPost, fmt.Sprintf("/post/%d/comments", postID), []byte(`{"body":"comment"}`))
+		defer res.Body.Close()
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+
+		var comment models.Comment
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&comment))
+		comments[postID] = comment.ID
+	}
+
+	res = doRequest(t, srv, key, http.MethodPost, "/post/1/unpublish", nil)
+	res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	res = doRequest(t, srv, key, http.MethodDelete, "/post/2", nil)
+	res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	testCases := []struct {
+		name   string
+		key    string
+		method string
+		postID int
+		body   []byte
+		status int
+	}{
+		{name: "Author edits comment of draft", key: user, method: http.MethodPatch, postID: 1, body: []byte(`{"body":"edited"}`), status: http.StatusNotFound},
+		{name: "Author deletes comment of deleted post", key: user, method: http.MethodDelete, postID: 2, status: http.StatusNotFound},
+		{name: "Moderator edits comment of draft", key: key, method: http.MethodPatch, postID: 1, body: []byte(`{"body":"moderated"}`), status: http.StatusOK},
+		{name: "Moderator deletes comment of deleted post", key: key, method: http.MethodDelete, postID: 2, status: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := doRequest(t, srv, tc.key, tc.method, fmt.Sprintf("/comment/%d", comments[tc.postID]), tc.body)
+			defer res.Body.Close()
+
+			assert.Equal(t, tc.status, res.StatusCode)
+		})
+	}
+}
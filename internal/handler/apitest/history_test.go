@@ -0,0 +1,38 @@
+package apitest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/trad3r/hskills/apirest/internal/auth"
+)
+
+func TestHistoryAccess(t *testing.T) {
+	t.Parallel()
+
+	srv, key := newTestServer(t)
+	reader := newAPIKey(t, srv, key, auth.ScopeUsersRead, auth.ScopePostsRead)
+
+	testCases := []struct {
+		name   string
+		key    string
+		path   string
+		status int
+	}{
+		{name: "Anonymous reads published post history", path: "/post/1/history", status: http.StatusOK},
+		{name: "Anonymous reads missing post history", path: "/post/100000/history", status: http.StatusNotFound},
+		{name: "Anonymous reads user history", path: "/user/1/history", status: http.StatusUnauthorized},
+		{name: "Reader reads user history", key: reader, path: "/user/1/history", status: http.StatusForbidden},
+		{name: "Admin reads user history", key: key, path: "/user/1/history", status: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := doRequest(t, srv, tc.key, http.MethodGet, tc.path, nil)
+			defer res.Body.Close()
+
+			assert.Equal(t, tc.status, res.StatusCode)
+		})
+	}
+}
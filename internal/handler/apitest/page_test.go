@@ -0,0 +1,60 @@
+package apitest
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListShape(t *testing.T) {
+	t.Parallel()
+
+	srv, key := newTestServer(t)
+
+	nextLink := regexp.MustCompile(`<([^>]+)>; rel="next"`)
+
+	testCases := []struct {
+		name     string
+		path     string
+		status   int
+		envelope bool
+	}{
+		{name: "Posts", path: "/posts?limit=2", status: http.StatusOK},
+		{name: "Posts by offset", path: "/posts?limit=2&offset=2", status: http.StatusOK},
+		{name: "Posts by cursor paging", path: "/posts?limit=2&paging=cursor", status: http.StatusOK, envelope: true},
+		{name: "Users", path: "/users?limit=2", status: http.StatusOK},
+		{name: "Users by cursor paging", path: "/users?limit=2&paging=cursor", status: http.StatusOK, envelope: true},
+		{name: "User posts by cursor paging", path: "/user/1/posts?limit=1&paging=cursor", status: http.StatusOK, envelope: true},
+		{name: "Unknown paging", path: "/posts?paging=offset", status: http.StatusUnprocessableEntity},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := doRequest(t, srv, key, http.MethodGet, tc.path, nil)
+			defer res.Body.Close()
+			require.Equal(t, tc.status, res.StatusCode)
+			if tc.status != http.StatusOK {
+				return
+			}
+
+			var body json.RawMessage
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+			assert.Equal(t, tc.envelope, body[0] == '{')
+
+			// the next page keeps the shape of the first one
+			link := nextLink.FindStringSubmatch(res.Header.Get("Link"))
+			require.Len(t, link, 2)
+
+			next := doRequest(t, srv, key, http.MethodGet, link[1], nil)
+			defer next.Body.Close()
+			require.Equal(t, http.StatusOK, next.StatusCode)
+
+			require.NoError(t, json.NewDecoder(next.Body).Decode(&body))
+			assert.Equal(t, tc.envelope, body[0] == '{')
+		})
+	}
+}
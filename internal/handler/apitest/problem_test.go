@@ -0,0 +1,146 @@
+package apitest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trad3r/hskills/apirest/internal/handler"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+func TestProblem(t *testing.T) {
+	t.Parallel()
+
+	// requests below are answered before any service is called
+	srv := httptest.NewServer(handler.NewHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).Handlers())
+	t.Cleanup(srv.Close)
+
+	testCases := []struct {
+		name          string
+		method        string
+		path          string
+		authorization string
+		status        int
+		code          string
+		authenticate  string
+	}{
+		{name: "Unknown route", method: http.MethodGet, path: "/unknown", status: http.StatusNotFound, code: "route_not_found"},
+		{name: "Unknown method", method: http.MethodDelete, path: "/users", status: http.StatusMethodNotAllowed, code: "method_not_allowed"},
+		{name: "Anonymous write asks for bearer token", method: http.MethodPost, path: "/post", status: http.StatusUnauthorized, code: "unauthorized", authenticate: `Bearer realm="apirest"`},
+		{name: "Malformed authorization", method: http.MethodGet, path: "/posts", authorization: "Basic dXNlcjpwYXNz", status: http.StatusUnauthorized, code: "invalid_token", authenticate: `Bearer realm="apirest"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(tc.method, srv.URL+tc.path, nil)
+			require.NoError(t, err)
+
+			req.Header.Set(requestIDHeader, "req-1")
+			if len(tc.authorization) > 0 {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+
+			res, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			assert.Equal(t, tc.status, res.StatusCode)
+			assert.Equal(t, "application/problem+json", res.Header.Get("Content-Type"))
+			assert.Equal(t, tc.authenticate, res.Header.Get("WWW-Authenticate"))
+
+			var p handler.Problem
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&p))
+			assert.Equal(t, tc.status, p.Status)
+			assert.Equal(t, tc.code, p.Code)
+			assert.Equal(t, "urn:apirest:problem:"+tc.code, p.Type)
+			assert.NotEmpty(t, p.Title)
+			assert.Equal(t, tc.path, p.Instance)
+			assert.Equal(t, "req-1", p.RequestID)
+		})
+	}
+}
+
+func TestServiceProblem(t *testing.T) {
+	t.Parallel()
+
+	srv, key := newTestServer(t)
+
+	testCases := []struct {
+		name   string
+		method string
+		path   string
+		body   []byte
+		status int
+		code   string
+		field  string
+	}{
+		{name: "Missing post", method: http.MethodGet, path: "/post/100000", status: http.StatusNotFound, code: "post_not_found"},
+		{name: "Missing user", method: http.MethodGet, path: "/user/100000", status: http.StatusNotFound, code: "user_not_found"},
+		{name: "Nothing to update", method: http.MethodPatch, path: "/post/1", body: []byte(`{}`), status: http.StatusUnprocessableEntity, code: "validation_failed", field: "body"},
+		{name: "Malformed body", method: http.MethodPatch, path: "/post/1", body: []byte(`{"subject":`), status: http.StatusBadRequest, code: "malformed_body"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := doRequest(t, srv, key, tc.method, tc.path, tc.body)
+			defer res.Body.Close()
+
+			assert.Equal(t, tc.status, res.StatusCode)
+
+			var p handler.Problem
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&p))
+			assert.Equal(t, tc.code, p.Code)
+			assert.Equal(t, tc.field, p.Field)
+			assert.NotEmpty(t, p.RequestID)
+		})
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(handler.NewHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).Handlers())
+	t.Cleanup(srv.Close)
+
+	testCases := []struct {
+		name   string
+		header string
+		kept   bool
+	}{
+		{name: "Client ID is kept", header: "3f2a-request", kept: true},
+		{name: "Missing ID is generated"},
+		{name: "Too long ID is replaced", header: strings.Repeat("a", 65)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, srv.URL+"/unknown", nil)
+			require.NoError(t, err)
+
+			if len(tc.header) > 0 {
+				req.Header.Set(requestIDHeader, tc.header)
+			}
+
+			res, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			id := res.Header.Get(requestIDHeader)
+			if tc.kept {
+				assert.Equal(t, tc.header, id)
+			} else {
+				assert.Regexp(t, `^[0-9a-f]{32}$`, id)
+			}
+
+			var p handler.Problem
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&p))
+			assert.Equal(t, id, p.RequestID, "problem refers to the same request")
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package apitest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCallerActsAsUser(t *testing.T) {
+	t.Parallel()
+
+	srv, key := newTestServer(t)
+
+	testCases := []struct {
+		name   string
+		key    string
+		method string
+		path   string
+		body   []byte
+		status int
+	}{
+		{name: "Key reacts", key: key, method: http.MethodPut, path: "/post/1/reactions/like", status: http.StatusForbidden},
+		{name: "Key removes reaction", key: key, method: http.MethodDelete, path: "/post/1/reactions/like", status: http.StatusForbidden},
+		{name: "Anonymous reacts", method: http.MethodPut, path: "/post/1/reactions/like", status: http.StatusUnauthorized},
+		{name: "Key comments", key: key, method: http.MethodPost, path: "/post/1/comments", body: []byte(`{"body":"comment","author":1}`), status: http.StatusForbidden},
+		{name: "Anonymous comments", method: http.MethodPost, path: "/post/1/comments", body: []byte(`{"body":"comment"}`), status: http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := doRequest(t, srv, tc.key, tc.method, tc.path, tc.body)
+			defer res.Body.Close()
+
+			assert.Equal(t, tc.status, res.StatusCode)
+		})
+	}
+}
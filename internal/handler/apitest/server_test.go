@@ -0,0 +1,83 @@
+package apitest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TRAD3R/tlog"
+	"github.com/stretchr/testify/require"
+	"github.com/trad3r/hskills/apirest/internal/auth"
+	"github.com/trad3r/hskills/apirest/internal/blobstore"
+	"github.com/trad3r/hskills/apirest/internal/handler"
+	"github.com/trad3r/hskills/apirest/internal/markdown"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/repository/filters"
+	"github.com/trad3r/hskills/apirest/internal/service"
+	"github.com/trad3r/hskills/apirest/internal/testutils"
+)
+
+// newTestServer serves handler wired to services on a fresh database and returns admin API key
+func newTestServer(t *testing.T) (*httptest.Server, string) {
+	db := testutils.PrepareDB(t)
+	logger := tlog.GetLogger(false)
+
+	store, err := blobstore.NewLocal(t.TempDir())
+	require.NoError(t, err)
+
+	u := service.NewUserService(logger, db)
+	p := service.NewPostService(logger, db, markdown.New(16))
+	au, err := service.NewAuthService(logger, db, auth.NewIssuer("test-secret-test-secret-test-secret", time.Minute), time.Hour)
+	require.NoError(t, err)
+	ak := service.NewAPIKeyService(logger, db)
+
+	h := handler.NewHandler(u, p, service.NewUserPostService(u, p), service.NewAuditService(logger, db), service.NewTagService(logger, db),
+		service.NewCommentService(logger, db), service.NewReactionService(logger, db),
+		service.NewAttachmentService(logger, db, store, service.AttachmentLimits{MaxSize: 1 << 20}), au, ak)
+
+	key, err := ak.APIKeyCreate(context.Background(), filters.APIKeyAddRequest{Name: "test", Scopes: []string{auth.ScopeAdmin}})
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(h.Handlers())
+	t.Cleanup(srv.Close)
+
+	return srv, key.Key
+}
+
+// doRequest sends request with API key, empty key makes anonymous request
+func doRequest(t *testing.T, srv *httptest.Server, key string, method string, path string, body []byte) *http.Response {
+	req, err := http.NewRequest(method, srv.URL+path, bytes.NewReader(body))
+	require.NoError(t, err)
+
+	if len(key) > 0 {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	return res
+}
+
+// newAPIKey creates API key with scopes through admin endpoint and returns it
+func newAPIKey(t *testing.T, srv *httptest.Server, adminKey string, scopes ...string) string {
+	body, err := json.Marshal(map[string]any{"name": "test " + strings.Join(scopes, " "), "scopes": scopes})
+	require.NoError(t, err)
+
+	res := doRequest(t, srv, adminKey, http.MethodPost, "/admin/api-keys", body)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	var key models.APIKey
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&key))
+
+	return key.Key
+}
@@ -0,0 +1,44 @@
+package apitest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/trad3r/hskills/apirest/internal/auth"
+)
+
+func TestTrashAccess(t *testing.T) {
+	t.Parallel()
+
+	srv, key := newTestServer(t)
+	reader := newAPIKey(t, srv, key, auth.ScopeUsersRead, auth.ScopePostsRead)
+	moderator := newAPIKey(t, srv, key, auth.ScopeUsersRead, auth.ScopePostsRead, auth.ScopePostsModerate)
+
+	testCases := []struct {
+		name   string
+		key    string
+		path   string
+		status int
+	}{
+		{name: "Anonymous lists live posts", path: "/posts", status: http.StatusOK},
+		{name: "Anonymous lists deleted posts", path: "/posts?deleted=include", status: http.StatusUnauthorized},
+		{name: "Anonymous exports deleted posts", path: "/posts/export?deleted=only", status: http.StatusUnauthorized},
+		{name: "Anonymous lists deleted posts of user", path: "/user/1/posts?deleted=only", status: http.StatusUnauthorized},
+		{name: "Anonymous lists deleted users", path: "/users?deleted=only", status: http.StatusUnauthorized},
+		{name: "Reader lists deleted posts", key: reader, path: "/posts?deleted=only", status: http.StatusForbidden},
+		{name: "Reader exports deleted users", key: reader, path: "/users/export?deleted=include", status: http.StatusForbidden},
+		{name: "Moderator lists deleted posts", key: moderator, path: "/posts?deleted=only", status: http.StatusOK},
+		{name: "Moderator lists deleted users", key: moderator, path: "/users?deleted=only", status: http.StatusForbidden},
+		{name: "Admin lists deleted users", key: key, path: "/users?deleted=only", status: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := doRequest(t, srv, tc.key, http.MethodGet, tc.path, nil)
+			defer res.Body.Close()
+
+			assert.Equal(t, tc.status, res.StatusCode)
+		})
+	}
+}
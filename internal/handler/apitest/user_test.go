@@ -0,0 +1,49 @@
+package apitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trad3r/hskills/apirest/internal/models"
+)
+
+func TestLoginIsHidden(t *testing.T) {
+	t.Parallel()
+
+	srv, key := newTestServer(t)
+
+	res := doRequest(t, srv, "", http.MethodPost, "/user", []byte(`{"name":"Hidden Login","phonenumber":"+70000000001","login":"hidden.login","password":"secret-password"}`))
+	defer res.Body.Close()
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	var user models.User
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&user))
+
+	testCases := []struct {
+		name string
+		key  string
+		path string
+	}{
+		{name: "Anonymous gets user", path: fmt.Sprintf("/user/%d", user.ID)},
+		{name: "Anonymous lists users", path: "/users"},
+		{name: "Anonymous exports users", path: "/users/export"},
+		{name: "Admin gets user", key: key, path: fmt.Sprintf("/user/%d", user.ID)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := doRequest(t, srv, tc.key, http.MethodGet, tc.path, nil)
+			defer res.Body.Close()
+			require.Equal(t, http.StatusOK, res.StatusCode)
+
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			assert.NotContains(t, string(body), "hidden.login")
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package apitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trad3r/hskills/apirest/internal/models"
+)
+
+func TestUserPosts(t *testing.T) {
+	t.Parallel()
+
+	srv, key := newTestServer(t)
+
+	testCases := []struct {
+		name   string
+		userID int
+		status int
+		posts  []int
+	}{
+		{name: "Author posts", userID: 1, status: http.StatusOK, posts: []int{1, 2}},
+		{name: "Missing user", userID: 1000, status: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := doRequest(t, srv, key, http.MethodGet, fmt.Sprintf("/user/%d/posts", tc.userID), nil)
+			defer res.Body.Close()
+
+			require.Equal(t, tc.status, res.StatusCode)
+			if tc.status != http.StatusOK {
+				return
+			}
+
+			var posts []models.Post
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&posts))
+
+			ids := make([]int, 0, len(posts))
+			for _, post := range posts {
+				ids = append(ids, post.ID)
+			}
+			assert.ElementsMatch(t, tc.posts, ids)
+		})
+	}
+}
+
+func TestAddUserPost(t *testing.T) {
+	t.Parallel()
+
+	srv, key := newTestServer(t)
+
+	testCases := []struct {
+		name   string
+		userID int
+		body   string
+		status int
+	}{
+		{name: "Author from path", userID: 2, body: `{"subject":"from path"}`, status: http.StatusCreated},
+		{name: "Same author in body", userID: 2, body: `{"subject":"same author","author":2}`, status: http.StatusCreated},
+		{name: "Other author in body", userID: 2, body: `{"subject":"other author","author":1}`, status: http.StatusUnprocessableEntity},
+		{name: "Missing user", userID: 1000, body: `{"subject":"missing"}`, status: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := doRequest(t, srv, key, http.MethodPost, fmt.Sprintf("/user/%d/posts", tc.userID), []byte(tc.body))
+			defer res.Body.Close()
+
+			assert.Equal(t, tc.status, res.StatusCode)
+		})
+	}
+
+	res := doRequest(t, srv, key, http.MethodGet, "/user/2/posts", nil)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var posts []models.Post
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&posts))
+
+	subjects := make([]string, 0, len(posts))
+	for _, post := range posts {
+		subjects = append(subjects, post.Subject)
+	}
+	assert.Subset(t, subjects, []string{"from path", "same author"})
+	assert.NotContains(t, subjects, "other author")
+}
+
+func TestUnpublishedPosts(t *testing.T) {
+	t.Parallel()
+
+	srv, key := newTestServer(t)
+
+	res := doRequest(t, srv, key, http.MethodPost, "/user/1/posts", []byte(`{"subject":"draft","status":"draft"}`))
+	res.Body.Close()
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	res = doRequest(t, srv, key, http.MethodGet, "/user/1/posts?status=draft", nil)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var posts []models.Post
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&posts))
+	require.Len(t, posts, 1)
+
+	draftID := posts[0].ID
+
+	testCases := []struct {
+		name   string
+		key    string
+		path   string
+		status int
+	}{
+		{name: "Moderator reads draft", key: key, path: fmt.Sprintf("/post/%d", draftID), status: http.StatusOK},
+		{name: "Anonymous reads draft", path: fmt.Sprintf("/post/%d", draftID), status: http.StatusNotFound},
+		{name: "Anonymous reads draft revisions", path: fmt.Sprintf("/post/%d/revisions", draftID), status: http.StatusNotFound},
+		{name: "Anonymous reads draft comments", path: fmt.Sprintf("/post/%d/comments", draftID), status: http.StatusNotFound},
+		{name: "Moderator reads draft history", key: key, path: fmt.Sprintf("/post/%d/history", draftID), status: http.StatusOK},
+		{name: "Anonymous reads draft history", path: fmt.Sprintf("/post/%d/history", draftID), status: http.StatusNotFound},
+		{name: "Anonymous lists drafts", path: "/user/1/posts?status=draft", status: http.StatusUnauthorized},
+		{name: "Anonymous lists published", path: "/user/1/posts", status: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := doRequest(t, srv, tc.key, http.MethodGet, tc.path, nil)
+			defer res.Body.Close()
+
+			assert.Equal(t, tc.status, res.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+)
+
+func (h *Handler) getPostAttachments(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	attachments, err := h.attachmentService.AttachmentList(id, c.Request)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, attachments)
+}
+
+func (h *Handler) addPostAttachment(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	attachment, err := h.attachmentService.AttachmentAdd(id, c.Request)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+func (h *Handler) getPostAttachment(c *gin.Context) {
+	id, attachmentID, err := pathAttachment(c)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	attachment, content, err := h.attachmentService.AttachmentGet(id, attachmentID, c.Request)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+	defer content.Close()
+
+	headers := map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}),
+		"X-Content-Type-Options": "nosniff",
+	}
+
+	// checksum is stored hex encoded, Digest header wants base64
+	if checksum, err := hex.DecodeString(attachment.Checksum); err == nil {
+		headers["Digest"] = "sha-256=" + base64.StdEncoding.EncodeToString(checksum)
+	}
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, headers)
+}
+
+func (h *Handler) deletePostAttachment(c *gin.Context) {
+	id, attachmentID, err := pathAttachment(c)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	if err := h.attachmentService.AttachmentDelete(id, attachmentID, c.Request); err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// pathAttachment parses post ID and attachment ID path params
+func pathAttachment(c *gin.Context) (int, int, error) {
+	id, err := pathID(c)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	attachmentID, err := strconv.Atoi(c.Param("attachment"))
+	if err != nil || attachmentID < 1 {
+		return 0, 0, custom_errors.NewValidationError("attachment", "must be a positive integer")
+	}
+
+	return id, attachmentID, nil
+}
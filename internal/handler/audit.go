@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/trad3r/hskills/apirest/internal/repository/filters"
+)
+
+func (h *Handler) getUserHistory(c *gin.Context) {
+	h.getHistory(c, filters.AuditEntityUser)
+}
+
+func (h *Handler) getPostHistory(c *gin.Context) {
+	h.getHistory(c, filters.AuditEntityPost)
+}
+
+func (h *Handler) getHistory(c *gin.Context, entity string) {
+	id, err := pathID(c)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	entries, err := h.auditService.AuditHistory(entity, id, c.Request)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	writePage(c, entries)
+}
+
+func (h *Handler) getAuditLog(c *gin.Context) {
+	entries, err := h.auditService.AuditList(c.Request)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	writePage(c, entries)
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trad3r/hskills/apirest/internal/auth"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/reqctx"
+)
+
+func (h *Handler) login(c *gin.Context) {
+	tokens, err := h.authService.Login(c.Request)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (h *Handler) refresh(c *gin.Context) {
+	tokens, err := h.authService.Refresh(c.Request)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (h *Handler) logout(c *gin.Context) {
+	if err := h.authService.Logout(c.Request); err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) changePassword(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	if err := h.authService.ChangePassword(id, c.Request); err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// authenticate stores caller of bearer token in request context, either user of access token or API key.
+// The caller becomes request actor, admins are granted admin scope. Requests without token stay anonymous, invalid token is rejected
+func (h *Handler) authenticate(c *gin.Context) {
+	header := c.GetHeader("Authorization")
+	if len(header) == 0 {
+		c.Next()
+		return
+	}
+
+	scheme, token, found := strings.Cut(header, " ")
+	token = strings.TrimSpace(token)
+	if !found || !strings.EqualFold(scheme, "Bearer") || len(token) == 0 {
+		writeProblem(c, custom_errors.ErrInvalidToken)
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	if auth.IsAPIKey(token) {
+		key, err := h.apiKeyService.Authenticate(ctx, token)
+		if err != nil {
+			writeProblem(c, err)
+			return
+		}
+
+		ctx = reqctx.WithScopes(ctx, key.Scopes)
+		ctx = reqctx.WithActor(ctx, "api_key:"+strconv.Itoa(key.ID))
+	} else {
+		user, err := h.authService.Authenticate(ctx, token)
+		if err != nil {
+			writeProblem(c, err)
+			return
+		}
+
+		scopes := auth.UserScopes
+		if user.Role == models.UserRoleAdmin {
+			scopes = []string{auth.ScopeAdmin}
+		}
+
+		ctx = reqctx.WithUser(ctx, user)
+		ctx = reqctx.WithScopes(ctx, scopes)
+		ctx = reqctx.WithActor(ctx, "user:"+strconv.Itoa(user.ID))
+	}
+
+	c.Request = c.Request.WithContext(ctx)
+
+	c.Next()
+}
+
+// requireScope rejects callers without scope, anonymous callers have auth.AnonymousScopes
+func requireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		granted := reqctx.Scopes(c.Request.Context())
+		if granted == nil {
+			granted = auth.AnonymousScopes
+		}
+
+		if auth.Allows(granted, scope) {
+			c.Next()
+			return
+		}
+
+		if reqctx.Scopes(c.Request.Context()) == nil {
+			writeProblem(c, custom_errors.ErrUnauthorized)
+			return
+		}
+
+		writeProblem(c, fmt.Errorf("%w: %s scope is required", custom_errors.ErrForbidden, scope))
+	}
+}
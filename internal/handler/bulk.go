@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/models"
+)
+
+// bulkItem is a result of one bulk request item, index matches request array
+type bulkItem struct {
+	Index  int      `json:"index"`
+	Status int      `json:"status"`
+	ID     int      `json:"id,omitempty"`
+	Error  *Problem `json:"error,omitempty"`
+}
+
+type bulkResponse struct {
+	Items []bulkItem `json:"items"`
+}
+
+func (h *Handler) addUsers(c *gin.Context) {
+	results, err := h.userService.UserBulkAdd(c.Request)
+	writeBulk(c, http.StatusCreated, results, err)
+}
+
+func (h *Handler) updateUsers(c *gin.Context) {
+	results, err := h.userService.UserBulkUpdate(c.Request)
+	writeBulk(c, http.StatusOK, results, err)
+}
+
+func (h *Handler) deleteUsers(c *gin.Context) {
+	results, err := h.userService.UserBulkDelete(c.Request)
+	writeBulk(c, http.StatusOK, results, err)
+}
+
+func (h *Handler) addPosts(c *gin.Context) {
+	results, err := h.userPostService.AddPosts(c.Request)
+	writeBulk(c, http.StatusCreated, results, err)
+}
+
+func (h *Handler) updatePosts(c *gin.Context) {
+	results, err := h.postService.PostBulkUpdate(c.Request)
+	writeBulk(c, http.StatusOK, results, err)
+}
+
+func (h *Handler) deletePosts(c *gin.Context) {
+	results, err := h.postService.PostBulkDelete(c.Request)
+	writeBulk(c, http.StatusOK, results, err)
+}
+
+// writeBulk writes per item results. Response status is success when every item succeeded,
+// status of the failure cause when every item failed and 207 otherwise
+func writeBulk(c *gin.Context, success int, results []models.BulkResult, err error) {
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	resp := bulkResponse{Items: make([]bulkItem, len(results))}
+
+	failed := 0
+	failure := 0
+	for i, res := range results {
+		item := bulkItem{Index: i, Status: success, ID: res.ID}
+
+		if res.Err != nil {
+			p := problemFromError(res.Err)
+			if p.Status == http.StatusInternalServerError {
+				log.Printf("internal error on %s %s item %d: %v", c.Request.Method, c.Request.URL.Path, i, res.Err)
+			}
+
+			p.Type = "urn:apirest:problem:" + p.Code
+			item.Status = p.Status
+			item.Error = &p
+			failed++
+
+			if failure == 0 && !errors.Is(res.Err, custom_errors.ErrBulkAborted) {
+				failure = p.Status
+			}
+		}
+
+		resp.Items[i] = item
+	}
+
+	status := success
+	switch {
+	case failed == len(results):
+		status = failure
+		if status == 0 {
+			status = http.StatusFailedDependency
+		}
+	case failed > 0:
+		status = http.StatusMultiStatus
+	}
+
+	c.JSON(status, resp)
+}
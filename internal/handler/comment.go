@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trad3r/hskills/apirest/internal/etag"
+)
+
+func (h *Handler) getPostComments(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	comments, err := h.commentService.CommentList(id, c.Request)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	writePage(c, comments)
+}
+
+func (h *Handler) addPostComment(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	comment, err := h.commentService.CommentAdd(id, c.Request)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	c.Header("ETag", etag.Make(comment.ID, comment.Version))
+	c.JSON(http.StatusCreated, comment)
+}
+
+func (h *Handler) updateComment(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	version, err := h.commentService.CommentUpdate(id, c.Request)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	c.Header("ETag", etag.Make(id, version))
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) deleteComment(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	if err := h.commentService.CommentDelete(id, c.Request); err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trad3r/hskills/apirest/internal/etag"
+)
+
+// writeEntity writes single resource with ETag header.
+// Not Modified is returned when client already has the same resource version
+func writeEntity(c *gin.Context, id int, version int, body any) {
+	tag := etag.Make(id, version)
+	c.Header("ETag", tag)
+
+	if etag.NoneMatch(c.GetHeader("If-None-Match"), tag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.JSON(http.StatusOK, body)
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/dataformat"
+)
+
+func (h *Handler) exportUsers(c *gin.Context) {
+	format := c.NegotiateFormat(dataformat.MIMENDJSON, dataformat.MIMECSV)
+	if len(format) == 0 {
+		writeProblem(c, custom_errors.ErrNotAcceptable)
+		return
+	}
+
+	h.streamUsers(c, format)
+}
+
+func (h *Handler) exportPosts(c *gin.Context) {
+	format := c.NegotiateFormat(dataformat.MIMENDJSON, dataformat.MIMECSV)
+	if len(format) == 0 {
+		writeProblem(c, custom_errors.ErrNotAcceptable)
+		return
+	}
+
+	h.streamPosts(c, format)
+}
+
+func (h *Handler) streamUsers(c *gin.Context, format string) {
+	c.Header("Content-Type", format)
+	writeExportError(c, h.userService.UserExport(c.Request, format, c.Writer))
+}
+
+func (h *Handler) streamPosts(c *gin.Context, format string) {
+	c.Header("Content-Type", format)
+	writeExportError(c, h.postService.PostExport(c.Request, format, c.Writer))
+}
+
+// listFormat negotiates list response format, JSON is the default one
+func listFormat(c *gin.Context) (string, error) {
+	format := c.NegotiateFormat(binding.MIMEJSON, dataformat.MIMENDJSON, dataformat.MIMECSV)
+	if len(format) == 0 {
+		return "", custom_errors.ErrNotAcceptable
+	}
+
+	return format, nil
+}
+
+// writeExportError writes problem if streaming hasn't started yet, otherwise the response is cut off
+func writeExportError(c *gin.Context, err error) {
+	if err == nil {
+		return
+	}
+
+	if !c.Writer.Written() {
+		writeProblem(c, err)
+		return
+	}
+
+	log.Printf("export error on %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+	c.Abort()
+}
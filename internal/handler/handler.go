@@ -4,35 +4,116 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/trad3r/hskills/apirest/internal/auth"
 	"github.com/trad3r/hskills/apirest/internal/service"
 )
 
 type Handler struct {
-	userService     service.IUserService
-	postService     service.IPostService
-	userPostService service.IUserPostService
+	userService       service.IUserService
+	postService       service.IPostService
+	userPostService   service.IUserPostService
+	auditService      service.IAuditService
+	tagService        service.ITagService
+	commentService    service.ICommentService
+	reactionService   service.IReactionService
+	attachmentService service.IAttachmentService
+	authService       service.IAuthService
+	apiKeyService     service.IAPIKeyService
 }
 
-func NewHandler(u service.IUserService, p service.IPostService, up service.IUserPostService) *Handler {
+func NewHandler(u service.IUserService, p service.IPostService, up service.IUserPostService, a service.IAuditService, t service.ITagService, c service.ICommentService, r service.IReactionService, at service.IAttachmentService, au service.IAuthService, ak service.IAPIKeyService) *Handler {
 	return &Handler{
-		userService:     u,
-		postService:     p,
-		userPostService: up,
+		userService:       u,
+		postService:       p,
+		userPostService:   up,
+		auditService:      a,
+		tagService:        t,
+		commentService:    c,
+		reactionService:   r,
+		attachmentService: at,
+		authService:       au,
+		apiKeyService:     ak,
 	}
 }
 
 func (h *Handler) Handlers() http.Handler {
-	r := gin.Default()
+	r := gin.New()
+	r.HandleMethodNotAllowed = true
+	r.Use(requestID, gin.Logger(), gin.CustomRecovery(recovery), h.authenticate)
 
-	r.GET("/users", h.getUsers)
+	r.NoRoute(func(c *gin.Context) {
+		abortWithProblem(c, Problem{Status: http.StatusNotFound, Code: "route_not_found", Title: "Route is not found"})
+	})
+	r.NoMethod(func(c *gin.Context) {
+		abortWithProblem(c, Problem{Status: http.StatusMethodNotAllowed, Code: "method_not_allowed", Title: "Method is not allowed"})
+	})
+
+	// every route but sign up and token exchange requires a scope, anonymous callers may only read
+	r.POST("/auth/login", h.login)
+	r.POST("/auth/refresh", h.refresh)
+	r.POST("/auth/logout", h.logout)
+
+	r.GET("/users", requireScope(auth.ScopeUsersRead), h.getUsers)
+	r.GET("/users/export", requireScope(auth.ScopeUsersRead), h.exportUsers)
+	r.POST("/users/import", requireScope(auth.ScopeUsersWrite), readTimeout(uploadReadTimeout), h.importUsers)
+	r.GET("/user/:id", requireScope(auth.ScopeUsersRead), h.getUser)
 	r.POST("/user", h.addUser)
-	r.PATCH("/user/:id", h.UpdateUser) // так проще
-	r.DELETE("/user/:id", h.deleteUser)
+	r.PATCH("/user/:id", requireScope(auth.ScopeUsersWrite), h.UpdateUser) // так проще
+	r.PUT("/user/:id", requireScope(auth.ScopeUsersWrite), h.replaceUser)
+	r.DELETE("/user/:id", requireScope(auth.ScopeUsersWrite), h.deleteUser)
+	r.PUT("/user/:id/password", requireScope(auth.ScopeUsersWrite), h.changePassword)
+	r.PUT("/user/:id/role", requireScope(auth.ScopeUsersWrite), h.setUserRole)
+	r.POST("/user/:id/restore", requireScope(auth.ScopeUsersWrite), h.restoreUser)
+	r.GET("/user/:id/history", requireScope(auth.ScopeUsersRead), h.getUserHistory)
+	r.GET("/user/:id/posts", requireScope(auth.ScopePostsRead), h.getUserPosts)
+	r.POST("/user/:id/posts", requireScope(auth.ScopePostsWrite), h.addUserPost)
+	r.POST("/users/bulk", requireScope(auth.ScopeUsersWrite), h.addUsers)
+	r.PATCH("/users/bulk", requireScope(auth.ScopeUsersWrite), h.updateUsers)
+	r.DELETE("/users/bulk", requireScope(auth.ScopeUsersWrite), h.deleteUsers)
+
+	r.GET("/posts", requireScope(auth.ScopePostsRead), h.getPosts)
+	r.GET("/posts/export", requireScope(auth.ScopePostsRead), h.exportPosts)
+	r.POST("/posts/import", requireScope(auth.ScopePostsWrite), readTimeout(uploadReadTimeout), h.importPosts)
+	r.POST("/posts/preview", requireScope(auth.ScopePostsRead), h.previewPost)
+	r.GET("/post/:id", requireScope(auth.ScopePostsRead), h.getPost)
+	r.GET("/post/by-slug/:slug", requireScope(auth.ScopePostsRead), h.getPostBySlug)
+	r.POST("/post", requireScope(auth.ScopePostsWrite), h.addPost)
+	r.PATCH("/post/:id", requireScope(auth.ScopePostsWrite), h.updatePost)
+	r.PUT("/post/:id", requireScope(auth.ScopePostsWrite), h.replacePost)
+	r.DELETE("/post/:id", requireScope(auth.ScopePostsWrite), h.deletePost)
+	r.POST("/post/:id/restore", requireScope(auth.ScopePostsWrite), h.restorePost)
+	r.POST("/post/:id/publish", requireScope(auth.ScopePostsWrite), h.publishPost)
+	r.POST("/post/:id/unpublish", requireScope(auth.ScopePostsWrite), h.unpublishPost)
+	r.POST("/post/:id/archive", requireScope(auth.ScopePostsWrite), h.archivePost)
+	r.GET("/post/:id/history", requireScope(auth.ScopePostsRead), h.getPostHistory)
+	r.GET("/post/:id/revisions", requireScope(auth.ScopePostsRead), h.getPostRevisions)
+	r.GET("/post/:id/revisions/:rev", requireScope(auth.ScopePostsRead), h.getPostRevision)
+	r.POST("/post/:id/revisions/:rev/restore", requireScope(auth.ScopePostsWrite), h.restorePostRevision)
+	r.GET("/post/:id/diff", requireScope(auth.ScopePostsRead), h.getPostDiff)
+	r.GET("/post/:id/comments", requireScope(auth.ScopePostsRead), h.getPostComments)
+	r.POST("/post/:id/comments", requireScope(auth.ScopePostsWrite), h.addPostComment)
+	r.PUT("/post/:id/reactions/:type", requireScope(auth.ScopePostsWrite), h.putPostReaction)
+	r.DELETE("/post/:id/reactions/:type", requireScope(auth.ScopePostsWrite), h.deletePostReaction)
+	r.GET("/post/:id/attachments", requireScope(auth.ScopePostsRead), h.getPostAttachments)
+	r.POST("/post/:id/attachments", requireScope(auth.ScopePostsWrite), readTimeout(uploadReadTimeout), h.addPostAttachment)
+	r.GET("/post/:id/attachments/:attachment", requireScope(auth.ScopePostsRead), h.getPostAttachment)
+	r.DELETE("/post/:id/attachments/:attachment", requireScope(auth.ScopePostsWrite), h.deletePostAttachment)
+	r.POST("/posts/bulk", requireScope(auth.ScopePostsWrite), h.addPosts)
+	r.PATCH("/posts/bulk", requireScope(auth.ScopePostsWrite), h.updatePosts)
+	r.DELETE("/posts/bulk", requireScope(auth.ScopePostsWrite), h.deletePosts)
+
+	r.PATCH("/comment/:id", requireScope(auth.ScopePostsWrite), h.updateComment)
+	r.DELETE("/comment/:id", requireScope(auth.ScopePostsWrite), h.deleteComment)
+
+	r.GET("/tags", requireScope(auth.ScopePostsRead), h.getTags)
 
-	r.GET("/posts", h.getPosts)
-	r.POST("/post", h.addPost)
-	r.PATCH("/post/:id", h.updatePost)
-	r.DELETE("/post/:id", h.deletePost)
+	r.GET("/admin/audit", requireScope(auth.ScopeAdmin), h.getAuditLog)
+	r.GET("/admin/api-keys", requireScope(auth.ScopeAdmin), h.getAPIKeys)
+	r.POST("/admin/api-keys", requireScope(auth.ScopeAdmin), h.addAPIKey)
+	r.DELETE("/admin/api-keys/:id", requireScope(auth.ScopeAdmin), h.revokeAPIKey)
+	r.POST("/admin/api-keys/:id/rotate", requireScope(auth.ScopeAdmin), h.rotateAPIKey)
+	r.PATCH("/admin/tag/:name", requireScope(auth.ScopeAdmin), h.renameTag)
+	r.POST("/admin/tag/:name/merge", requireScope(auth.ScopeAdmin), h.mergeTag)
 
 	//r.HandleFunc("/debug/pprof/", pprof.Index)
 	//r.HandleFunc("debug/pprof/cmdline", pprof.Cmdline)
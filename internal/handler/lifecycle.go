@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trad3r/hskills/apirest/internal/etag"
+)
+
+func (h *Handler) publishPost(c *gin.Context) {
+	h.transitPost(c, h.postService.PostPublish)
+}
+
+func (h *Handler) unpublishPost(c *gin.Context) {
+	h.transitPost(c, h.postService.PostUnpublish)
+}
+
+func (h *Handler) archivePost(c *gin.Context) {
+	h.transitPost(c, h.postService.PostArchive)
+}
+
+// transitPost runs post status transition and sets ETag of the changed post
+func (h *Handler) transitPost(c *gin.Context, transition func(id int, req *http.Request) (int, error)) {
+	id, err := pathID(c)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	version, err := transition(id, c.Request)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	c.Header("ETag", etag.Make(id, version))
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trad3r/hskills/apirest/internal/reqctx"
+)
+
+const (
+	requestIDHeader = "X-Request-ID"
+	// uploadReadTimeout is how long uploads and imports may take to send the body,
+	// server read timeout is short and meant for other requests
+	uploadReadTimeout = 10 * time.Minute
+)
+
+// requestID takes request ID from header or generates a new one and stores it in request context
+func requestID(c *gin.Context) {
+	id := c.GetHeader(requestIDHeader)
+	if len(id) == 0 || len(id) > 64 {
+		id = newRequestID()
+	}
+
+	c.Request = c.Request.WithContext(reqctx.WithRequestID(c.Request.Context(), id))
+	c.Header(requestIDHeader, id)
+
+	c.Next()
+}
+
+// readTimeout replaces server read deadline of request with timeout, so large bodies are not cut off
+func readTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := http.NewResponseController(c.Writer).SetReadDeadline(time.Now().Add(timeout)); err != nil {
+			abortWithProblem(c, Problem{Status: http.StatusInternalServerError, Code: "internal_error", Title: "Internal server error"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func recovery(c *gin.Context, _ any) {
+	abortWithProblem(c, Problem{
+		Status: http.StatusInternalServerError,
+		Code:   "internal_error",
+		Title:  "Internal server error",
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/pagination"
+)
+
+// writePage writes list page with Link and X-Total-Count headers
+func writePage[T any](c *gin.Context, page *models.Page[T]) {
+	if link := pagination.LinkHeader(c.Request.URL, page.Next, page.Prev); len(link) > 0 {
+		c.Header("Link", link)
+	}
+
+	if page.Total != nil {
+		c.Header("X-Total-Count", strconv.Itoa(*page.Total))
+	}
+
+	c.JSON(http.StatusOK, page)
+}
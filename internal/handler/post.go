@@ -1,55 +1,170 @@
 package handler
 
 import (
-	"log"
 	"net/http"
+	"net/url"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/trad3r/hskills/apirest/internal/etag"
 )
 
 func (h *Handler) getPosts(c *gin.Context) {
-	users, err := h.postService.PostList(c.Request)
+	format, err := listFormat(c)
 	if err != nil {
-		c.Writer.WriteHeader(http.StatusBadRequest)
+		writeProblem(c, err)
 		return
 	}
 
-	c.Writer.WriteHeader(http.StatusOK)
-	c.JSON(http.StatusOK, users)
+	if format != binding.MIMEJSON {
+		h.streamPosts(c, format)
+		return
+	}
+
+	posts, err := h.postService.PostList(c.Request)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	writePage(c, posts)
+}
+
+func (h *Handler) getUserPosts(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	posts, err := h.userPostService.AuthorPosts(id, c.Request)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	writePage(c, posts)
+}
+
+func (h *Handler) getPost(c *gin.Context) {
+	post, err := h.postService.PostGet(c.Request)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	writeEntity(c, post.ID, post.Version, post)
+}
+
+// getPostBySlug serves post by slug, former slugs are redirected to the current one
+func (h *Handler) getPostBySlug(c *gin.Context) {
+	slug := c.Param("slug")
+
+	post, err := h.postService.PostGetBySlug(slug, c.Request)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	if post.Slug != slug {
+		location := url.URL{Path: "/post/by-slug/" + post.Slug, RawQuery: c.Request.URL.RawQuery}
+		c.Redirect(http.StatusMovedPermanently, location.String())
+		return
+	}
+
+	writeEntity(c, post.ID, post.Version, post)
+}
+
+func (h *Handler) previewPost(c *gin.Context) {
+	preview, err := h.postService.PostPreview(c.Request)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
 }
 
 func (h *Handler) addPost(c *gin.Context) {
-	err := h.userPostService.AddPost(c.Request)
+	if err := h.userPostService.AddPost(c.Request); err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	c.Status(http.StatusCreated)
+}
+
+func (h *Handler) addUserPost(c *gin.Context) {
+	id, err := pathID(c)
 	if err != nil {
-		c.Writer.WriteHeader(http.StatusBadRequest)
-		if _, err = c.Writer.Write([]byte(err.Error())); err != nil {
-			log.Println(err)
-		}
-	} else {
-		c.Writer.WriteHeader(http.StatusCreated)
+		writeProblem(c, err)
+		return
+	}
+
+	if err := h.userPostService.AddAuthorPost(id, c.Request); err != nil {
+		writeProblem(c, err)
+		return
 	}
+
+	c.Status(http.StatusCreated)
 }
 
 func (h *Handler) updatePost(c *gin.Context) {
-	err := h.postService.PostUpdate(c.Request)
+	id, err := pathID(c)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	version, err := h.postService.PostUpdate(c.Request)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	c.Header("ETag", etag.Make(id, version))
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) replacePost(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	version, err := h.postService.PostReplace(c.Request)
 	if err != nil {
-		c.Writer.WriteHeader(http.StatusBadRequest)
-		if _, err = c.Writer.Write([]byte(err.Error())); err != nil {
-			log.Println(err)
-		}
-	} else {
-		c.Writer.WriteHeader(http.StatusOK)
+		writeProblem(c, err)
+		return
 	}
+
+	c.Header("ETag", etag.Make(id, version))
+	c.Status(http.StatusOK)
 }
 
 func (h *Handler) deletePost(c *gin.Context) {
-	err := h.postService.PostDelete(c.Request)
+	if err := h.postService.PostDelete(c.Request); err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) restorePost(c *gin.Context) {
+	id, err := pathID(c)
 	if err != nil {
-		c.Writer.WriteHeader(http.StatusBadRequest)
-		if _, err = c.Writer.Write([]byte(err.Error())); err != nil {
-			log.Println(err)
-		}
-	} else {
-		c.Writer.WriteHeader(http.StatusOK)
+		writeProblem(c, err)
+		return
 	}
+
+	version, err := h.postService.PostRestore(id, c.Request)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	c.Header("ETag", etag.Make(id, version))
+	c.Status(http.StatusOK)
 }
@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/reqctx"
+)
+
+const problemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details body
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Code      string `json:"code"`
+	Detail    string `json:"detail,omitempty"`
+	Field     string `json:"field,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// problemFromError maps service errors to problem details
+func problemFromError(err error) Problem {
+	var validationErr *custom_errors.ValidationError
+
+	switch {
+	case errors.As(err, &validationErr):
+		return Problem{
+			Status: http.StatusUnprocessableEntity,
+			Code:   "validation_failed",
+			Title:  "Request validation failed",
+			Detail: validationErr.Message,
+			Field:  validationErr.Field,
+		}
+	case errors.Is(err, custom_errors.ErrInvalidBody):
+		return Problem{
+			Status: http.StatusBadRequest,
+			Code:   "malformed_body",
+			Title:  "Request body is malformed",
+			Detail: err.Error(),
+		}
+	case errors.Is(err, custom_errors.ErrUnsupportedMediaType):
+		return Problem{
+			Status: http.StatusUnsupportedMediaType,
+			Code:   "unsupported_media_type",
+			Title:  "Unsupported media type",
+			Detail: err.Error(),
+		}
+	case errors.Is(err, custom_errors.ErrNotAcceptable):
+		return Problem{
+			Status: http.StatusNotAcceptable,
+			Code:   "not_acceptable",
+			Title:  "Not acceptable",
+			Detail: err.Error(),
+		}
+	case errors.Is(err, custom_errors.ErrUnauthorized):
+		return Problem{
+			Status: http.StatusUnauthorized,
+			Code:   "unauthorized",
+			Title:  "Authentication is required",
+		}
+	case errors.Is(err, custom_errors.ErrInvalidCredentials):
+		return Problem{
+			Status: http.StatusUnauthorized,
+			Code:   "invalid_credentials",
+			Title:  "Login or password is wrong",
+		}
+	case errors.Is(err, custom_errors.ErrInvalidToken):
+		return Problem{
+			Status: http.StatusUnauthorized,
+			Code:   "invalid_token",
+			Title:  "Token is invalid or expired",
+		}
+	case errors.Is(err, custom_errors.ErrForbidden):
+		return Problem{
+			Status: http.StatusForbidden,
+			Code:   "forbidden",
+			Title:  "Operation is not allowed",
+			Detail: err.Error(),
+		}
+	case errors.Is(err, custom_errors.ErrUserNotFound):
+		return Problem{
+			Status: http.StatusNotFound,
+			Code:   "user_not_found",
+			Title:  "User is not found",
+		}
+	case errors.Is(err, custom_errors.ErrPostNotFound):
+		return Problem{
+			Status: http.StatusNotFound,
+			Code:   "post_not_found",
+			Title:  "Post is not found",
+		}
+	case errors.Is(err, custom_errors.ErrRevisionNotFound):
+		return Problem{
+			Status: http.StatusNotFound,
+			Code:   "revision_not_found",
+			Title:  "Post revision is not found",
+		}
+	case errors.Is(err, custom_errors.ErrTagNotFound):
+		return Problem{
+			Status: http.StatusNotFound,
+			Code:   "tag_not_found",
+			Title:  "Tag is not found",
+		}
+	case errors.Is(err, custom_errors.ErrCommentNotFound):
+		return Problem{
+			Status: http.StatusNotFound,
+			Code:   "comment_not_found",
+			Title:  "Comment is not found",
+		}
+	case errors.Is(err, custom_errors.ErrAttachmentNotFound):
+		return Problem{
+			Status: http.StatusNotFound,
+			Code:   "attachment_not_found",
+			Title:  "Attachment is not found",
+		}
+	case errors.Is(err, custom_errors.ErrAPIKeyNotFound):
+		return Problem{
+			Status: http.StatusNotFound,
+			Code:   "api_key_not_found",
+			Title:  "API key is not found",
+		}
+	case errors.Is(err, custom_errors.ErrPayloadTooLarge):
+		return Problem{
+			Status: http.StatusRequestEntityTooLarge,
+			Code:   "payload_too_large",
+			Title:  "Request payload is too large",
+			Detail: err.Error(),
+		}
+	case errors.Is(err, custom_errors.ErrTransitionNotAllowed):
+		return Problem{
+			Status: http.StatusConflict,
+			Code:   "transition_not_allowed",
+			Title:  "Post status transition is not allowed",
+			Detail: err.Error(),
+		}
+	case errors.Is(err, custom_errors.ErrPreconditionFailed):
+		return Problem{
+			Status: http.StatusPreconditionFailed,
+			Code:   "precondition_failed",
+			Title:  "Resource was modified",
+			Detail: "If-Match does not match current resource version",
+		}
+	case errors.Is(err, custom_errors.ErrBulkAborted):
+		return Problem{
+			Status: http.StatusFailedDependency,
+			Code:   "aborted",
+			Title:  "Item is not written because another item failed",
+		}
+	case errors.Is(err, custom_errors.ErrConflict):
+		return Problem{
+			Status: http.StatusConflict,
+			Code:   "conflict",
+			Title:  "Resource conflicts with existing data",
+			Detail: err.Error(),
+		}
+	}
+
+	return Problem{
+		Status: http.StatusInternalServerError,
+		Code:   "internal_error",
+		Title:  "Internal server error",
+	}
+}
+
+// writeProblem writes err to response as problem details and aborts the request
+func writeProblem(c *gin.Context, err error) {
+	p := problemFromError(err)
+	if p.Status == http.StatusInternalServerError {
+		log.Printf("internal error on %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+	}
+
+	abortWithProblem(c, p)
+}
+
+func abortWithProblem(c *gin.Context, p Problem) {
+	p.Type = "urn:apirest:problem:" + p.Code
+	p.Instance = c.Request.URL.Path
+	p.RequestID = reqctx.RequestID(c.Request.Context())
+
+	if p.Status == http.StatusUnauthorized {
+		c.Header("WWW-Authenticate", `Bearer realm="apirest"`)
+	}
+
+	c.Header("Content-Type", problemContentType)
+	c.AbortWithStatusJSON(p.Status, p)
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/etag"
+)
+
+func (h *Handler) getPostRevisions(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	revisions, err := h.postService.PostRevisions(id, c.Request)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	writePage(c, revisions)
+}
+
+func (h *Handler) getPostRevision(c *gin.Context) {
+	id, revision, err := pathRevision(c)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	rev, err := h.postService.PostRevision(id, revision, c.Request)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rev)
+}
+
+func (h *Handler) getPostDiff(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	diff, err := h.postService.PostDiff(id, c.Request)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+func (h *Handler) restorePostRevision(c *gin.Context) {
+	id, revision, err := pathRevision(c)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	version, err := h.postService.PostRestoreRevision(id, revision, c.Request)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	c.Header("ETag", etag.Make(id, version))
+	c.Status(http.StatusOK)
+}
+
+// pathRevision parses post ID and revision number path params
+func pathRevision(c *gin.Context) (int, int, error) {
+	id, err := pathID(c)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	revision, err := strconv.Atoi(c.Param("rev"))
+	if err != nil || revision < 1 {
+		return 0, 0, custom_errors.NewValidationError("rev", "must be a positive integer")
+	}
+
+	return id, revision, nil
+}
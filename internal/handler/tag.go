@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) getTags(c *gin.Context) {
+	tags, err := h.tagService.TagList(c.Request)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+func (h *Handler) renameTag(c *gin.Context) {
+	if err := h.tagService.TagRename(c.Param("name"), c.Request); err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) mergeTag(c *gin.Context) {
+	if err := h.tagService.TagMerge(c.Param("name"), c.Request); err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
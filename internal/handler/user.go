@@ -1,88 +1,153 @@
 package handler
 
 import (
-	"errors"
-	"log"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/etag"
 )
 
 // frameworks: echo, gin
 
 func (h *Handler) getUsers(c *gin.Context) {
+	format, err := listFormat(c)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	if format != binding.MIMEJSON {
+		h.streamUsers(c, format)
+		return
+	}
+
 	users, err := h.userService.UserList(c.Request)
 	if err != nil {
-		c.Writer.WriteHeader(http.StatusBadRequest)
-		if _, err := c.Writer.Write([]byte(err.Error())); err != nil {
-			log.Printf("failed to write response: %v", err)
-		}
+		writeProblem(c, err)
+		return
+	}
+
+	writePage(c, users)
+}
+
+func (h *Handler) getUser(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	user, err := h.userService.UserGet(id, c.Request)
+	if err != nil {
+		writeProblem(c, err)
 		return
 	}
 
-	c.Writer.WriteHeader(http.StatusOK)
-	c.JSON(http.StatusOK, users)
+	writeEntity(c, user.ID, user.Version, user)
 }
 
 func (h *Handler) addUser(c *gin.Context) {
 	user, err := h.userService.UserAdd(c.Request)
 	if err != nil {
-		log.Printf("failed to add user: %v\n", err)
-		c.Writer.WriteHeader(http.StatusBadRequest)
-		if _, err = c.Writer.Write([]byte("failed to add user")); err != nil {
-			log.Printf("failed to write body: %v\n", err)
-		}
+		writeProblem(c, err)
 		return
 	}
 
-	c.Writer.WriteHeader(http.StatusOK)
-	c.JSON(http.StatusOK, user)
+	c.JSON(http.StatusCreated, user)
 }
 
 func (h *Handler) UpdateUser(c *gin.Context) {
-	idVal := c.Param("id")
-	id, err := strconv.Atoi(idVal)
+	id, err := pathID(c)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	version, err := h.userService.UserUpdate(id, c.Request)
 	if err != nil {
-		c.Writer.WriteHeader(http.StatusBadRequest)
-		if _, err = c.Writer.Write([]byte("invalid path param ID")); err != nil {
-			log.Printf("failed to write body: %v\n", err)
-		}
+		writeProblem(c, err)
+		return
+	}
+
+	c.Header("ETag", etag.Make(id, version))
+	c.Status(http.StatusOK)
+}
 
+func (h *Handler) replaceUser(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		writeProblem(c, err)
 		return
 	}
 
-	if err := h.userService.UserUpdate(id, c.Request); err != nil {
-		if errors.Is(err, custom_errors.ErrUserNotFound) {
-			c.Writer.WriteHeader(http.StatusNotFound)
-		} else {
-			c.Writer.WriteHeader(http.StatusBadRequest) // 404
-		}
-	} else {
-		c.Writer.WriteHeader(http.StatusOK)
+	version, err := h.userService.UserReplace(id, c.Request)
+	if err != nil {
+		writeProblem(c, err)
+		return
 	}
+
+	c.Header("ETag", etag.Make(id, version))
+	c.Status(http.StatusOK)
 }
 
 func (h *Handler) deleteUser(c *gin.Context) {
-	idVal := c.Param("id")
-	id, err := strconv.Atoi(idVal)
+	id, err := pathID(c)
 	if err != nil {
-		c.Writer.WriteHeader(http.StatusBadRequest)
-		if _, err = c.Writer.Write([]byte("invalid path param ID")); err != nil {
-			log.Printf("failed to write body: %v\n", err)
-		}
-
+		writeProblem(c, err)
 		return
 	}
 
 	if err := h.userService.UserDelete(id, c.Request); err != nil {
-		if errors.Is(err, custom_errors.ErrUserNotFound) {
-			c.Writer.WriteHeader(http.StatusNotFound)
-		} else {
-			c.Writer.WriteHeader(http.StatusBadRequest) // 404
-		}
-	} else {
-		c.Writer.WriteHeader(http.StatusOK)
+		writeProblem(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) restoreUser(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	version, err := h.userService.UserRestore(id, c.Request)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	c.Header("ETag", etag.Make(id, version))
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) setUserRole(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		writeProblem(c, err)
+		return
+	}
+
+	version, err := h.userService.UserSetRole(id, c.Request)
+	if err != nil {
+		writeProblem(c, err)
+		return
 	}
+
+	c.Header("ETag", etag.Make(id, version))
+	c.Status(http.StatusNoContent)
+}
+
+// pathID parses ID path param
+func pathID(c *gin.Context) (int, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		return 0, custom_errors.NewValidationError("id", "must be a positive integer")
+	}
+
+	return id, nil
 }
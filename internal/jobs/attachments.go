@@ -0,0 +1,86 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/TRAD3R/tlog"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/trad3r/hskills/apirest/internal/blobstore"
+	"github.com/trad3r/hskills/apirest/internal/repository/postgres"
+)
+
+// cleanupBatch is how many objects are removed between queue updates
+const cleanupBatch = 100
+
+// AttachmentCleaner removes objects of removed attachments from storage, including attachments of purged posts
+type AttachmentCleaner struct {
+	attachments postgres.IAttachmentRepository
+	store       blobstore.Store
+	logger      *tlog.Logger
+}
+
+func NewAttachmentCleaner(logger *tlog.Logger, db *pgxpool.Pool, store blobstore.Store) *AttachmentCleaner {
+	return &AttachmentCleaner{
+		attachments: postgres.NewAttachmentRepository(db),
+		store:       store,
+		logger:      logger,
+	}
+}
+
+// Run cleans storage every interval until ctx is done
+func (c *AttachmentCleaner) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		c.Clean(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Clean removes queued objects batch by batch, objects failed to remove stay queued for the next run
+func (c *AttachmentCleaner) Clean(ctx context.Context) {
+	var removed int
+
+	for ctx.Err() == nil {
+		keys, err := c.attachments.Orphans(ctx, cleanupBatch)
+		if err != nil {
+			c.logger.Error("error reading removed attachments", "err", err.Error())
+			return
+		}
+
+		deleted := make([]string, 0, len(keys))
+		for _, key := range keys {
+			if err := c.store.Delete(ctx, key); err != nil {
+				c.logger.Error("error removing attachment object", "key", key, "err", err.Error())
+				continue
+			}
+
+			deleted = append(deleted, key)
+		}
+
+		if len(deleted) > 0 {
+			if err := c.attachments.ForgetOrphans(ctx, deleted); err != nil {
+				c.logger.Error("error forgetting removed attachments", "err", err.Error())
+				return
+			}
+		}
+
+		removed += len(deleted)
+
+		// a batch with failures is retried on the next run rather than in a loop
+		if len(keys) < cleanupBatch || len(deleted) < len(keys) {
+			break
+		}
+	}
+
+	if removed > 0 {
+		c.logger.Info("attachment objects removed", "objects", removed)
+	}
+}
@@ -0,0 +1,58 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/TRAD3R/tlog"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/trad3r/hskills/apirest/internal/repository/postgres"
+	"github.com/trad3r/hskills/apirest/internal/reqctx"
+)
+
+// publishActor is recorded in audit log as the author of scheduled publications
+const publishActor = "system:scheduler"
+
+// Publisher publishes scheduled posts when their publish time arrives
+type Publisher struct {
+	posts  postgres.IPostRepository
+	logger *tlog.Logger
+}
+
+func NewPublisher(logger *tlog.Logger, db *pgxpool.Pool) *Publisher {
+	return &Publisher{
+		posts:  postgres.NewPostRepository(db),
+		logger: logger,
+	}
+}
+
+// Run publishes due posts every interval until ctx is done. The first run publishes posts missed during downtime
+func (p *Publisher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		p.Publish(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Publish publishes every scheduled post whose publish time has come
+func (p *Publisher) Publish(ctx context.Context) {
+	ctx = reqctx.WithActor(ctx, publishActor)
+
+	posts, err := p.posts.PublishDue(ctx, time.Now())
+	if err != nil {
+		p.logger.Error("error publishing scheduled posts", "err", err.Error())
+		return
+	}
+
+	if posts > 0 {
+		p.logger.Info("scheduled posts published", "posts", posts)
+	}
+}
@@ -0,0 +1,67 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/TRAD3R/tlog"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/trad3r/hskills/apirest/internal/repository/postgres"
+	"github.com/trad3r/hskills/apirest/internal/reqctx"
+)
+
+// purgeActor is recorded in audit log as the author of purged rows removal
+const purgeActor = "system:purge"
+
+// Purger removes users and posts which are in trash longer than retention
+type Purger struct {
+	users     postgres.IUserRepository
+	posts     postgres.IPostRepository
+	retention time.Duration
+	logger    *tlog.Logger
+}
+
+func NewPurger(logger *tlog.Logger, db *pgxpool.Pool, retention time.Duration) *Purger {
+	return &Purger{
+		users:     postgres.NewUserRepository(db),
+		posts:     postgres.NewPostRepository(db),
+		retention: retention,
+		logger:    logger,
+	}
+}
+
+// Run purges trash every interval until ctx is done
+func (p *Purger) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		p.Purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge removes rows deleted before retention period, users go first as their posts are removed with them
+func (p *Purger) Purge(ctx context.Context) {
+	ctx = reqctx.WithActor(ctx, purgeActor)
+	before := time.Now().Add(-p.retention)
+
+	users, err := p.users.Purge(ctx, before)
+	if err != nil {
+		p.logger.Error("error purging users", "err", err.Error())
+	}
+
+	posts, err := p.posts.Purge(ctx, before)
+	if err != nil {
+		p.logger.Error("error purging posts", "err", err.Error())
+	}
+
+	if users > 0 || posts > 0 {
+		p.logger.Info("trash purged", "users", users, "posts", posts)
+	}
+}
@@ -0,0 +1,305 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidPatch = errors.New("invalid patch")
+	ErrPathNotFound = errors.New("path is not found")
+	ErrTestFailed   = errors.New("test operation failed")
+)
+
+// Operation is RFC 6902 patch operation
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Apply applies RFC 6902 JSON patch to doc and returns patched document.
+// Operations are applied in order and patch fails as a whole
+func Apply(doc []byte, patch []byte) ([]byte, error) {
+	var ops []Operation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPatch, err)
+	}
+
+	var root any
+	if err := json.Unmarshal(doc, &root); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal document: %w", err)
+	}
+
+	for i, op := range ops {
+		var err error
+		root, err = applyOperation(root, op)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+
+	return json.Marshal(root)
+}
+
+func applyOperation(root any, op Operation) (any, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, fmt.Errorf("%w: value is required", ErrInvalidPatch)
+		}
+
+		var value any
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidPatch, err)
+		}
+
+		switch op.Op {
+		case "add":
+			return add(root, path, value)
+		case "replace":
+			if root, _, err = remove(root, path); err != nil {
+				return nil, err
+			}
+
+			return add(root, path, value)
+		default:
+			current, err := get(root, path)
+			if err != nil {
+				return nil, err
+			}
+
+			if !reflect.DeepEqual(current, value) {
+				return nil, ErrTestFailed
+			}
+
+			return root, nil
+		}
+	case "remove":
+		root, _, err = remove(root, path)
+		return root, err
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+
+		var value any
+		if op.Op == "move" {
+			if isPrefix(from, path) && len(from) < len(path) {
+				return nil, fmt.Errorf("%w: can't move value into itself", ErrInvalidPatch)
+			}
+
+			root, value, err = remove(root, from)
+		} else {
+			value, err = get(root, from)
+			value = deepCopy(value)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return add(root, path, value)
+	}
+
+	return nil, fmt.Errorf("%w: unknown operation %q", ErrInvalidPatch, op.Op)
+}
+
+// parsePointer splits RFC 6901 JSON pointer into unescaped reference tokens
+func parsePointer(pointer string) ([]string, error) {
+	if len(pointer) == 0 {
+		return nil, nil
+	}
+
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("%w: pointer %q must start with /", ErrInvalidPatch, pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+func get(node any, path []string) (any, error) {
+	for _, token := range path {
+		switch n := node.(type) {
+		case map[string]any:
+			v, ok := n[token]
+			if !ok {
+				return nil, ErrPathNotFound
+			}
+			node = v
+		case []any:
+			i, err := arrayIndex(token, len(n)-1)
+			if err != nil {
+				return nil, err
+			}
+			node = n[i]
+		default:
+			return nil, ErrPathNotFound
+		}
+	}
+
+	return node, nil
+}
+
+func add(root any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	parent, err := get(root, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	token := path[len(path)-1]
+
+	switch p := parent.(type) {
+	case map[string]any:
+		p[token] = value
+		return root, nil
+	case []any:
+		i := len(p)
+		if token != "-" {
+			if i, err = arrayIndex(token, len(p)); err != nil {
+				return nil, err
+			}
+		}
+
+		p = append(p, nil)
+		copy(p[i+1:], p[i:])
+		p[i] = value
+
+		return replaceNode(root, path[:len(path)-1], p)
+	}
+
+	return nil, ErrPathNotFound
+}
+
+func remove(root any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, root, nil
+	}
+
+	parent, err := get(root, path[:len(path)-1])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	token := path[len(path)-1]
+
+	switch p := parent.(type) {
+	case map[string]any:
+		v, ok := p[token]
+		if !ok {
+			return nil, nil, ErrPathNotFound
+		}
+
+		delete(p, token)
+		return root, v, nil
+	case []any:
+		i, err := arrayIndex(token, len(p)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		v := p[i]
+		p = append(p[:i:i], p[i+1:]...)
+
+		root, err = replaceNode(root, path[:len(path)-1], p)
+		return root, v, err
+	}
+
+	return nil, nil, ErrPathNotFound
+}
+
+// replaceNode puts value at path, arrays have to be replaced in their parents after resize
+func replaceNode(root any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	parent, err := get(root, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	token := path[len(path)-1]
+
+	switch p := parent.(type) {
+	case map[string]any:
+		p[token] = value
+	case []any:
+		i, err := arrayIndex(token, len(p)-1)
+		if err != nil {
+			return nil, err
+		}
+		p[i] = value
+	}
+
+	return root, nil
+}
+
+func arrayIndex(token string, max int) (int, error) {
+	if len(token) > 1 && token[0] == '0' {
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrInvalidPatch, token)
+	}
+
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrInvalidPatch, token)
+	}
+
+	if i > max {
+		return 0, ErrPathNotFound
+	}
+
+	return i, nil
+}
+
+func isPrefix(prefix []string, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func deepCopy(v any) any {
+	switch n := v.(type) {
+	case map[string]any:
+		c := make(map[string]any, len(n))
+		for k, item := range n {
+			c[k] = deepCopy(item)
+		}
+		return c
+	case []any:
+		c := make([]any, len(n))
+		for i, item := range n {
+			c[i] = deepCopy(item)
+		}
+		return c
+	}
+
+	return v
+}
@@ -0,0 +1,103 @@
+package jsonpatch_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trad3r/hskills/apirest/internal/jsonpatch"
+)
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		doc      string
+		patch    string
+		expected string
+		err      error
+	}{
+		{
+			name:     "replace",
+			doc:      `{"subject":"a","body":"b"}`,
+			patch:    `[{"op":"replace","path":"/subject","value":"c"}]`,
+			expected: `{"subject":"c","body":"b"}`,
+		},
+		{
+			name:     "add and remove",
+			doc:      `{"subject":"a","body":"b"}`,
+			patch:    `[{"op":"remove","path":"/body"},{"op":"add","path":"/tags","value":["x"]}]`,
+			expected: `{"subject":"a","tags":["x"]}`,
+		},
+		{
+			name:     "array insert and append",
+			doc:      `{"tags":["a","c"]}`,
+			patch:    `[{"op":"add","path":"/tags/1","value":"b"},{"op":"add","path":"/tags/-","value":"d"}]`,
+			expected: `{"tags":["a","b","c","d"]}`,
+		},
+		{
+			name:     "array remove",
+			doc:      `{"tags":["a","b","c"]}`,
+			patch:    `[{"op":"remove","path":"/tags/1"}]`,
+			expected: `{"tags":["a","c"]}`,
+		},
+		{
+			name:     "move and copy",
+			doc:      `{"a":{"x":1},"b":{}}`,
+			patch:    `[{"op":"move","from":"/a/x","path":"/b/y"},{"op":"copy","from":"/b","path":"/c"}]`,
+			expected: `{"a":{},"b":{"y":1},"c":{"y":1}}`,
+		},
+		{
+			name:     "escaped pointer",
+			doc:      `{"a/b":1,"m~n":2}`,
+			patch:    `[{"op":"replace","path":"/a~1b","value":3},{"op":"remove","path":"/m~0n"}]`,
+			expected: `{"a/b":3}`,
+		},
+		{
+			name:     "test passes",
+			doc:      `{"subject":"a"}`,
+			patch:    `[{"op":"test","path":"/subject","value":"a"},{"op":"replace","path":"/subject","value":null}]`,
+			expected: `{"subject":null}`,
+		},
+		{
+			name:  "test fails",
+			doc:   `{"subject":"a"}`,
+			patch: `[{"op":"test","path":"/subject","value":"b"}]`,
+			err:   jsonpatch.ErrTestFailed,
+		},
+		{
+			name:  "replace missing",
+			doc:   `{"subject":"a"}`,
+			patch: `[{"op":"replace","path":"/body","value":"b"}]`,
+			err:   jsonpatch.ErrPathNotFound,
+		},
+		{
+			name:  "unknown operation",
+			doc:   `{}`,
+			patch: `[{"op":"merge","path":"/a","value":1}]`,
+			err:   jsonpatch.ErrInvalidPatch,
+		},
+		{
+			name:  "not a list",
+			doc:   `{}`,
+			patch: `{"op":"add"}`,
+			err:   jsonpatch.ErrInvalidPatch,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			res, err := jsonpatch.Apply([]byte(tc.doc), []byte(tc.patch))
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expected, string(res))
+		})
+	}
+}
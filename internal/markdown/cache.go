@@ -0,0 +1,84 @@
+package markdown
+
+import (
+	"container/list"
+	"sync"
+)
+
+type entry struct {
+	id       int
+	checksum [32]byte
+	html     string
+}
+
+// cache is LRU of rendered posts by post ID, entry is valid for the body with the same checksum only
+type cache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[int]*list.Element
+}
+
+func newCache(size int) *cache {
+	return &cache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[int]*list.Element),
+	}
+}
+
+func (c *cache) get(id int, checksum [32]byte) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[id]
+	if !ok {
+		return "", false
+	}
+
+	e := el.Value.(*entry)
+	if e.checksum != checksum {
+		c.order.Remove(el)
+		delete(c.entries, id)
+
+		return "", false
+	}
+
+	c.order.MoveToFront(el)
+
+	return e.html, true
+}
+
+func (c *cache) put(id int, checksum [32]byte, html string) {
+	if c.size <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[id]; ok {
+		el.Value = &entry{id: id, checksum: checksum, html: html}
+		c.order.MoveToFront(el)
+
+		return
+	}
+
+	c.entries[id] = c.order.PushFront(&entry{id: id, checksum: checksum, html: html})
+
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*entry).id)
+	}
+}
+
+func (c *cache) remove(id int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[id]; ok {
+		c.order.Remove(el)
+		delete(c.entries, id)
+	}
+}
@@ -0,0 +1,66 @@
+// Package markdown renders post bodies written in Markdown to HTML safe to embed into pages
+package markdown
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// Renderer converts GitHub flavoured Markdown to sanitized HTML, rendered posts are cached
+type Renderer struct {
+	md     goldmark.Markdown
+	policy *bluemonday.Policy
+	cache  *cache
+}
+
+// New makes renderer caching at most cacheSize posts
+func New(cacheSize int) *Renderer {
+	// raw HTML of the source is omitted by goldmark, the policy removes whatever unsafe is left such as javascript: links
+	policy := bluemonday.UGCPolicy()
+	policy.RequireNoFollowOnLinks(true)
+	policy.AllowAttrs("class").Matching(bluemonday.SpaceSeparatedTokens).OnElements("code")
+
+	return &Renderer{
+		md:     goldmark.New(goldmark.WithExtensions(extension.GFM)),
+		policy: policy,
+		cache:  newCache(cacheSize),
+	}
+}
+
+// Render renders source without caching, it is meant for unsaved drafts
+func (r *Renderer) Render(source string) (string, error) {
+	var buf bytes.Buffer
+	if err := r.md.Convert([]byte(source), &buf); err != nil {
+		return "", fmt.Errorf("error while rendering markdown: %w", err)
+	}
+
+	return r.policy.Sanitize(buf.String()), nil
+}
+
+// RenderPost renders body of post id. Cached HTML is reused while the body is the same,
+// so an updated post is rendered again and its old HTML is dropped
+func (r *Renderer) RenderPost(id int, body string) (string, error) {
+	checksum := sha256.Sum256([]byte(body))
+	if html, ok := r.cache.get(id, checksum); ok {
+		return html, nil
+	}
+
+	html, err := r.Render(body)
+	if err != nil {
+		return "", err
+	}
+
+	r.cache.put(id, checksum, html)
+
+	return html, nil
+}
+
+// Forget drops cached HTML of post id
+func (r *Renderer) Forget(id int) {
+	r.cache.remove(id)
+}
@@ -0,0 +1,55 @@
+package markdown_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trad3r/hskills/apirest/internal/markdown"
+)
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{name: "Emphasis", source: "**bold** _it_", expected: "<p><strong>bold</strong> <em>it</em></p>\n"},
+		{name: "Strikethrough", source: "~~old~~", expected: "<p><del>old</del></p>\n"},
+		{name: "Code language", source: "```go\nx := 1\n```", expected: "<pre><code class=\"language-go\">x := 1\n</code></pre>\n"},
+		{name: "Link", source: "[site](https://example.com)", expected: "<p><a href=\"https://example.com\" rel=\"nofollow\">site</a></p>\n"},
+		{name: "Raw script", source: "<script>alert(1)</script>", expected: "\n"},
+		{name: "Script link", source: "[x](javascript:alert(1))", expected: "<p>x</p>\n"},
+		{name: "Inline handler", source: "<img src=x onerror=alert(1)>", expected: "\n"},
+	}
+
+	renderer := markdown.New(10)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			html, err := renderer.Render(tc.source)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, html)
+		})
+	}
+}
+
+func TestRenderPostCache(t *testing.T) {
+	t.Parallel()
+
+	renderer := markdown.New(1)
+
+	html, err := renderer.RenderPost(1, "first")
+	require.NoError(t, err)
+	assert.Equal(t, "<p>first</p>\n", html)
+
+	html, err = renderer.RenderPost(1, "updated")
+	require.NoError(t, err)
+	assert.Equal(t, "<p>updated</p>\n", html)
+
+	html, err = renderer.RenderPost(2, "other")
+	require.NoError(t, err)
+	assert.Equal(t, "<p>other</p>\n", html)
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Attachment is a file of post, its content is kept in blob storage under StorageKey
+type Attachment struct {
+	ID          int    `json:"id"`
+	PostID      int    `json:"post_id"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	// Checksum is hex encoded SHA-256 of the content
+	Checksum   string    `json:"checksum"`
+	CreatedAt  time.Time `json:"created_at"`
+	StorageKey string    `json:"-"`
+}
@@ -0,0 +1,23 @@
+package models
+
+// TokenPair is issued on login and refresh, refresh token is single use
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	// ExpiresIn is lifetime of access token in seconds
+	ExpiresIn int `json:"expires_in"`
+}
+
+// Credentials are login credentials of user
+type Credentials struct {
+	UserID       int
+	Login        string
+	PasswordHash string
+}
+
+// Session is a login of user, see migration 00015
+type Session struct {
+	ID     int
+	UserID int
+}
@@ -0,0 +1,7 @@
+package models
+
+// BulkResult is an outcome of one bulk request item
+type BulkResult struct {
+	ID  int
+	Err error
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Comment is a post comment, replies have parent comment
+type Comment struct {
+	ID         int        `json:"id"`
+	PostID     int        `json:"post_id"`
+	ParentID   *int       `json:"parent_id"`
+	Author     *User      `json:"author"`
+	Body       string     `json:"body"`
+	ReplyCount int        `json:"reply_count"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  *time.Time `json:"updated_at"`
+	Version    int        `json:"-"`
+}
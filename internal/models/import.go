@@ -0,0 +1,26 @@
+package models
+
+// ImportReport is a result of data import, errors refer to input lines
+type ImportReport struct {
+	DryRun bool `json:"dry_run"`
+	// Aborted means nothing is written because of failed rows in atomic mode
+	Aborted bool          `json:"aborted"`
+	Total   int           `json:"total"`
+	Created int           `json:"created"`
+	Updated int           `json:"updated"`
+	Failed  int           `json:"failed"`
+	Errors  []ImportError `json:"errors"`
+}
+
+type ImportError struct {
+	Line    int    `json:"line"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportResult is an outcome of one imported row
+type ImportResult struct {
+	ID      int
+	Created bool
+	Err     error
+}
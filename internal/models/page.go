@@ -0,0 +1,72 @@
+package models
+
+import "encoding/json"
+
+// Page is a list response with cursors to the neighbour pages
+type Page[T any] struct {
+	Items []T    `json:"items"`
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	Total *int   `json:"total,omitempty"`
+	// Fields limits item keys in response, empty means all keys
+	Fields []string `json:"-"`
+	// Bare encodes page as plain item array as lists were before cursors, cursors are left to Link header.
+	// User and post lists are bare unless requested with paging=cursor, other lists always get the envelope
+	Bare bool `json:"-"`
+}
+
+// MarshalJSON encodes page keeping only requested item fields
+func (p Page[T]) MarshalJSON() ([]byte, error) {
+	items := make([]any, len(p.Items))
+	for i := range p.Items {
+		if len(p.Fields) == 0 {
+			items[i] = p.Items[i]
+			continue
+		}
+
+		item, err := Project(p.Items[i], p.Fields)
+		if err != nil {
+			return nil, err
+		}
+
+		items[i] = item
+	}
+
+	if p.Bare {
+		return json.Marshal(items)
+	}
+
+	return json.Marshal(struct {
+		Items []any  `json:"items"`
+		Next  string `json:"next,omitempty"`
+		Prev  string `json:"prev,omitempty"`
+		Total *int   `json:"total,omitempty"`
+	}{
+		Items: items,
+		Next:  p.Next,
+		Prev:  p.Prev,
+		Total: p.Total,
+	})
+}
+
+// Project encodes v as JSON object with given keys only
+func Project(v any, keys []string) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+
+	projected := make(map[string]json.RawMessage, len(keys))
+	for _, key := range keys {
+		if value, ok := all[key]; ok {
+			projected[key] = value
+		}
+	}
+
+	return projected, nil
+}
@@ -0,0 +1,53 @@
+package models_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trad3r/hskills/apirest/internal/models"
+)
+
+func TestPageMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		fields   []string
+		bare     bool
+		expected string
+	}{
+		{
+			name:     "All fields",
+			fields:   nil,
+			expected: `{"items":[{"id":1,"subject":"post","body":"body","created_at":null,"updated_at":null,"comment_count":0}],"next":"abc"}`,
+		},
+		{
+			name:     "Sparse fields",
+			fields:   []string{"id", "subject", "author"},
+			expected: `{"items":[{"id":1,"subject":"post"}],"next":"abc"}`,
+		},
+		{
+			name:     "Bare",
+			fields:   []string{"id", "subject"},
+			bare:     true,
+			expected: `[{"id":1,"subject":"post"}]`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			page := models.Page[models.Post]{
+				Items:  []models.Post{{ID: 1, Subject: "post", Body: "body"}},
+				Next:   "abc",
+				Fields: tc.fields,
+				Bare:   tc.bare,
+			}
+
+			data, err := json.Marshal(page)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expected, string(data))
+		})
+	}
+}
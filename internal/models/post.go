@@ -2,11 +2,40 @@ package models
 
 import "time"
 
+const (
+	PostStatusDraft     = "draft"
+	PostStatusScheduled = "scheduled"
+	PostStatusPublished = "published"
+	PostStatusArchived  = "archived"
+)
+
+// PostStatuses are lifecycle states of post, only published posts are listed publicly
+var PostStatuses = []string{PostStatusDraft, PostStatusScheduled, PostStatusPublished, PostStatusArchived}
+
 type Post struct {
 	ID        int        `json:"id"`
 	Subject   string     `json:"subject"`
+	Slug      string     `json:"slug,omitempty"`
 	Body      string     `json:"body"`
+	BodyHTML  string     `json:"body_html,omitempty"`
 	CreatedAt *time.Time `json:"created_at"`
 	UpdatedAt *time.Time `json:"updated_at"`
-	Author    User       `json:"author"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	Tags      []string   `json:"tags,omitempty"`
+	Status    string     `json:"status,omitempty"`
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+	// CommentCount includes replies
+	CommentCount int `json:"comment_count"`
+	// Reactions are counts by reaction type, types without reactions are omitted
+	Reactions map[string]int `json:"reactions,omitempty"`
+
+	Author  *User   `json:"author,omitempty"`
+	Rank    float64 `json:"rank,omitempty"`
+	Snippet string  `json:"snippet,omitempty"`
+	Version int     `json:"-"`
+}
+
+// PostPreview is rendered body of unsaved post
+type PostPreview struct {
+	BodyHTML string `json:"body_html"`
 }
@@ -0,0 +1,18 @@
+package models
+
+const (
+	ReactionLike  = "like"
+	ReactionLove  = "love"
+	ReactionLaugh = "laugh"
+	ReactionWow   = "wow"
+	ReactionSad   = "sad"
+)
+
+// ReactionTypes are reactions users may put on posts
+var ReactionTypes = []string{ReactionLike, ReactionLove, ReactionLaugh, ReactionWow, ReactionSad}
+
+// PostReactions are reaction counts of post by reaction type
+type PostReactions struct {
+	PostID    int            `json:"post_id"`
+	Reactions map[string]int `json:"reactions"`
+}
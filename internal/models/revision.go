@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/trad3r/hskills/apirest/internal/textdiff"
+)
+
+// PostRevision is a saved state of post subject and body, revisions are numbered from 1 per post
+type PostRevision struct {
+	PostID    int       `json:"post_id"`
+	Revision  int       `json:"revision"`
+	Subject   string    `json:"subject"`
+	Body      string    `json:"body"`
+	Actor     *string   `json:"actor"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PostDiff is a line diff between two post revisions
+type PostDiff struct {
+	From    int             `json:"from"`
+	To      int             `json:"to"`
+	Subject []textdiff.Line `json:"subject"`
+	Body    []textdiff.Line `json:"body"`
+}
@@ -0,0 +1,7 @@
+package models
+
+// Tag is a post label with amount of live posts having it
+type Tag struct {
+	Name      string `json:"name"`
+	PostCount int    `json:"post_count"`
+}
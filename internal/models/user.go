@@ -2,11 +2,28 @@ package models
 
 import "time"
 
+const (
+	UserRoleAuthor    = "author"
+	UserRoleModerator = "moderator"
+	UserRoleAdmin     = "admin"
+)
+
+// UserRoles are roles of users, authors manage only their own posts and profile
+var UserRoles = []string{UserRoleAuthor, UserRoleModerator, UserRoleAdmin}
+
 type User struct {
 	ID          int        `json:"id"`
 	Name        string     `json:"name"`
 	Phonenumber string     `json:"phonenumber"`
+	Role        string     `json:"role,omitempty"`
 	CreatedAt   *time.Time `json:"created_at"`
 	UpdatedAt   *time.Time `json:"updated_at"`
-	PostCount   int
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	PostCount   int        `json:"post_count"`
+	Posts       []Post     `json:"posts,omitempty"`
+	Version     int        `json:"-"`
+	// Login is never shown, it is half of the credentials
+	Login string `json:"-"`
+	// PasswordHash is set only when user is added with credentials
+	PasswordHash string `json:"-"`
 }
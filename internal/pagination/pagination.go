@@ -0,0 +1,87 @@
+package pagination
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor points to the boundary row of a page
+type Cursor struct {
+	// Sort is the signature of the order the cursor was issued for
+	Sort string `json:"s"`
+	// Values are the sort key values of the boundary row, the last one is always row ID
+	Values []any `json:"v"`
+	// Backward is set for cursors pointing to the previous page
+	Backward bool `json:"b,omitempty"`
+}
+
+// Encode returns opaque cursor token
+func Encode(c Cursor) string {
+	b, err := json.Marshal(c)
+	if err != nil {
+		// values are scanned from database, so they are always marshalable
+		panic(fmt.Sprintf("failed to marshal cursor: %v", err))
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Decode parses cursor token produced by Encode
+func Decode(token string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&c); err != nil || len(c.Values) == 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	for i, v := range c.Values {
+		n, ok := v.(json.Number)
+		if !ok {
+			continue
+		}
+
+		if iv, err := n.Int64(); err == nil {
+			c.Values[i] = iv
+		} else if fv, err := n.Float64(); err == nil {
+			c.Values[i] = fv
+		} else {
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	return &c, nil
+}
+
+// LinkHeader builds RFC 8288 Link header value with next and prev relations.
+// Offset param is dropped from the links as cursors replace it
+func LinkHeader(u *url.URL, next string, prev string) string {
+	var links []string
+
+	for _, l := range []struct{ rel, cursor string }{{"next", next}, {"prev", prev}} {
+		if len(l.cursor) == 0 {
+			continue
+		}
+
+		q := u.Query()
+		q.Del("offset")
+		q.Set("cursor", l.cursor)
+
+		link := url.URL{Path: u.Path, RawQuery: q.Encode()}
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, link.String(), l.rel))
+	}
+
+	return strings.Join(links, ", ")
+}
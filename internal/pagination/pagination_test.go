@@ -0,0 +1,49 @@
+package pagination_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trad3r/hskills/apirest/internal/pagination"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	t.Parallel()
+
+	c := pagination.Cursor{
+		Sort:     "created_at:desc,id:desc",
+		Values:   []any{"2024-07-01T10:00:00.123456Z", 42, 0.5},
+		Backward: true,
+	}
+
+	decoded, err := pagination.Decode(pagination.Encode(c))
+	require.NoError(t, err)
+	assert.Equal(t, c.Sort, decoded.Sort)
+	assert.True(t, decoded.Backward)
+	assert.Equal(t, []any{"2024-07-01T10:00:00.123456Z", int64(42), 0.5}, decoded.Values)
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, token := range []string{"", "!!!", "bm90IGpzb24", "e30"} {
+		_, err := pagination.Decode(token)
+		assert.ErrorIs(t, err, pagination.ErrInvalidCursor, token)
+	}
+}
+
+func TestLinkHeader(t *testing.T) {
+	t.Parallel()
+
+	u, err := url.Parse("/posts?author=1&limit=2&offset=4")
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		`</posts?author=1&cursor=n&limit=2>; rel="next", </posts?author=1&cursor=p&limit=2>; rel="prev"`,
+		pagination.LinkHeader(u, "n", "p"),
+	)
+	assert.Equal(t, `</posts?author=1&cursor=n&limit=2>; rel="next"`, pagination.LinkHeader(u, "n", ""))
+	assert.Empty(t, pagination.LinkHeader(u, "", ""))
+}
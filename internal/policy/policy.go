@@ -0,0 +1,129 @@
+// Package policy decides who may change posts and users.
+// Authors change only their own posts and profile, moderators change any post, admins manage users
+package policy
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/trad3r/hskills/apirest/internal/auth"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/reqctx"
+)
+
+var (
+	postModerators = []string{models.UserRoleModerator, models.UserRoleAdmin}
+	userManagers   = []string{models.UserRoleAdmin}
+)
+
+// Actor is the caller a decision is made for, zero Actor is anonymous
+type Actor struct {
+	// UserID is 0 for API keys, they own nothing
+	UserID int
+	Role   string
+	APIKey bool
+}
+
+// ActorFrom returns caller of request: user of access token or API key.
+// Keys with admin scope act as admins, keys with posts:moderate scope as moderators, other keys have no role
+func ActorFrom(ctx context.Context) Actor {
+	if user := reqctx.User(ctx); user != nil {
+		return Actor{UserID: user.ID, Role: user.Role}
+	}
+
+	scopes := reqctx.Scopes(ctx)
+	if scopes == nil {
+		return Actor{}
+	}
+
+	switch {
+	case slices.Contains(scopes, auth.ScopeAdmin):
+		return Actor{Role: models.UserRoleAdmin, APIKey: true}
+	case slices.Contains(scopes, auth.ScopePostsModerate):
+		return Actor{Role: models.UserRoleModerator, APIKey: true}
+	}
+
+	return Actor{APIKey: true}
+}
+
+// CreatePost allows users to post as themselves, moderators and admins to post as any author
+func CreatePost(actor Actor, authorID int) error {
+	return decide(actor, authorID, postModerators, "only a moderator may post as another author")
+}
+
+// EditPost allows author of the post, moderators and admins
+func EditPost(actor Actor, authorID int) error {
+	return decide(actor, authorID, postModerators, "only the author or a moderator may edit the post")
+}
+
+// ViewPost allows author of the post, moderators and admins to see the post before publication
+func ViewPost(actor Actor, authorID int) error {
+	return decide(actor, authorID, postModerators, "only the author or a moderator may see unpublished posts")
+}
+
+// DeletePost allows author of the post, moderators and admins
+func DeletePost(actor Actor, authorID int) error {
+	return decide(actor, authorID, postModerators, "only the author or a moderator may delete the post")
+}
+
+// RestorePost allows author of the deleted post, moderators and admins
+func RestorePost(actor Actor, authorID int) error {
+	return decide(actor, authorID, postModerators, "only the author or a moderator may restore the post")
+}
+
+// EditComment allows author of the comment, moderators and admins
+func EditComment(actor Actor, authorID int) error {
+	return decide(actor, authorID, postModerators, "only the author or a moderator may edit the comment")
+}
+
+// DeleteComment allows author of the comment, moderators and admins
+func DeleteComment(actor Actor, authorID int) error {
+	return decide(actor, authorID, postModerators, "only the author or a moderator may delete the comment")
+}
+
+// EditUser allows the user and admins
+func EditUser(actor Actor, userID int) error {
+	return decide(actor, userID, userManagers, "only the user or an admin may edit the profile")
+}
+
+// ModeratePosts allows moderators and admins to change posts regardless of author, e.g. by import
+func ModeratePosts(actor Actor) error {
+	return decide(actor, 0, postModerators, "only a moderator may change posts of other authors")
+}
+
+// ManageUsers allows admins to delete, restore and import users
+func ManageUsers(actor Actor) error {
+	return decide(actor, 0, userManagers, "only an admin may manage users")
+}
+
+// SetRole allows admins to change roles of other users, own role can not be changed by anyone
+func SetRole(actor Actor, userID int) error {
+	if err := decide(actor, 0, userManagers, "only an admin may change roles"); err != nil {
+		return err
+	}
+
+	if actor.UserID == userID {
+		return fmt.Errorf("%w: own role can not be changed", custom_errors.ErrForbidden)
+	}
+
+	return nil
+}
+
+// decide allows owner of resource and actors having one of roles, owner 0 means resource is not owned
+func decide(actor Actor, owner int, roles []string, reason string) error {
+	if actor == (Actor{}) {
+		return custom_errors.ErrUnauthorized
+	}
+
+	if owner > 0 && actor.UserID == owner {
+		return nil
+	}
+
+	if slices.Contains(roles, actor.Role) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", custom_errors.ErrForbidden, reason)
+}
@@ -0,0 +1,134 @@
+package policy_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/trad3r/hskills/apirest/internal/auth"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/policy"
+	"github.com/trad3r/hskills/apirest/internal/reqctx"
+)
+
+var (
+	anonymous    = policy.Actor{}
+	author       = policy.Actor{UserID: 1, Role: models.UserRoleAuthor}
+	moderator    = policy.Actor{UserID: 2, Role: models.UserRoleModerator}
+	admin        = policy.Actor{UserID: 3, Role: models.UserRoleAdmin}
+	apiKey       = policy.Actor{APIKey: true}
+	moderatorKey = policy.Actor{Role: models.UserRoleModerator, APIKey: true}
+	adminKey     = policy.Actor{Role: models.UserRoleAdmin, APIKey: true}
+)
+
+func TestPolicy(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name   string
+		decide func() error
+		err    error
+	}{
+		{name: "author creates own post", decide: func() error { return policy.CreatePost(author, 1) }},
+		{name: "author creates post of other", decide: func() error { return policy.CreatePost(author, 2) }, err: custom_errors.ErrForbidden},
+		{name: "moderator creates post of other", decide: func() error { return policy.CreatePost(moderator, 1) }},
+		{name: "key creates post", decide: func() error { return policy.CreatePost(apiKey, 1) }, err: custom_errors.ErrForbidden},
+		{name: "moderator key creates post", decide: func() error { return policy.CreatePost(moderatorKey, 1) }},
+		{name: "anonymous creates post", decide: func() error { return policy.CreatePost(anonymous, 1) }, err: custom_errors.ErrUnauthorized},
+
+		{name: "author edits own post", decide: func() error { return policy.EditPost(author, 1) }},
+		{name: "author edits other post", decide: func() error { return policy.EditPost(author, 2) }, err: custom_errors.ErrForbidden},
+		{name: "moderator edits any post", decide: func() error { return policy.EditPost(moderator, 1) }},
+		{name: "admin edits any post", decide: func() error { return policy.EditPost(admin, 1) }},
+		{name: "key edits post", decide: func() error { return policy.EditPost(apiKey, 1) }, err: custom_errors.ErrForbidden},
+		{name: "moderator key edits any post", decide: func() error { return policy.EditPost(moderatorKey, 1) }},
+		{name: "anonymous edits post", decide: func() error { return policy.EditPost(anonymous, 1) }, err: custom_errors.ErrUnauthorized},
+
+		{name: "author views own draft", decide: func() error { return policy.ViewPost(author, 1) }},
+		{name: "author views other draft", decide: func() error { return policy.ViewPost(author, 2) }, err: custom_errors.ErrForbidden},
+		{name: "moderator views any draft", decide: func() error { return policy.ViewPost(moderator, 1) }},
+		{name: "anonymous views draft", decide: func() error { return policy.ViewPost(anonymous, 1) }, err: custom_errors.ErrUnauthorized},
+
+		{name: "author deletes own post", decide: func() error { return policy.DeletePost(author, 1) }},
+		{name: "author deletes other post", decide: func() error { return policy.DeletePost(author, 2) }, err: custom_errors.ErrForbidden},
+		{name: "moderator deletes any post", decide: func() error { return policy.DeletePost(moderator, 1) }},
+		{name: "anonymous deletes post", decide: func() error { return policy.DeletePost(anonymous, 1) }, err: custom_errors.ErrUnauthorized},
+
+		{name: "author restores own post", decide: func() error { return policy.RestorePost(author, 1) }},
+		{name: "author restores other post", decide: func() error { return policy.RestorePost(author, 2) }, err: custom_errors.ErrForbidden},
+		{name: "moderator restores any post", decide: func() error { return policy.RestorePost(moderator, 1) }},
+		{name: "key restores post", decide: func() error { return policy.RestorePost(apiKey, 1) }, err: custom_errors.ErrForbidden},
+
+		{name: "author edits own comment", decide: func() error { return policy.EditComment(author, 1) }},
+		{name: "author edits other comment", decide: func() error { return policy.EditComment(author, 2) }, err: custom_errors.ErrForbidden},
+		{name: "moderator edits any comment", decide: func() error { return policy.EditComment(moderator, 1) }},
+		{name: "key edits comment", decide: func() error { return policy.EditComment(apiKey, 1) }, err: custom_errors.ErrForbidden},
+		{name: "anonymous edits comment", decide: func() error { return policy.EditComment(anonymous, 1) }, err: custom_errors.ErrUnauthorized},
+
+		{name: "author deletes own comment", decide: func() error { return policy.DeleteComment(author, 1) }},
+		{name: "author deletes other comment", decide: func() error { return policy.DeleteComment(author, 2) }, err: custom_errors.ErrForbidden},
+		{name: "moderator deletes any comment", decide: func() error { return policy.DeleteComment(moderator, 1) }},
+		{name: "admin deletes any comment", decide: func() error { return policy.DeleteComment(admin, 1) }},
+		{name: "anonymous deletes comment", decide: func() error { return policy.DeleteComment(anonymous, 1) }, err: custom_errors.ErrUnauthorized},
+
+		{name: "author edits own profile", decide: func() error { return policy.EditUser(author, 1) }},
+		{name: "author edits other profile", decide: func() error { return policy.EditUser(author, 2) }, err: custom_errors.ErrForbidden},
+		{name: "moderator edits other profile", decide: func() error { return policy.EditUser(moderator, 1) }, err: custom_errors.ErrForbidden},
+		{name: "admin edits any profile", decide: func() error { return policy.EditUser(admin, 1) }},
+		{name: "key edits profile", decide: func() error { return policy.EditUser(apiKey, 1) }, err: custom_errors.ErrForbidden},
+		{name: "admin key edits profile", decide: func() error { return policy.EditUser(adminKey, 1) }},
+
+		{name: "author moderates posts", decide: func() error { return policy.ModeratePosts(author) }, err: custom_errors.ErrForbidden},
+		{name: "moderator moderates posts", decide: func() error { return policy.ModeratePosts(moderator) }},
+		{name: "key moderates posts", decide: func() error { return policy.ModeratePosts(apiKey) }, err: custom_errors.ErrForbidden},
+		{name: "moderator key moderates posts", decide: func() error { return policy.ModeratePosts(moderatorKey) }},
+
+		{name: "author manages users", decide: func() error { return policy.ManageUsers(author) }, err: custom_errors.ErrForbidden},
+		{name: "moderator manages users", decide: func() error { return policy.ManageUsers(moderator) }, err: custom_errors.ErrForbidden},
+		{name: "admin manages users", decide: func() error { return policy.ManageUsers(admin) }},
+		{name: "admin key manages users", decide: func() error { return policy.ManageUsers(adminKey) }},
+		{name: "anonymous manages users", decide: func() error { return policy.ManageUsers(anonymous) }, err: custom_errors.ErrUnauthorized},
+
+		{name: "admin sets role", decide: func() error { return policy.SetRole(admin, 1) }},
+		{name: "admin sets own role", decide: func() error { return policy.SetRole(admin, 3) }, err: custom_errors.ErrForbidden},
+		{name: "admin key sets role", decide: func() error { return policy.SetRole(adminKey, 1) }},
+		{name: "moderator key sets role", decide: func() error { return policy.SetRole(moderatorKey, 1) }, err: custom_errors.ErrForbidden},
+		{name: "moderator sets role", decide: func() error { return policy.SetRole(moderator, 1) }, err: custom_errors.ErrForbidden},
+		{name: "author sets own role", decide: func() error { return policy.SetRole(author, 1) }, err: custom_errors.ErrForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.ErrorIs(t, tc.decide(), tc.err)
+		})
+	}
+}
+
+func TestActorFrom(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	testCases := []struct {
+		name  string
+		ctx   context.Context
+		actor policy.Actor
+	}{
+		{name: "anonymous", ctx: ctx, actor: anonymous},
+		{name: "user", ctx: reqctx.WithScopes(reqctx.WithUser(ctx, &models.User{ID: 2, Role: models.UserRoleModerator}), auth.UserScopes), actor: moderator},
+		{name: "key", ctx: reqctx.WithScopes(ctx, []string{auth.ScopePostsWrite}), actor: apiKey},
+		{name: "moderator key", ctx: reqctx.WithScopes(ctx, []string{auth.ScopePostsWrite, auth.ScopePostsModerate}), actor: moderatorKey},
+		{name: "admin key", ctx: reqctx.WithScopes(ctx, []string{auth.ScopeAdmin}), actor: adminKey},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.actor, policy.ActorFrom(tc.ctx))
+		})
+	}
+}
@@ -0,0 +1,9 @@
+package filters
+
+import "time"
+
+type APIKeyAddRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
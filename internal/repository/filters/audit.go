@@ -0,0 +1,24 @@
+package filters
+
+import (
+	"time"
+
+	"github.com/trad3r/hskills/apirest/internal/pagination"
+)
+
+const (
+	AuditEntityUser = "user"
+	AuditEntityPost = "post"
+)
+
+// AuditFilter selects audit entries, zero values match everything. Entries are listed newest first
+type AuditFilter struct {
+	Limit    int
+	Cursor   *pagination.Cursor
+	Entity   string
+	EntityID int
+	Action   string
+	Actor    string
+	From     time.Time
+	To       time.Time
+}
@@ -0,0 +1,23 @@
+package filters
+
+import "github.com/trad3r/hskills/apirest/internal/pagination"
+
+// CommentFilter selects comments of post, top level ones when ParentID is nil or replies of the parent otherwise.
+// Comments are listed oldest first
+type CommentFilter struct {
+	PostID   int
+	ParentID *int
+	Limit    int
+	Cursor   *pagination.Cursor
+}
+
+type CommentAddRequest struct {
+	Body     string `json:"body"`
+	ParentID *int   `json:"parent_id"`
+}
+
+type CommentUpdateRequest struct {
+	Body string `json:"body"`
+	// IfVersions limits update to the listed versions, any version is updated when empty
+	IfVersions []int `json:"-"`
+}
@@ -0,0 +1,11 @@
+package filters
+
+// Deleted selects list rows by soft deletion
+type Deleted string
+
+const (
+	// DeletedExclude lists live rows only
+	DeletedExclude Deleted = ""
+	DeletedInclude Deleted = "include"
+	DeletedOnly    Deleted = "only"
+)
@@ -0,0 +1,45 @@
+package filters
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Field is an update request field which tells absent value from explicit null
+type Field[T any] struct {
+	// Set is true when field is present in request
+	Set bool
+	// Null is true when field is explicit null
+	Null  bool
+	Value T
+}
+
+// Value returns field set to v
+func Value[T any](v T) Field[T] {
+	return Field[T]{Set: true, Value: v}
+}
+
+// Null returns field set to null
+func Null[T any]() Field[T] {
+	return Field[T]{Set: true, Null: true}
+}
+
+// OrNull returns null field when f is unset
+func (f Field[T]) OrNull() Field[T] {
+	if !f.Set {
+		return Null[T]()
+	}
+
+	return f
+}
+
+// UnmarshalJSON is only called for present keys, so absent fields stay unset
+func (f *Field[T]) UnmarshalJSON(b []byte) error {
+	f.Set = true
+	if bytes.Equal(b, []byte("null")) {
+		f.Null = true
+		return nil
+	}
+
+	return json.Unmarshal(b, &f.Value)
+}
@@ -1,23 +1,77 @@
 package filters
 
-import "time"
+import (
+	"time"
+
+	"github.com/trad3r/hskills/apirest/internal/pagination"
+)
 
 type PostFilter struct {
 	Offset        int
 	Limit         int
+	Cursor        *pagination.Cursor
+	WithTotal     bool
 	FromCreatedAt time.Time
 	ToCreatedAt   time.Time
 	Subject       string
-	Authors       []int
+	// Query is a websearch query
+	Query   string
+	Authors []int
+	// Tags selects posts having any of tags, or all of them with TagsAll
+	Tags    []string
+	TagsAll bool
+	// Statuses limits posts to the lifecycle states, any state when empty
+	Statuses   []string
+	Sort       []SortField
+	Deleted    Deleted
+	Projection Projection
 }
 
+// PostAddRequest is a new post, it is published at once unless status or publish_at is set
 type PostAddRequest struct {
-	Subject string `json:"subject"`
-	Body    string `json:"body"`
-	Author  int    `json:"author"`
+	Subject   string     `json:"subject"`
+	Body      string     `json:"body"`
+	Tags      []string   `json:"tags"`
+	Author    int        `json:"author"`
+	Status    string     `json:"status"`
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+// PostImportItem is an imported post, post with the same external ID is updated.
+// Author is referenced either by ID or by external ID
+type PostImportItem struct {
+	ExternalID       string `json:"external_id"`
+	Subject          string `json:"subject"`
+	Body             string `json:"body"`
+	Author           int    `json:"author"`
+	AuthorExternalID string `json:"author_external_id"`
+}
+
+type PostBulkUpdateItem struct {
+	ID int `json:"id"`
+	PostUpdateRequest
+}
+
+// PostReplaceRequest is PUT request body, absent body is cleared
+type PostReplaceRequest struct {
+	Subject string   `json:"subject"`
+	Body    *string  `json:"body"`
+	Tags    []string `json:"tags"`
+}
+
+// PostTransition moves post from one of From states into To state with PublishAt, nil PublishAt clears it
+type PostTransition struct {
+	From      []string
+	To        string
+	PublishAt *time.Time
+	// IfVersions limits transition to the listed versions, any version is changed when empty
+	IfVersions []int
 }
 
 type PostUpdateRequest struct {
-	Subject string `json:"subject,omitempty"`
-	Body    string `json:"body,omitempty"`
+	Subject Field[string]   `json:"subject"`
+	Body    Field[string]   `json:"body"`
+	Tags    Field[[]string] `json:"tags"`
+	// IfVersions limits update to the listed versions, any version is updated when empty
+	IfVersions []int `json:"-"`
 }
@@ -0,0 +1,31 @@
+package filters
+
+import "slices"
+
+// Projection is a set of response fields and embedded relations requested by client.
+// Empty Fields means all fields
+type Projection struct {
+	Fields  []string
+	Include []string
+}
+
+// HasField reports whether field is requested
+func (p Projection) HasField(name string) bool {
+	return len(p.Fields) == 0 || slices.Contains(p.Fields, name)
+}
+
+// Includes reports whether relation is requested
+func (p Projection) Includes(name string) bool {
+	return slices.Contains(p.Include, name)
+}
+
+// Keys returns response keys to keep, nil means all keys
+func (p Projection) Keys() []string {
+	if len(p.Fields) == 0 {
+		return nil
+	}
+
+	keys := append([]string{"id"}, p.Fields...)
+
+	return append(keys, p.Include...)
+}
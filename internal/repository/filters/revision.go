@@ -0,0 +1,9 @@
+package filters
+
+import "github.com/trad3r/hskills/apirest/internal/pagination"
+
+// RevisionFilter selects a page of post revisions, newest first
+type RevisionFilter struct {
+	Limit  int
+	Cursor *pagination.Cursor
+}
@@ -0,0 +1,7 @@
+package filters
+
+// SortField is one key of list order
+type SortField struct {
+	Field string
+	Desc  bool
+}
@@ -0,0 +1,7 @@
+package filters
+
+// TagFilter selects most used tags, optionally starting with prefix
+type TagFilter struct {
+	Limit  int
+	Prefix string
+}
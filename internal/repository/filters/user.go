@@ -1,22 +1,56 @@
 package filters
 
-import "time"
+import (
+	"time"
+
+	"github.com/trad3r/hskills/apirest/internal/pagination"
+)
 
 type UserFilter struct {
-	Offset         uint
-	Limit          uint
-	FromCreatedAt  *time.Time
-	ToCreatedAt    *time.Time
-	Name           []string
+	Offset        uint
+	Limit         uint
+	Cursor        *pagination.Cursor
+	WithTotal     bool
+	FromCreatedAt *time.Time
+	ToCreatedAt   *time.Time
+	Name          []string
+	// TopPostsAmount orders by post count when Sort is empty
 	TopPostsAmount string
+	Sort           []SortField
+	Deleted        Deleted
+	Projection     Projection
+	// PostsLimit is amount of latest posts embedded into every user
+	PostsLimit int
 }
 
 type UserAddRequest struct {
 	Name        string `json:"name"`
 	Phonenumber string `json:"phonenumber"`
+	// Login and Password are optional, user without them can not log in
+	Login    string `json:"login"`
+	Password string `json:"password"`
+}
+
+// UserRoleRequest sets role of user, see models.UserRoles
+type UserRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// UserImportItem is an imported user, user with the same external ID is updated
+type UserImportItem struct {
+	ExternalID  string `json:"external_id"`
+	Name        string `json:"name"`
+	Phonenumber string `json:"phonenumber"`
+}
+
+type UserBulkUpdateItem struct {
+	ID int `json:"id"`
+	UserUpdateRequest
 }
 
 type UserUpdateRequest struct {
-	Name        string `json:"name,omitempty"`
-	Phonenumber string `json:"phonenumber,omitempty"`
+	Name        Field[string] `json:"name"`
+	Phonenumber Field[string] `json:"phonenumber"`
+	// IfVersions limits update to the listed versions, any version is updated when empty
+	IfVersions []int `json:"-"`
 }
@@ -0,0 +1,219 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/models"
+)
+
+type IAPIKeyRepository interface {
+	Add(ctx context.Context, key *models.APIKey, keyHash string) error
+	GetList(ctx context.Context) ([]models.APIKey, error)
+	FindByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	Touch(ctx context.Context, id int) error
+	Revoke(ctx context.Context, id int) error
+	Rotate(ctx context.Context, id int, prefix string, keyHash string) (*models.APIKey, error)
+}
+
+type APIKeyRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAPIKeyRepository(db *pgxpool.Pool) IAPIKeyRepository {
+	return APIKeyRepository{
+		db: db,
+	}
+}
+
+var apiKeyColumns = []any{"id", "name", "prefix", "scopes", "created_by", "created_at", "expires_at", "rotated_at", "last_used_at", "revoked_at"}
+
+func scanAPIKey(row pgx.Row) (models.APIKey, error) {
+	var k models.APIKey
+	err := row.Scan(&k.ID, &k.Name, &k.Prefix, &k.Scopes, &k.CreatedBy, &k.CreatedAt, &k.ExpiresAt, &k.RotatedAt, &k.LastUsedAt, &k.RevokedAt)
+
+	return k, err
+}
+
+// activeAPIKey matches keys which are neither revoked nor expired
+func activeAPIKey() exp.Expression {
+	return goqu.And(
+		goqu.C("revoked_at").IsNull(),
+		goqu.Or(goqu.C("expires_at").IsNull(), goqu.C("expires_at").Gt(goqu.L("now()"))),
+	)
+}
+
+// Add stores new key by its hash
+func (s APIKeyRepository) Add(ctx context.Context, key *models.APIKey, keyHash string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	sql, args, err := goqu.Insert("api_key").
+		Rows(goqu.Record{
+			"name":       key.Name,
+			"prefix":     key.Prefix,
+			"key_hash":   keyHash,
+			"scopes":     varcharArray(key.Scopes),
+			"created_by": key.CreatedBy,
+			"expires_at": key.ExpiresAt,
+		}).
+		Returning("created_at").
+		ToSQL()
+	if err != nil {
+		return fmt.Errorf("error while preparing add API key: %w", err)
+	}
+
+	if err := s.db.QueryRow(ctx, sql, args...).Scan(&key.CreatedAt); err != nil {
+		return fmt.Errorf("error while adding API key: %w", mapPgError(err))
+	}
+
+	return nil
+}
+
+// GetList returns all keys, revoked included, newest first
+func (s APIKeyRepository) GetList(ctx context.Context) ([]models.APIKey, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	sql, args, err := goqu.From("api_key").Select(apiKeyColumns...).Order(goqu.C("id").Desc()).ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("error while preparing list API keys: %w", err)
+	}
+
+	rows, err := s.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying API keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make([]models.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning API key: %w", err)
+		}
+
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error while reading API keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// FindByHash returns active key by hash, nil if there is no such key
+func (s APIKeyRepository) FindByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	sql, args, err := goqu.From("api_key").
+		Select(apiKeyColumns...).
+		Where(goqu.Ex{"key_hash": keyHash}, activeAPIKey()).
+		ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("error while preparing find API key: %w", err)
+	}
+
+	key, err := scanAPIKey(s.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error while find API key: %w", err)
+	}
+
+	return &key, nil
+}
+
+// Touch sets last use time of key to now
+func (s APIKeyRepository) Touch(ctx context.Context, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	sql, args, err := goqu.Update("api_key").Set(goqu.Record{"last_used_at": goqu.L("now()")}).Where(goqu.Ex{"id": id}).ToSQL()
+	if err != nil {
+		return fmt.Errorf("error while preparing touch API key: %w", err)
+	}
+
+	if _, err := s.db.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("error while touching API key %d: %w", id, err)
+	}
+
+	return nil
+}
+
+// Revoke disables key for good, revoking revoked key is not an error
+func (s APIKeyRepository) Revoke(ctx context.Context, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	sql, args, err := goqu.Update("api_key").
+		Set(goqu.Record{"revoked_at": goqu.L("COALESCE(revoked_at, now())")}).
+		Where(goqu.Ex{"id": id}).
+		ToSQL()
+	if err != nil {
+		return fmt.Errorf("error while preparing revoke API key: %w", err)
+	}
+
+	tag, err := s.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("error while revoking API key %d: %w", id, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return custom_errors.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// Rotate replaces secret of active key, the old secret stops working at once
+func (s APIKeyRepository) Rotate(ctx context.Context, id int, prefix string, keyHash string) (*models.APIKey, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	sql, args, err := goqu.Update("api_key").
+		Set(goqu.Record{"prefix": prefix, "key_hash": keyHash, "rotated_at": goqu.L("now()")}).
+		Where(goqu.Ex{"id": id}, activeAPIKey()).
+		Returning(apiKeyColumns...).
+		ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("error while preparing rotate API key: %w", err)
+	}
+
+	key, err := scanAPIKey(s.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, custom_errors.ErrAPIKeyNotFound
+		}
+
+		return nil, fmt.Errorf("error while rotating API key %d: %w", id, mapPgError(err))
+	}
+
+	return &key, nil
+}
+
+// varcharArray is a VARCHAR array literal of values
+func varcharArray(values []string) exp.LiteralExpression {
+	if len(values) == 0 {
+		return goqu.L("'{}'::VARCHAR[]")
+	}
+
+	args := make([]any, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+
+	return goqu.L("ARRAY["+strings.Repeat("?, ", len(values)-1)+"?]::VARCHAR[]", args...)
+}
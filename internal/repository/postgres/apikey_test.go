@@ -0,0 +1,78 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/repository/postgres"
+	"github.com/trad3r/hskills/apirest/internal/testutils"
+)
+
+func TestAPIKeys(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	repo := getAPIKeyRepo(t)
+
+	createdBy := 1
+	key := &models.APIKey{Name: "ci", Prefix: "ak_00000001", Scopes: []string{"posts:read", "posts:write"}, CreatedBy: &createdBy}
+	require.NoError(t, repo.Add(ctx, key, "hash-1"))
+	assert.NotNil(t, key.CreatedAt)
+
+	expiredAt := time.Now().Add(-time.Hour)
+	expired := &models.APIKey{Name: "old", Prefix: "ak_00000002", Scopes: []string{"admin"}, ExpiresAt: &expiredAt}
+	require.NoError(t, repo.Add(ctx, expired, "hash-2"))
+
+	assert.ErrorIs(t, repo.Add(ctx, &models.APIKey{Name: "dup", Prefix: "ak_00000003", Scopes: []string{"admin"}}, "hash-1"), custom_errors.ErrConflict)
+
+	found, err := repo.FindByHash(ctx, "hash-1")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, []string{"posts:read", "posts:write"}, found.Scopes)
+	assert.Nil(t, found.LastUsedAt)
+
+	found, err = repo.FindByHash(ctx, "hash-2")
+	require.NoError(t, err)
+	assert.Nil(t, found, "expired key is not found")
+
+	require.NoError(t, repo.Touch(ctx, key.ID))
+
+	rotated, err := repo.Rotate(ctx, key.ID, "ak_00000004", "hash-4")
+	require.NoError(t, err)
+	assert.Equal(t, "ak_00000004", rotated.Prefix)
+	assert.NotNil(t, rotated.LastUsedAt)
+	assert.NotNil(t, rotated.RotatedAt)
+
+	found, err = repo.FindByHash(ctx, "hash-1")
+	require.NoError(t, err)
+	assert.Nil(t, found, "old secret stops working")
+
+	require.NoError(t, repo.Revoke(ctx, key.ID))
+	require.NoError(t, repo.Revoke(ctx, key.ID))
+	assert.ErrorIs(t, repo.Revoke(ctx, 1000), custom_errors.ErrAPIKeyNotFound)
+
+	_, err = repo.Rotate(ctx, key.ID, "ak_00000005", "hash-5")
+	assert.ErrorIs(t, err, custom_errors.ErrAPIKeyNotFound, "revoked key is not rotated")
+
+	found, err = repo.FindByHash(ctx, "hash-4")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	keys, err := repo.GetList(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, expired.ID, keys[0].ID)
+	assert.NotNil(t, keys[1].RevokedAt)
+}
+
+func getAPIKeyRepo(t *testing.T) postgres.IAPIKeyRepository {
+	db := testutils.PrepareDB(t)
+
+	return postgres.NewAPIKeyRepository(db)
+}
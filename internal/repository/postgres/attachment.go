@@ -0,0 +1,195 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/hashicorp/go-multierror"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/models"
+)
+
+type IAttachmentRepository interface {
+	Add(ctx context.Context, attachment *models.Attachment) error
+	GetList(ctx context.Context, postID int) ([]models.Attachment, error)
+	FindById(ctx context.Context, postID int, id int) (*models.Attachment, error)
+	Delete(ctx context.Context, postID int, id int) error
+	Orphans(ctx context.Context, limit int) ([]string, error)
+	ForgetOrphans(ctx context.Context, keys []string) error
+}
+
+type AttachmentRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAttachmentRepository(db *pgxpool.Pool) IAttachmentRepository {
+	return AttachmentRepository{
+		db: db,
+	}
+}
+
+func attachmentSelect() *goqu.SelectDataset {
+	return goqu.From("attachment").Select("id", "post_id", "storage_key", "filename", "content_type", "size", "checksum", "created_at")
+}
+
+func scanAttachment(row pgx.Row) (models.Attachment, error) {
+	var a models.Attachment
+	err := row.Scan(&a.ID, &a.PostID, &a.StorageKey, &a.Filename, &a.ContentType, &a.Size, &a.Checksum, &a.CreatedAt)
+
+	return a, err
+}
+
+// Add records stored attachment
+func (s AttachmentRepository) Add(ctx context.Context, attachment *models.Attachment) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	sql, args, err := goqu.Insert("attachment").
+		Rows(goqu.Record{
+			"post_id":      attachment.PostID,
+			"storage_key":  attachment.StorageKey,
+			"filename":     attachment.Filename,
+			"content_type": attachment.ContentType,
+			"size":         attachment.Size,
+			"checksum":     attachment.Checksum,
+		}).
+		Returning("id", "created_at").
+		ToSQL()
+	if err != nil {
+		return fmt.Errorf("error while creating sql: %w", err)
+	}
+
+	if err := s.db.QueryRow(ctx, sql, args...).Scan(&attachment.ID, &attachment.CreatedAt); err != nil {
+		return fmt.Errorf("error while inserting attachment: %w", mapPgError(err))
+	}
+
+	return nil
+}
+
+// GetList returns attachments of post in upload order
+func (s AttachmentRepository) GetList(ctx context.Context, postID int) ([]models.Attachment, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	sql, args, err := attachmentSelect().Where(goqu.Ex{"post_id": postID}).Order(goqu.C("id").Asc()).ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("error while creating sql: %w", err)
+	}
+
+	rows, err := s.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying attachments: %w", err)
+	}
+	defer rows.Close()
+
+	var errs error
+	attachments := make([]models.Attachment, 0)
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			errs = multierror.Append(errs, err)
+			continue
+		}
+
+		attachments = append(attachments, attachment)
+	}
+
+	if err := rows.Err(); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+
+	if errs != nil {
+		return nil, errs
+	}
+
+	return attachments, nil
+}
+
+// FindById returns attachment of post, nil if it is not found
+func (s AttachmentRepository) FindById(ctx context.Context, postID int, id int) (*models.Attachment, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	sql, args, err := attachmentSelect().Where(goqu.Ex{"id": id, "post_id": postID}).ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("error while preparing find attachment by ID %d: %w", id, err)
+	}
+
+	attachment, err := scanAttachment(s.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error while find attachment by ID %d: %w", id, err)
+	}
+
+	return &attachment, nil
+}
+
+// Delete removes attachment record, its object is queued for the cleanup job by trigger
+func (s AttachmentRepository) Delete(ctx context.Context, postID int, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	sql, args, err := goqu.Delete("attachment").Where(goqu.Ex{"id": id, "post_id": postID}).ToSQL()
+	if err != nil {
+		return fmt.Errorf("error while preparing delete attachment: %w", err)
+	}
+
+	tag, err := s.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("error while deleting attachment: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return custom_errors.ErrAttachmentNotFound
+	}
+
+	return nil
+}
+
+// Orphans returns storage keys of removed attachments, oldest first
+func (s AttachmentRepository) Orphans(ctx context.Context, limit int) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	sql, args, err := goqu.From("attachment_orphan").Select("storage_key").Order(goqu.C("created_at").Asc()).Limit(uint(limit)).ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("error while creating sql: %w", err)
+	}
+
+	rows, err := s.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying attachment orphans: %w", err)
+	}
+
+	keys, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("error while scanning attachment orphans: %w", err)
+	}
+
+	return keys, nil
+}
+
+// ForgetOrphans removes keys whose objects are deleted from the cleanup queue
+func (s AttachmentRepository) ForgetOrphans(ctx context.Context, keys []string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	sql, args, err := goqu.Delete("attachment_orphan").Where(goqu.Ex{"storage_key": keys}).ToSQL()
+	if err != nil {
+		return fmt.Errorf("error while preparing forget attachment orphans: %w", err)
+	}
+
+	if _, err := s.db.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("error while forgetting attachment orphans: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-faker/faker/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/repository/postgres"
+	"github.com/trad3r/hskills/apirest/internal/testutils"
+)
+
+func TestAttachmentOrphans(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	postRepo, attachmentRepo := getAttachmentRepos(t)
+
+	post := &models.Post{Subject: faker.Sentence(), Author: &models.User{ID: 1}}
+	require.NoError(t, postRepo.Add(ctx, post))
+
+	keys := []string{"posts/a", "posts/b"}
+	for _, key := range keys {
+		attachment := &models.Attachment{PostID: post.ID, StorageKey: key, Filename: "a.txt", ContentType: "text/plain", Size: 1, Checksum: faker.Password()}
+		require.NoError(t, attachmentRepo.Add(ctx, attachment))
+	}
+
+	attachments, err := attachmentRepo.GetList(ctx, post.ID)
+	require.NoError(t, err)
+	require.Len(t, attachments, 2)
+
+	require.NoError(t, attachmentRepo.Delete(ctx, post.ID, attachments[0].ID))
+	assert.ErrorIs(t, attachmentRepo.Delete(ctx, post.ID, attachments[0].ID), custom_errors.ErrAttachmentNotFound)
+
+	orphans, err := attachmentRepo.Orphans(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, keys[:1], orphans)
+
+	// purged post takes remaining attachments with it
+	require.NoError(t, postRepo.Delete(ctx, post.ID))
+	_, err = postRepo.Purge(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+
+	orphans, err = attachmentRepo.Orphans(ctx, 10)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, keys, orphans)
+
+	require.NoError(t, attachmentRepo.ForgetOrphans(ctx, keys))
+
+	orphans, err = attachmentRepo.Orphans(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, orphans)
+}
+
+func getAttachmentRepos(t *testing.T) (postgres.IPostRepository, postgres.IAttachmentRepository) {
+	db := testutils.PrepareDB(t)
+
+	return postgres.NewPostRepository(db), postgres.NewAttachmentRepository(db)
+}
@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/hashicorp/go-multierror"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/repository/filters"
+	"github.com/trad3r/hskills/apirest/internal/reqctx"
+)
+
+// beginAudited starts transaction passing actor and request ID of ctx to audit triggers
+func beginAudited(ctx context.Context, db *pgxpool.Pool) (pgx.Tx, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error while starting transaction: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, "SELECT set_config('audit.actor', $1, true), set_config('audit.request_id', $2, true)",
+		reqctx.Actor(ctx), reqctx.RequestID(ctx)); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, fmt.Errorf("error while setting audit context: %w", err)
+	}
+
+	return tx, nil
+}
+
+// audited runs fn in audited transaction, the transaction is committed when fn succeeds
+func audited(ctx context.Context, db *pgxpool.Pool, fn func(tx pgx.Tx) error) error {
+	tx, err := beginAudited(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error while committing transaction: %w", mapPgError(err))
+	}
+
+	return nil
+}
+
+type IAuditRepository interface {
+	GetList(ctx context.Context, filter filters.AuditFilter) (*models.Page[models.AuditEntry], error)
+}
+
+type AuditRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAuditRepository(db *pgxpool.Pool) IAuditRepository {
+	return AuditRepository{
+		db: db,
+	}
+}
+
+var auditSortColumns = map[string]sortColumn[models.AuditEntry]{
+	"id": {expr: goqu.I("id"), value: func(e models.AuditEntry) any { return e.ID }},
+}
+
+// GetList returns audit entries page, newest first
+func (s AuditRepository) GetList(ctx context.Context, filter filters.AuditFilter) (*models.Page[models.AuditEntry], error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	keys, cursorValues, err := sortKeys(auditSortColumns, []filters.SortField{{Field: "id", Desc: true}})
+	if err != nil {
+		return nil, err
+	}
+
+	wheres := auditWheres(filter)
+	if filter.Cursor != nil {
+		if err := checkCursor(keys, filter.Cursor); err != nil {
+			return nil, err
+		}
+
+		wheres = append(wheres, keysetCondition(keys, filter.Cursor))
+	}
+
+	ds := goqu.From("audit_log").
+		Select("id", "entity", "entity_id", "action", "actor", "request_id", "before", "after", "created_at").
+		Order(orderBy(keys, filter.Cursor != nil && filter.Cursor.Backward)...).
+		Limit(uint(filter.Limit + 1))
+
+	if len(wheres) > 0 {
+		ds = ds.Where(wheres...)
+	}
+
+	sql, args, err := ds.ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("error while creating sql: %w", err)
+	}
+
+	rows, err := s.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var errs error
+	entries := make([]models.AuditEntry, 0, filter.Limit+1)
+	for rows.Next() {
+		var e models.AuditEntry
+		if err := rows.Scan(&e.ID, &e.Entity, &e.EntityID, &e.Action, &e.Actor, &e.RequestID, &e.Before, &e.After, &e.CreatedAt); err != nil {
+			errs = multierror.Append(errs, err)
+			continue
+		}
+
+		entries = append(entries, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+
+	if errs != nil {
+		return nil, errs
+	}
+
+	return newPage(entries, filter.Limit, 0, keys, filter.Cursor, cursorValues), nil
+}
+
+func auditWheres(filter filters.AuditFilter) []goqu.Expression {
+	var wheres []goqu.Expression
+
+	if len(filter.Entity) > 0 {
+		wheres = append(wheres, goqu.C("entity").Eq(filter.Entity))
+	}
+
+	if filter.EntityID > 0 {
+		wheres = append(wheres, goqu.C("entity_id").Eq(filter.EntityID))
+	}
+
+	if len(filter.Action) > 0 {
+		wheres = append(wheres, goqu.C("action").Eq(filter.Action))
+	}
+
+	if len(filter.Actor) > 0 {
+		wheres = append(wheres, goqu.C("actor").Eq(filter.Actor))
+	}
+
+	if !filter.From.IsZero() {
+		wheres = append(wheres, goqu.C("created_at").Gte(filter.From))
+	}
+
+	if !filter.To.IsZero() {
+		wheres = append(wheres, goqu.C("created_at").Lt(filter.To))
+	}
+
+	return wheres
+}
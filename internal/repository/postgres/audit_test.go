@@ -0,0 +1,91 @@
+package postgres_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/repository/filters"
+	"github.com/trad3r/hskills/apirest/internal/repository/postgres"
+	"github.com/trad3r/hskills/apirest/internal/reqctx"
+	"github.com/trad3r/hskills/apirest/internal/testutils"
+)
+
+func TestAuditUserChanges(t *testing.T) {
+	t.Parallel()
+
+	ctx := reqctx.WithRequestID(reqctx.WithActor(context.Background(), "admin"), "req-1")
+
+	userRepo, auditRepo := getAuditRepos(t)
+
+	_, err := userRepo.Update(ctx, 1, filters.UserUpdateRequest{Name: filters.Value("Jack")})
+	require.NoError(t, err)
+
+	err = userRepo.Delete(ctx, 1)
+	require.NoError(t, err)
+
+	page, err := auditRepo.GetList(ctx, filters.AuditFilter{Limit: 10, Entity: filters.AuditEntityUser, EntityID: 1})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 2)
+
+	deleted, updated := page.Items[0], page.Items[1]
+	assert.Equal(t, "delete", deleted.Action)
+	assert.Equal(t, "update", updated.Action)
+	require.NotNil(t, updated.Actor)
+	assert.Equal(t, "admin", *updated.Actor)
+	require.NotNil(t, updated.RequestID)
+	assert.Equal(t, "req-1", *updated.RequestID)
+
+	var before, after struct {
+		Name string `json:"name"`
+	}
+	require.NoError(t, json.Unmarshal(updated.Before, &before))
+	require.NoError(t, json.Unmarshal(updated.After, &after))
+	assert.Equal(t, "John", before.Name)
+	assert.Equal(t, "Jack", after.Name)
+
+	page, err = auditRepo.GetList(ctx, filters.AuditFilter{Limit: 10, Entity: filters.AuditEntityPost, Action: "delete"})
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 2)
+}
+
+func getAuditRepos(t *testing.T) (postgres.IUserRepository, postgres.IAuditRepository) {
+	db := testutils.PrepareDB(t)
+
+	return postgres.NewUserRepository(db), postgres.NewAuditRepository(db)
+}
+
+func TestAuditHidesCredentials(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	userRepo, auditRepo := getAuditRepos(t)
+
+	user := &models.User{Name: "Login User", Phonenumber: "+70000000003", Login: "audited.login", PasswordHash: "$argon2id$v=19$m=65536,t=1,p=4$c2FsdA$aGFzaA"}
+	require.NoError(t, userRepo.Add(ctx, user))
+
+	_, err := userRepo.Update(ctx, user.ID, filters.UserUpdateRequest{Name: filters.Value("Renamed User")})
+	require.NoError(t, err)
+
+	page, err := auditRepo.GetList(ctx, filters.AuditFilter{Limit: 10, Entity: filters.AuditEntityUser, EntityID: user.ID})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 2)
+
+	for _, entry := range page.Items {
+		for _, row := range []json.RawMessage{entry.Before, entry.After} {
+			if len(row) == 0 || string(row) == "null" {
+				continue
+			}
+
+			var fields map[string]any
+			require.NoError(t, json.Unmarshal(row, &fields))
+			assert.Contains(t, fields, "name")
+			assert.NotContains(t, fields, "login")
+			assert.NotContains(t, fields, "password_hash")
+		}
+	}
+}
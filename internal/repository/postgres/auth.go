@@ -0,0 +1,292 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/models"
+)
+
+type IAuthRepository interface {
+	FindCredentials(ctx context.Context, login string) (*models.Credentials, error)
+	FindCredentialsById(ctx context.Context, userID int) (*models.Credentials, error)
+	SetPassword(ctx context.Context, userID int, passwordHash string) error
+	AddSession(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) (*models.Session, error)
+	Rotate(ctx context.Context, tokenHash string, newTokenHash string, expiresAt time.Time) (*models.Session, error)
+	RevokeSession(ctx context.Context, tokenHash string) error
+	SessionUser(ctx context.Context, sessionID int) (*models.User, error)
+}
+
+type AuthRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAuthRepository(db *pgxpool.Pool) IAuthRepository {
+	return AuthRepository{
+		db: db,
+	}
+}
+
+// FindCredentials returns credentials of live user by login, nil if there is no such user
+func (s AuthRepository) FindCredentials(ctx context.Context, login string) (*models.Credentials, error) {
+	return s.findCredentials(ctx, goqu.Ex{"login": login})
+}
+
+// FindCredentialsById returns credentials of live user, nil if user has no credentials
+func (s AuthRepository) FindCredentialsById(ctx context.Context, userID int) (*models.Credentials, error) {
+	return s.findCredentials(ctx, goqu.Ex{"id": userID, "login": goqu.Op{"isNot": nil}})
+}
+
+func (s AuthRepository) findCredentials(ctx context.Context, where exp.Ex) (*models.Credentials, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	sql, args, err := goqu.From("author").
+		Select("id", "login", "password_hash").
+		Where(where, goqu.Ex{"deleted_at": nil}).
+		ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("error while preparing find credentials: %w", err)
+	}
+
+	var c models.Credentials
+	if err := s.db.QueryRow(ctx, sql, args...).Scan(&c.UserID, &c.Login, &c.PasswordHash); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error while find credentials: %w", err)
+	}
+
+	return &c, nil
+}
+
+// SetPassword replaces password hash of user with credentials and revokes all user sessions
+func (s AuthRepository) SetPassword(ctx context.Context, userID int, passwordHash string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	sql, args, err := goqu.Update("author").
+		Set(goqu.Record{"password_hash": passwordHash, "updated_at": time.Now(), "version": goqu.L("version + 1")}).
+		Where(goqu.Ex{"id": userID, "deleted_at": nil, "login": goqu.Op{"isNot": nil}}).
+		ToSQL()
+	if err != nil {
+		return fmt.Errorf("error while preparing set password: %w", err)
+	}
+
+	return audited(ctx, s.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, sql, args...)
+		if err != nil {
+			return fmt.Errorf("error while setting password: %w", err)
+		}
+
+		if tag.RowsAffected() == 0 {
+			return custom_errors.ErrUserNotFound
+		}
+
+		return execRevokeSessions(ctx, tx, goqu.Ex{"author_id": userID})
+	})
+}
+
+// AddSession starts session of user with its first refresh token
+func (s AuthRepository) AddSession(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) (*models.Session, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	session := models.Session{UserID: userID}
+
+	sql, args, err := goqu.Insert("auth_session").Rows(goqu.Record{"author_id": userID}).Returning("id").ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("error while preparing add session: %w", err)
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&session.ID); err != nil {
+		return nil, fmt.Errorf("error while adding session: %w", mapPgError(err))
+	}
+
+	if err := addRefreshToken(ctx, tx, session.ID, tokenHash, expiresAt); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error while committing session: %w", err)
+	}
+
+	return &session, nil
+}
+
+// Rotate exchanges refresh token for a new one of the same session.
+// Token used before is a sign of theft, its session is revoked
+func (s AuthRepository) Rotate(ctx context.Context, tokenHash string, newTokenHash string, expiresAt time.Time) (*models.Session, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	sql, args, err := goqu.From(goqu.T("refresh_token").As("t")).
+		Select("s.id", "s.author_id", "t.used_at", goqu.L("t.expires_at < now()"), goqu.L("s.revoked_at IS NOT NULL OR a.deleted_at IS NOT NULL")).
+		Join(goqu.T("auth_session").As("s"), goqu.On(goqu.Ex{"s.id": goqu.I("t.session_id")})).
+		Join(goqu.T("author").As("a"), goqu.On(goqu.Ex{"a.id": goqu.I("s.author_id")})).
+		Where(goqu.Ex{"t.token_hash": tokenHash}).
+		ForUpdate(exp.Wait, goqu.T("t"), goqu.T("s")).
+		ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("error while preparing find refresh token: %w", err)
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var session models.Session
+	var usedAt *time.Time
+	var expired, revoked bool
+
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&session.ID, &session.UserID, &usedAt, &expired, &revoked); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, custom_errors.ErrInvalidToken
+		}
+
+		return nil, fmt.Errorf("error while find refresh token: %w", err)
+	}
+
+	if revoked || expired {
+		return nil, custom_errors.ErrInvalidToken
+	}
+
+	if usedAt != nil {
+		if err := execRevokeSessions(ctx, tx, goqu.Ex{"id": session.ID}); err != nil {
+			return nil, err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return nil, fmt.Errorf("error while committing revoke session: %w", err)
+		}
+
+		return nil, custom_errors.ErrInvalidToken
+	}
+
+	sql, args, err = goqu.Update("refresh_token").
+		Set(goqu.Record{"used_at": goqu.L("now()")}).
+		Where(goqu.Ex{"token_hash": tokenHash}).
+		ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("error while preparing use refresh token: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return nil, fmt.Errorf("error while using refresh token: %w", err)
+	}
+
+	if err := addRefreshToken(ctx, tx, session.ID, newTokenHash, expiresAt); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error while committing refresh token: %w", err)
+	}
+
+	return &session, nil
+}
+
+// RevokeSession ends session of refresh token, used tokens of the session are accepted too
+func (s AuthRepository) RevokeSession(ctx context.Context, tokenHash string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	sessions := goqu.From("refresh_token").Select("session_id").Where(goqu.Ex{"token_hash": tokenHash})
+
+	sql, args, err := revokeSessions(goqu.Ex{"id": sessions}).ToSQL()
+	if err != nil {
+		return fmt.Errorf("error while preparing revoke session: %w", err)
+	}
+
+	tag, err := s.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("error while revoking session: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return custom_errors.ErrInvalidToken
+	}
+
+	return nil
+}
+
+// SessionUser returns live user of not revoked session, nil if session is not active
+func (s AuthRepository) SessionUser(ctx context.Context, sessionID int) (*models.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	sql, args, err := goqu.From(goqu.T("auth_session").As("s")).
+		Select("a.id", "a.name", goqu.COALESCE(goqu.I("a.login"), ""), "a.role").
+		Join(goqu.T("author").As("a"), goqu.On(goqu.Ex{"a.id": goqu.I("s.author_id")})).
+		Where(goqu.Ex{"s.id": sessionID, "s.revoked_at": nil, "a.deleted_at": nil}).
+		ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("error while preparing find session: %w", err)
+	}
+
+	var user models.User
+	if err := s.db.QueryRow(ctx, sql, args...).Scan(&user.ID, &user.Name, &user.Login, &user.Role); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error while find session %d: %w", sessionID, err)
+	}
+
+	return &user, nil
+}
+
+func addRefreshToken(ctx context.Context, tx pgx.Tx, sessionID int, tokenHash string, expiresAt time.Time) error {
+	sql, args, err := goqu.Insert("refresh_token").
+		Rows(goqu.Record{"token_hash": tokenHash, "session_id": sessionID, "expires_at": expiresAt}).
+		ToSQL()
+	if err != nil {
+		return fmt.Errorf("error while preparing add refresh token: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("error while adding refresh token: %w", mapPgError(err))
+	}
+
+	return nil
+}
+
+// revokeSessions revokes active sessions matching where
+func revokeSessions(where exp.Ex) *goqu.UpdateDataset {
+	return goqu.Update("auth_session").
+		Set(goqu.Record{"revoked_at": goqu.L("now()")}).
+		Where(where, goqu.Ex{"revoked_at": nil})
+}
+
+func execRevokeSessions(ctx context.Context, tx pgx.Tx, where exp.Ex) error {
+	sql, args, err := revokeSessions(where).ToSQL()
+	if err != nil {
+		return fmt.Errorf("error while preparing revoke sessions: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("error while revoking sessions: %w", err)
+	}
+
+	return nil
+}
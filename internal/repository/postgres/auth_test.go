@@ -0,0 +1,93 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/repository/postgres"
+	"github.com/trad3r/hskills/apirest/internal/testutils"
+)
+
+func TestAuthSessions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	userRepo, authRepo := getAuthRepos(t)
+
+	user := &models.User{Name: "Auth user", Phonenumber: "+79990000000", Login: "auth.user", PasswordHash: "$argon2id$hash"}
+	require.NoError(t, userRepo.Add(ctx, user))
+
+	credentials, err := authRepo.FindCredentials(ctx, "auth.user")
+	require.NoError(t, err)
+	require.NotNil(t, credentials)
+	assert.Equal(t, user.ID, credentials.UserID)
+	assert.Equal(t, "$argon2id$hash", credentials.PasswordHash)
+
+	credentials, err = authRepo.FindCredentialsById(ctx, 1)
+	require.NoError(t, err)
+	assert.Nil(t, credentials, "fixture users have no credentials")
+
+	expiresAt := time.Now().Add(time.Hour)
+
+	session, err := authRepo.AddSession(ctx, user.ID, "a1", expiresAt)
+	require.NoError(t, err)
+
+	sessionUser, err := authRepo.SessionUser(ctx, session.ID)
+	require.NoError(t, err)
+	require.NotNil(t, sessionUser)
+	assert.Equal(t, "auth.user", sessionUser.Login)
+
+	rotated, err := authRepo.Rotate(ctx, "a1", "a2", expiresAt)
+	require.NoError(t, err)
+	assert.Equal(t, session.ID, rotated.ID)
+
+	// second use of a rotated token means it leaked, the whole session is revoked
+	_, err = authRepo.Rotate(ctx, "a1", "a3", expiresAt)
+	assert.ErrorIs(t, err, custom_errors.ErrInvalidToken)
+
+	_, err = authRepo.Rotate(ctx, "a2", "a4", expiresAt)
+	assert.ErrorIs(t, err, custom_errors.ErrInvalidToken)
+
+	sessionUser, err = authRepo.SessionUser(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Nil(t, sessionUser)
+
+	expired, err := authRepo.AddSession(ctx, user.ID, "b1", time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+
+	_, err = authRepo.Rotate(ctx, "b1", "b2", expiresAt)
+	assert.ErrorIs(t, err, custom_errors.ErrInvalidToken)
+
+	require.NoError(t, authRepo.RevokeSession(ctx, "b1"))
+	assert.ErrorIs(t, authRepo.RevokeSession(ctx, "b1"), custom_errors.ErrInvalidToken)
+
+	sessionUser, err = authRepo.SessionUser(ctx, expired.ID)
+	require.NoError(t, err)
+	assert.Nil(t, sessionUser)
+
+	active, err := authRepo.AddSession(ctx, user.ID, "c1", expiresAt)
+	require.NoError(t, err)
+
+	require.NoError(t, authRepo.SetPassword(ctx, user.ID, "$argon2id$other"))
+
+	sessionUser, err = authRepo.SessionUser(ctx, active.ID)
+	require.NoError(t, err)
+	assert.Nil(t, sessionUser, "password change ends sessions")
+
+	assert.ErrorIs(t, authRepo.SetPassword(ctx, 1, "$argon2id$other"), custom_errors.ErrUserNotFound)
+
+	_, err = authRepo.Rotate(ctx, "unknown", "d1", expiresAt)
+	assert.ErrorIs(t, err, custom_errors.ErrInvalidToken)
+}
+
+func getAuthRepos(t *testing.T) (postgres.IUserRepository, postgres.IAuthRepository) {
+	db := testutils.PrepareDB(t)
+
+	return postgres.NewUserRepository(db), postgres.NewAuthRepository(db)
+}
@@ -0,0 +1,221 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/models"
+)
+
+// bulkQuery is a statement of one bulk request item
+type bulkQuery struct {
+	sql  string
+	args []interface{}
+	// scan reads statement result
+	scan func(row pgx.Row) error
+}
+
+// runBulk executes bulk queries in one transaction and returns per query errors.
+// Atomic mode sends all queries in one batch and rolls everything back on the first failure,
+// otherwise every query runs in its own savepoint and failed ones don't affect the rest
+func runBulk(ctx context.Context, db *pgxpool.Pool, queries []bulkQuery, atomic bool) ([]error, error) {
+	tx, err := beginAudited(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var errs []error
+	if atomic {
+		errs, err = runBatch(ctx, tx, queries)
+	} else {
+		errs, err = runSavepoints(ctx, tx, queries)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if atomic && hasErrors(errs) {
+		// successful statements are rolled back too
+		for i := range errs {
+			if errs[i] == nil {
+				errs[i] = custom_errors.ErrBulkAborted
+			}
+		}
+
+		return errs, nil
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error while committing transaction: %w", mapPgError(err))
+	}
+
+	return errs, nil
+}
+
+func runBatch(ctx context.Context, tx pgx.Tx, queries []bulkQuery) ([]error, error) {
+	errs := make([]error, len(queries))
+
+	batch := &pgx.Batch{}
+	for _, q := range queries {
+		batch.Queue(q.sql, q.args...)
+	}
+
+	br := tx.SendBatch(ctx, batch)
+
+	failed := false
+	for i, q := range queries {
+		if failed {
+			errs[i] = custom_errors.ErrBulkAborted
+			continue
+		}
+
+		if err := q.scan(br.QueryRow()); err != nil {
+			errs[i] = mapPgError(err)
+			failed = true
+		}
+	}
+
+	if err := br.Close(); err != nil && !failed {
+		return nil, fmt.Errorf("error while closing batch: %w", err)
+	}
+
+	return errs, nil
+}
+
+// bulkSavepoint isolates a query of partial bulk request
+const bulkSavepoint = "bulk_item"
+
+// runSavepoints runs every query in its own savepoint. Queries are sent in one batch, each between
+// SAVEPOINT and RELEASE, instead of three round trips per query. A failed query makes the server skip
+// the rest of the batch, so its savepoint is rolled back and the following queries are sent again:
+// a request takes one round trip plus two per failed query, and queries after a failure are resent.
+// A query returning no row has changed nothing, it is reported without stopping the batch
+func runSavepoints(ctx context.Context, tx pgx.Tx, queries []bulkQuery) ([]error, error) {
+	errs := make([]error, len(queries))
+
+	for start := 0; start < len(queries); {
+		failed := -1
+
+		batch := &pgx.Batch{}
+		for i := start; i < len(queries); i++ {
+			batch.Queue("SAVEPOINT " + bulkSavepoint)
+			batch.Queue(queries[i].sql, queries[i].args...).QueryRow(func(row pgx.Row) error {
+				scanned := &scannedRow{Row: row}
+				errs[i] = queries[i].scan(scanned)
+				if errs[i] == nil || errors.Is(scanned.err, pgx.ErrNoRows) {
+					return nil
+				}
+
+				failed = i
+				return errs[i]
+			})
+			batch.Queue("RELEASE SAVEPOINT " + bulkSavepoint)
+		}
+
+		// results are read by queued functions
+		err := tx.SendBatch(ctx, batch).Close()
+		if failed < 0 {
+			if err != nil {
+				return nil, fmt.Errorf("error while running batch: %w", err)
+			}
+
+			break
+		}
+
+		var pgErr *pgconn.PgError
+		if !errors.As(errs[failed], &pgErr) {
+			return nil, fmt.Errorf("error while reading batch result: %w", errs[failed])
+		}
+		errs[failed] = mapPgError(errs[failed])
+
+		if _, err := tx.Exec(ctx, "ROLLBACK TO SAVEPOINT "+bulkSavepoint+"; RELEASE SAVEPOINT "+bulkSavepoint); err != nil {
+			return nil, fmt.Errorf("error while rolling back savepoint: %w", err)
+		}
+
+		start = failed + 1
+	}
+
+	return errs, nil
+}
+
+// scannedRow keeps scan error of row, so missing row is told apart from errors scan functions return for it
+type scannedRow struct {
+	pgx.Row
+	err error
+}
+
+func (r *scannedRow) Scan(dest ...any) error {
+	r.err = r.Row.Scan(dest...)
+	return r.err
+}
+
+// scanImported returns scan function reading returned ID and whether row was inserted, not updated
+func scanImported(result *models.ImportResult) func(row pgx.Row) error {
+	return func(row pgx.Row) error {
+		if err := row.Scan(&result.ID, &result.Created); err != nil {
+			// conflicting row is not updated when it is in trash
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("%w: external ID belongs to a deleted row", custom_errors.ErrConflict)
+			}
+
+			return err
+		}
+
+		return nil
+	}
+}
+
+// importResults merges statement errors into results
+func importResults(results []models.ImportResult, errs []error) []models.ImportResult {
+	for i := range results {
+		results[i].Err = errs[i]
+		if errs[i] != nil {
+			results[i].ID = 0
+			results[i].Created = false
+		}
+	}
+
+	return results
+}
+
+// scanID returns scan function reading returned ID, missing row means notFound
+func scanID(id *int, notFound error) func(row pgx.Row) error {
+	return func(row pgx.Row) error {
+		if err := row.Scan(id); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return notFound
+			}
+
+			return err
+		}
+
+		return nil
+	}
+}
+
+// nullString converts empty string to SQL NULL
+func nullString(s string) interface{} {
+	if len(s) == 0 {
+		return nil
+	}
+
+	return s
+}
+
+func hasErrors(errs []error) bool {
+	for _, err := range errs {
+		if err != nil {
+			return true
+		}
+	}
+
+	return false
+}
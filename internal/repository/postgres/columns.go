@@ -0,0 +1,25 @@
+package postgres
+
+// column is a selected column and its scan target in T
+type column[T any] struct {
+	expr interface{}
+	dest func(item *T) any
+}
+
+func selectExprs[T any](columns []column[T]) []interface{} {
+	exprs := make([]interface{}, 0, len(columns))
+	for _, c := range columns {
+		exprs = append(exprs, c.expr)
+	}
+
+	return exprs
+}
+
+func scanDests[T any](columns []column[T], item *T) []any {
+	dests := make([]any, 0, len(columns))
+	for _, c := range columns {
+		dests = append(dests, c.dest(item))
+	}
+
+	return dests
+}
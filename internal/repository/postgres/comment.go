@@ -0,0 +1,227 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/hashicorp/go-multierror"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/repository/filters"
+)
+
+type ICommentRepository interface {
+	Add(ctx context.Context, comment *models.Comment) error
+	GetList(ctx context.Context, filter filters.CommentFilter) (*models.Page[models.Comment], error)
+	Update(ctx context.Context, id int, commentReq filters.CommentUpdateRequest) (int, error)
+	Delete(ctx context.Context, id int) error
+	FindById(ctx context.Context, id int) (*models.Comment, error)
+}
+
+type CommentRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewCommentRepository(db *pgxpool.Pool) ICommentRepository {
+	return CommentRepository{
+		db: db,
+	}
+}
+
+// commentCountSubquery counts comments of the post with table alias, replies included
+func commentCountSubquery(alias string) *goqu.SelectDataset {
+	return goqu.From(goqu.T("comment").As("cc")).Select(goqu.COUNT("id")).Where(goqu.I("cc.post_id").Eq(goqu.I(alias + ".id")))
+}
+
+var commentSortColumns = map[string]sortColumn[models.Comment]{
+	"id":         {expr: goqu.I("c.id"), value: func(c models.Comment) any { return c.ID }},
+	"created_at": {expr: goqu.I("c.created_at"), value: func(c models.Comment) any { return c.CreatedAt }},
+}
+
+func commentSelect() *goqu.SelectDataset {
+	replyCountSubquery := goqu.From(goqu.T("comment").As("r")).Select(goqu.COUNT("id")).Where(goqu.I("r.parent_id").Eq(goqu.I("c.id")))
+
+	return goqu.From(goqu.T("comment").As("c")).
+		Select("c.id", "c.post_id", "c.parent_id", "c.body", replyCountSubquery.As("reply_count"), "c.created_at", "c.updated_at", "c.version", "a.id", "a.name").
+		Join(goqu.T("author").As("a"), goqu.On(goqu.Ex{"a.id": goqu.I("c.author_id")}))
+}
+
+func scanComment(row pgx.Row) (models.Comment, error) {
+	c := models.Comment{Author: &models.User{}}
+	err := row.Scan(&c.ID, &c.PostID, &c.ParentID, &c.Body, &c.ReplyCount, &c.CreatedAt, &c.UpdatedAt, &c.Version, &c.Author.ID, &c.Author.Name)
+
+	return c, err
+}
+
+// Add adds new comment
+func (s CommentRepository) Add(ctx context.Context, comment *models.Comment) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	sql, args, err := goqu.Insert("comment").
+		Cols("post_id", "parent_id", "author_id", "body").
+		Vals(goqu.Vals{comment.PostID, comment.ParentID, comment.Author.ID, comment.Body}).
+		Returning("id", "created_at", "version").
+		ToSQL()
+	if err != nil {
+		return fmt.Errorf("error while creating sql: %w", err)
+	}
+
+	if err := s.db.QueryRow(ctx, sql, args...).Scan(&comment.ID, &comment.CreatedAt, &comment.Version); err != nil {
+		return fmt.Errorf("error while inserting comment: %w", mapPgError(err))
+	}
+
+	return nil
+}
+
+// GetList returns comments page, oldest first
+func (s CommentRepository) GetList(ctx context.Context, filter filters.CommentFilter) (*models.Page[models.Comment], error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	keys, cursorValues, err := sortKeys(commentSortColumns, []filters.SortField{{Field: "created_at"}})
+	if err != nil {
+		return nil, err
+	}
+
+	wheres := []goqu.Expression{goqu.I("c.post_id").Eq(filter.PostID)}
+	if filter.ParentID != nil {
+		wheres = append(wheres, goqu.I("c.parent_id").Eq(*filter.ParentID))
+	} else {
+		wheres = append(wheres, goqu.I("c.parent_id").IsNull())
+	}
+
+	if filter.Cursor != nil {
+		if err := checkCursor(keys, filter.Cursor); err != nil {
+			return nil, err
+		}
+
+		wheres = append(wheres, keysetCondition(keys, filter.Cursor))
+	}
+
+	sql, args, err := commentSelect().
+		Where(wheres...).
+		Order(orderBy(keys, filter.Cursor != nil && filter.Cursor.Backward)...).
+		Limit(uint(filter.Limit + 1)).
+		ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("error while creating sql: %w", err)
+	}
+
+	rows, err := s.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying comments: %w", err)
+	}
+	defer rows.Close()
+
+	var errs error
+	comments := make([]models.Comment, 0, filter.Limit+1)
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			errs = multierror.Append(errs, err)
+			continue
+		}
+
+		comments = append(comments, comment)
+	}
+
+	if err := rows.Err(); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+
+	if errs != nil {
+		return nil, errs
+	}
+
+	return newPage(comments, filter.Limit, 0, keys, filter.Cursor, cursorValues), nil
+}
+
+// Update changes comment body and returns new comment version
+func (s CommentRepository) Update(ctx context.Context, id int, commentReq filters.CommentUpdateRequest) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	wheres := goqu.Ex{"id": id}
+	if len(commentReq.IfVersions) > 0 {
+		wheres["version"] = commentReq.IfVersions
+	}
+
+	sql, args, err := goqu.Update("comment").
+		Set(goqu.Record{"body": commentReq.Body, "updated_at": time.Now(), "version": goqu.L("version + 1")}).
+		Where(wheres).
+		Returning("version").
+		ToSQL()
+	if err != nil {
+		return 0, fmt.Errorf("error while preparing update comment: %w", err)
+	}
+
+	var version int
+	if err := s.db.QueryRow(ctx, sql, args...).Scan(&version); err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return 0, fmt.Errorf("error while updating comment: %w", err)
+		}
+
+		comment, err := s.FindById(ctx, id)
+		if err != nil {
+			return 0, err
+		}
+
+		if comment == nil {
+			return 0, custom_errors.ErrCommentNotFound
+		}
+
+		return 0, custom_errors.ErrPreconditionFailed
+	}
+
+	return version, nil
+}
+
+// Delete removes comment with all its replies
+func (s CommentRepository) Delete(ctx context.Context, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	sql, args, err := goqu.Delete("comment").Where(goqu.Ex{"id": id}).ToSQL()
+	if err != nil {
+		return fmt.Errorf("error while preparing delete comment: %w", err)
+	}
+
+	tag, err := s.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("error while deleting comment: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return custom_errors.ErrCommentNotFound
+	}
+
+	return nil
+}
+
+// FindById returns comment by ID, nil if it is not found
+func (s CommentRepository) FindById(ctx context.Context, id int) (*models.Comment, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	sql, args, err := commentSelect().Where(goqu.Ex{"c.id": id}).ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("error while preparing find comment by ID %d: %w", id, err)
+	}
+
+	comment, err := scanComment(s.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error while find comment by ID %d: %w", id, err)
+	}
+
+	return &comment, nil
+}
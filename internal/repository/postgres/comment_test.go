@@ -0,0 +1,88 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-faker/faker/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/repository/filters"
+	"github.com/trad3r/hskills/apirest/internal/repository/postgres"
+	"github.com/trad3r/hskills/apirest/internal/testutils"
+)
+
+func TestPostComments(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	postRepo, commentRepo := getCommentRepos(t)
+
+	post := &models.Post{Subject: faker.Sentence(), Author: &models.User{ID: 1}}
+	err := postRepo.Add(ctx, post)
+	require.NoError(t, err)
+
+	root := &models.Comment{PostID: post.ID, Author: &models.User{ID: 1}, Body: faker.Sentence()}
+	require.NoError(t, commentRepo.Add(ctx, root))
+
+	reply := &models.Comment{PostID: post.ID, ParentID: &root.ID, Author: &models.User{ID: 2}, Body: faker.Sentence()}
+	require.NoError(t, commentRepo.Add(ctx, reply))
+
+	nested := &models.Comment{PostID: post.ID, ParentID: &reply.ID, Author: &models.User{ID: 1}, Body: faker.Sentence()}
+	require.NoError(t, commentRepo.Add(ctx, nested))
+
+	testCases := []struct {
+		name        string
+		parentID    *int
+		expectedIds []int
+		replyCount  int
+	}{
+		{name: "Top level", expectedIds: []int{root.ID}, replyCount: 1},
+		{name: "Replies", parentID: &root.ID, expectedIds: []int{reply.ID}, replyCount: 1},
+		{name: "Nested replies", parentID: &reply.ID, expectedIds: []int{nested.ID}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			page, err := commentRepo.GetList(ctx, filters.CommentFilter{PostID: post.ID, ParentID: tc.parentID, Limit: 10})
+			require.NoError(t, err)
+
+			ids := make([]int, 0, len(page.Items))
+			for _, c := range page.Items {
+				ids = append(ids, c.ID)
+				assert.Equal(t, tc.replyCount, c.ReplyCount)
+			}
+
+			assert.Equal(t, tc.expectedIds, ids)
+		})
+	}
+
+	dbPost, err := postRepo.FindById(ctx, post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, dbPost.CommentCount)
+
+	_, err = commentRepo.Update(ctx, root.ID, filters.CommentUpdateRequest{Body: "edited", IfVersions: []int{root.Version + 1}})
+	assert.ErrorIs(t, err, custom_errors.ErrPreconditionFailed)
+
+	version, err := commentRepo.Update(ctx, root.ID, filters.CommentUpdateRequest{Body: "edited", IfVersions: []int{root.Version}})
+	require.NoError(t, err)
+	assert.Equal(t, root.Version+1, version)
+
+	require.NoError(t, commentRepo.Delete(ctx, root.ID))
+
+	dbPost, err = postRepo.FindById(ctx, post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, dbPost.CommentCount)
+
+	err = commentRepo.Delete(ctx, root.ID)
+	assert.ErrorIs(t, err, custom_errors.ErrCommentNotFound)
+}
+
+func getCommentRepos(t *testing.T) (postgres.IPostRepository, postgres.ICommentRepository) {
+	db := testutils.PrepareDB(t)
+
+	return postgres.NewPostRepository(db), postgres.NewCommentRepository(db)
+}
@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+)
+
+const (
+	pgForeignKeyViolation = "23503"
+	pgUniqueViolation     = "23505"
+)
+
+// mapPgError converts constraint violations into custom errors, other errors are returned as is
+func mapPgError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch pgErr.Code {
+	case pgUniqueViolation, pgForeignKeyViolation:
+		return fmt.Errorf("%w: %s", custom_errors.ErrConflict, pgErr.ConstraintName)
+	}
+
+	return err
+}
+
+// missingRowError tells why conditional write has not found a row: it is either deleted or has another version
+func missingRowError(ctx context.Context, db *pgxpool.Pool, table string, id int, notFound error) error {
+	sql, args, err := goqu.From(table).Select("id").Where(goqu.Ex{"id": id, "deleted_at": nil}).ToSQL()
+	if err != nil {
+		return fmt.Errorf("error while preparing find %s by ID %d: %w", table, id, err)
+	}
+
+	if err := db.QueryRow(ctx, sql, args...).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return notFound
+		}
+
+		return fmt.Errorf("error while find %s by ID %d: %w", table, id, err)
+	}
+
+	return custom_errors.ErrPreconditionFailed
+}
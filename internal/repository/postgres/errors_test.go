@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+)
+
+func TestMapPgError(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		err      error
+		conflict bool
+		message  string
+	}{
+		{name: "unique violation", err: &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: "author_login_unique"}, conflict: true, message: "resource conflicts with existing data: author_login_unique"},
+		{name: "foreign key violation", err: &pgconn.PgError{Code: pgForeignKeyViolation, ConstraintName: "fk_author"}, conflict: true, message: "resource conflicts with existing data: fk_author"},
+		{name: "wrapped violation", err: fmt.Errorf("error while adding user: %w", &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: "post_slug_unique"}), conflict: true, message: "resource conflicts with existing data: post_slug_unique"},
+		{name: "check violation", err: &pgconn.PgError{Code: "23514", Message: "violates check constraint"}, message: ": violates check constraint (SQLSTATE 23514)"},
+		{name: "not a postgres error", err: pgx.ErrNoRows, message: "no rows in result set"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := mapPgError(tc.err)
+			assert.Equal(t, tc.conflict, errors.Is(err, custom_errors.ErrConflict))
+			assert.Equal(t, tc.message, err.Error())
+			if !tc.conflict {
+				assert.Same(t, tc.err, err, "other errors are returned as is")
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package postgres
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/pagination"
+	"github.com/trad3r/hskills/apirest/internal/repository/filters"
+)
+
+type sortExpr interface {
+	exp.Comparable
+	exp.Orderable
+}
+
+// sortKey is an expression the list is ordered by. The last key of a list must be unique
+type sortKey struct {
+	name string
+	expr sortExpr
+	desc bool
+}
+
+// sortColumn is a sortable column of T and its value for cursors
+type sortColumn[T any] struct {
+	expr  sortExpr
+	value func(item T) any
+}
+
+// sortKeys builds keys of requested order from allowed columns, ID is appended as a tiebreaker
+// in the direction of the first key. It also returns cursor values function of the order
+func sortKeys[T any](columns map[string]sortColumn[T], sort []filters.SortField) ([]sortKey, func(item T) []any, error) {
+	keys := make([]sortKey, 0, len(sort)+1)
+	values := make([]func(item T) any, 0, len(sort)+1)
+
+	for _, field := range sort {
+		column, ok := columns[field.Field]
+		if !ok {
+			return nil, nil, custom_errors.NewValidationError("sort", "can't sort by "+field.Field)
+		}
+
+		keys = append(keys, sortKey{name: field.Field, expr: column.expr, desc: field.Desc})
+		values = append(values, column.value)
+
+		if field.Field == "id" {
+			break
+		}
+	}
+
+	if len(keys) == 0 || keys[len(keys)-1].name != "id" {
+		desc := len(keys) > 0 && keys[0].desc
+		keys = append(keys, sortKey{name: "id", expr: columns["id"].expr, desc: desc})
+		values = append(values, columns["id"].value)
+	}
+
+	return keys, func(item T) []any {
+		v := make([]any, 0, len(values))
+		for _, value := range values {
+			v = append(v, value(item))
+		}
+
+		return v
+	}, nil
+}
+
+// sortedBy reports whether order has field
+func sortedBy(sort []filters.SortField, field string) bool {
+	return slices.ContainsFunc(sort, func(f filters.SortField) bool {
+		return f.Field == field
+	})
+}
+
+// sortSignature identifies list order, cursors issued for one order can't be used with another
+func sortSignature(keys []sortKey) string {
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if k.desc {
+			parts = append(parts, k.name+":desc")
+		} else {
+			parts = append(parts, k.name+":asc")
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// checkCursor verifies cursor was issued for the keys order
+func checkCursor(keys []sortKey, cursor *pagination.Cursor) error {
+	if cursor.Sort != sortSignature(keys) || len(cursor.Values) != len(keys) {
+		return custom_errors.NewValidationError("cursor", "does not match list order")
+	}
+
+	return nil
+}
+
+// orderBy returns ORDER BY clause for keys, reversed for backward pagination
+func orderBy(keys []sortKey, backward bool) []exp.OrderedExpression {
+	order := make([]exp.OrderedExpression, 0, len(keys))
+	for _, k := range keys {
+		if k.desc != backward {
+			order = append(order, k.expr.Desc())
+		} else {
+			order = append(order, k.expr.Asc())
+		}
+	}
+
+	return order
+}
+
+// keysetCondition selects rows following cursor values in keys order:
+// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ...
+func keysetCondition(keys []sortKey, cursor *pagination.Cursor) exp.Expression {
+	ors := make([]exp.Expression, 0, len(keys))
+	for i, k := range keys {
+		ands := make([]exp.Expression, 0, i+1)
+		for j := 0; j < i; j++ {
+			ands = append(ands, keys[j].expr.Eq(cursor.Values[j]))
+		}
+
+		if k.desc != cursor.Backward {
+			ands = append(ands, k.expr.Lt(cursor.Values[i]))
+		} else {
+			ands = append(ands, k.expr.Gt(cursor.Values[i]))
+		}
+
+		ors = append(ors, goqu.And(ands...))
+	}
+
+	return goqu.Or(ors...)
+}
+
+// newPage trims the extra row fetched to detect more data and fills neighbour page cursors
+func newPage[T any](items []T, limit int, offset int, keys []sortKey, cursor *pagination.Cursor, values func(T) []any) *models.Page[T] {
+	backward := cursor != nil && cursor.Backward
+
+	hasMore := limit > 0 && len(items) > limit
+	if hasMore {
+		items = items[:limit]
+	}
+
+	if backward {
+		slices.Reverse(items)
+	}
+
+	page := &models.Page[T]{Items: items}
+	if len(items) == 0 || limit == 0 {
+		return page
+	}
+
+	signature := sortSignature(keys)
+
+	if (backward && hasMore) || (!backward && (cursor != nil || offset > 0)) {
+		page.Prev = pagination.Encode(pagination.Cursor{Sort: signature, Values: values(items[0]), Backward: true})
+	}
+
+	if backward || hasMore {
+		page.Next = pagination.Encode(pagination.Cursor{Sort: signature, Values: values(items[len(items)-1])})
+	}
+
+	return page
+}
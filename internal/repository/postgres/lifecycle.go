@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/repository/filters"
+)
+
+// Transition changes post status when the post is in one of transition From states and returns new post version
+func (s PostRepository) Transition(ctx context.Context, id int, transition filters.PostTransition) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	wheres := goqu.Ex{"id": id, "deleted_at": nil, "status": transition.From}
+	if len(transition.IfVersions) > 0 {
+		wheres["version"] = transition.IfVersions
+	}
+
+	sql, args, err := goqu.Update("post").
+		Set(goqu.Record{"status": transition.To, "publish_at": transition.PublishAt, "updated_at": time.Now(), "version": goqu.L("version + 1")}).
+		Where(wheres).
+		Returning("version").
+		ToSQL()
+	if err != nil {
+		return 0, fmt.Errorf("error while preparing post transition: %w", err)
+	}
+
+	var version int
+	err = audited(ctx, s.db, func(tx pgx.Tx) error {
+		return tx.QueryRow(ctx, sql, args...).Scan(&version)
+	})
+	if err == nil {
+		return version, nil
+	}
+
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("error while changing post status: %w", err)
+	}
+
+	post, err := s.FindById(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	if post == nil {
+		return 0, custom_errors.ErrPostNotFound
+	}
+
+	if !slices.Contains(transition.From, post.Status) {
+		return 0, fmt.Errorf("%w: post is %s", custom_errors.ErrTransitionNotAllowed, post.Status)
+	}
+
+	return 0, custom_errors.ErrPreconditionFailed
+}
+
+// PublishDue publishes scheduled posts whose publish time is not after now, overdue posts are published as well
+func (s PostRepository) PublishDue(ctx context.Context, now time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	sql, args, err := goqu.Update("post").
+		Set(goqu.Record{"status": models.PostStatusPublished, "updated_at": now, "version": goqu.L("version + 1")}).
+		Where(goqu.Ex{"status": models.PostStatusScheduled, "deleted_at": nil}, goqu.C("publish_at").Lte(now)).
+		ToSQL()
+	if err != nil {
+		return 0, fmt.Errorf("error while preparing publish posts: %w", err)
+	}
+
+	var tag pgconn.CommandTag
+	err = audited(ctx, s.db, func(tx pgx.Tx) error {
+		tag, err = tx.Exec(ctx, sql, args...)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error while publishing posts: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -4,22 +4,42 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"html"
+	"maps"
+	"strings"
 	"time"
 
 	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
 	"github.com/hashicorp/go-multierror"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
 	"github.com/trad3r/hskills/apirest/internal/models"
 	"github.com/trad3r/hskills/apirest/internal/repository/filters"
 )
 
 type IPostRepository interface {
 	Add(ctx context.Context, post *models.Post) error
-	GetList(ctx context.Context, filter filters.PostFilter) ([]models.Post, error)
-	Update(ctx context.Context, id int, postReq filters.PostUpdateRequest) error
+	GetList(ctx context.Context, filter filters.PostFilter) (*models.Page[models.Post], error)
+	Export(ctx context.Context, filter filters.PostFilter, fn func(post models.Post) error) error
+	Update(ctx context.Context, id int, postReq filters.PostUpdateRequest) (int, error)
 	Delete(ctx context.Context, id int) error
+	Restore(ctx context.Context, id int) (int, error)
+	Purge(ctx context.Context, before time.Time) (int64, error)
+	Transition(ctx context.Context, id int, transition filters.PostTransition) (int, error)
+	PublishDue(ctx context.Context, now time.Time) (int64, error)
 	FindById(ctx context.Context, id int) (*models.Post, error)
+	FindIdBySlug(ctx context.Context, postSlug string) (int, error)
+	FindAuthorIds(ctx context.Context, ids []int, deleted filters.Deleted) (map[int]int, error)
+	Revisions(ctx context.Context, postID int, filter filters.RevisionFilter) (*models.Page[models.PostRevision], error)
+	Revision(ctx context.Context, postID int, revision int) (*models.PostRevision, error)
+	RestoreRevision(ctx context.Context, postID int, revision int, ifVersions []int) (int, error)
+	BulkAdd(ctx context.Context, posts []*models.Post, atomic bool) ([]error, error)
+	BulkUpdate(ctx context.Context, items []filters.PostBulkUpdateItem, atomic bool) ([]error, error)
+	BulkDelete(ctx context.Context, ids []int, atomic bool) ([]error, error)
+	Import(ctx context.Context, items []filters.PostImportItem, atomic bool) ([]models.ImportResult, error)
 }
 
 type PostRepository struct {
@@ -37,58 +57,55 @@ func (s PostRepository) Add(ctx context.Context, post *models.Post) error {
 	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
 
-	ds := goqu.Insert("post").
-		Cols("subject", "body", "author_id").
-		Vals(
-			goqu.Vals{post.Subject, post.Body, post.Author.ID},
-		).
-		Returning("id")
-
-	sql, args, err := ds.ToSQL()
+	sql, args, err := postInsert(post).ToSQL()
 	if err != nil {
 		return fmt.Errorf("error while creating sql: %w", err)
 	}
 
-	err = s.db.QueryRow(ctx, sql, args...).Scan(&post.ID)
+	err = audited(ctx, s.db, func(tx pgx.Tx) error {
+		return tx.QueryRow(ctx, sql, args...).Scan(&post.ID)
+	})
 	if err != nil {
-		return fmt.Errorf("error while inserting post: %w", err)
+		return fmt.Errorf("error while inserting post: %w", mapPgError(err))
 	}
 
 	return nil
 }
 
-// GetList returns post list
-func (s PostRepository) GetList(ctx context.Context, filter filters.PostFilter) ([]models.Post, error) {
+// GetList returns post list page
+func (s PostRepository) GetList(ctx context.Context, filter filters.PostFilter) (*models.Page[models.Post], error) {
 	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
 
 	var errs error
-	var posts []models.Post
-	var wheres []goqu.Expression
-
-	ds := goqu.From(goqu.T("post").As("p")).
-		Select("p.id", "p.subject", "p.body", "p.created_at", "p.updated_at", "a.id", "a.name", "a.phonenumber", "a.created_at", "a.updated_at").
-		Join(goqu.T("author").As("a"), goqu.On(goqu.Ex{"a.id": goqu.I("p.author_id")}))
+	posts := make([]models.Post, 0, filter.Limit+1)
 
-	if !filter.FromCreatedAt.IsZero() {
-		wheres = append(wheres, goqu.T("p").Col("created_at").Gte(filter.FromCreatedAt))
+	list, err := newPostList(filter)
+	if err != nil {
+		return nil, err
 	}
 
-	if !filter.ToCreatedAt.IsZero() {
-		wheres = append(wheres, goqu.T("p").Col("created_at").Lte(filter.ToCreatedAt))
-	}
+	ds := list.ds
 
-	if len(filter.Authors) > 0 {
-		wheres = append(wheres, goqu.T("p").Col("author_id").In(filter.Authors))
-	}
+	if filter.Cursor != nil {
+		if err := checkCursor(list.keys, filter.Cursor); err != nil {
+			return nil, err
+		}
 
-	if len(wheres) > 0 {
-		ds = ds.Where(goqu.And(wheres...))
+		ds = ds.Where(goqu.And(append(list.wheres, keysetCondition(list.keys, filter.Cursor))...))
+	} else {
+		if len(list.wheres) > 0 {
+			ds = ds.Where(goqu.And(list.wheres...))
+		}
+
+		if filter.Offset > 0 {
+			ds = ds.Offset(uint(filter.Offset))
+		}
 	}
 
-	ds = ds.
-		Offset(uint(filter.Offset)).
-		Limit(uint(filter.Limit))
+	if filter.Limit > 0 {
+		ds = ds.Limit(uint(filter.Limit + 1))
+	}
 
 	sql, args, err := ds.ToSQL()
 	if err != nil {
@@ -102,86 +119,656 @@ func (s PostRepository) GetList(ctx context.Context, filter filters.PostFilter)
 	defer rows.Close()
 
 	for rows.Next() {
-		var user models.User
-		var post models.Post
-
-		err := rows.Scan(&post.ID, &post.Subject, &post.Body, &post.CreatedAt, &post.UpdatedAt, &user.ID,
-			&user.Name, &user.Phonenumber, &user.CreatedAt, &user.UpdatedAt)
+		post, err := list.scan(rows)
 		if err != nil {
 			errs = multierror.Append(errs, err)
 			continue
 		}
 
-		post.Author = user
 		posts = append(posts, post)
 	}
 
-	return posts, errs
-}
+	if err := rows.Err(); err != nil {
+		errs = multierror.Append(errs, err)
+	}
 
-// Update updates post data
-func (s PostRepository) Update(ctx context.Context, id int, postReq filters.PostUpdateRequest) error {
-	ctx, cancel := context.WithTimeout(ctx, time.Minute)
-	defer cancel()
+	if errs != nil {
+		return nil, errs
+	}
+
+	page := newPage(posts, filter.Limit, filter.Offset, list.keys, filter.Cursor, list.cursorValues)
 
-	ds := goqu.Update("post").
-		Where(goqu.Ex{"id": id})
+	if filter.WithTotal {
+		total, err := s.count(ctx, list.wheres)
+		if err != nil {
+			return nil, err
+		}
 
-	updates := make(map[string]interface{}, 3)
-	if len(postReq.Subject) > 0 {
-		updates["subject"] = postReq.Subject
+		page.Total = &total
 	}
 
-	if len(postReq.Body) > 0 {
-		updates["body"] = postReq.Body
+	return page, nil
+}
+
+// Export streams every post matching filter to fn in list order, pagination is ignored
+func (s PostRepository) Export(ctx context.Context, filter filters.PostFilter, fn func(post models.Post) error) error {
+	list, err := newPostList(filter)
+	if err != nil {
+		return err
 	}
 
-	if len(updates) > 0 {
-		updates["updated_at"] = time.Now()
-		ds = ds.Set(updates)
+	ds := list.ds
+	if len(list.wheres) > 0 {
+		ds = ds.Where(goqu.And(list.wheres...))
 	}
 
 	sql, args, err := ds.ToSQL()
 	if err != nil {
-		return fmt.Errorf("error while preparing update post: %w", err)
+		return fmt.Errorf("error while creating sql: %w", err)
 	}
 
-	_, err = s.db.Exec(ctx, sql, args...)
+	rows, err := s.db.Query(ctx, sql, args...)
 	if err != nil {
-		return fmt.Errorf("error while updating post: %w", err)
+		return fmt.Errorf("error while querying posts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		post, err := list.scan(rows)
+		if err != nil {
+			return fmt.Errorf("error while scanning post: %w", err)
+		}
+
+		if err := fn(post); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error while reading posts: %w", err)
 	}
 
 	return nil
 }
 
-// Delete removes post by ID
+// postList is an ordered post list query without pagination
+type postList struct {
+	ds           *goqu.SelectDataset
+	columns      []column[models.Post]
+	withAuthor   bool
+	keys         []sortKey
+	cursorValues func(post models.Post) []any
+	wheres       []goqu.Expression
+}
+
+func newPostList(filter filters.PostFilter) (*postList, error) {
+	// search results are ordered by relevance unless other order is requested
+	sortColumns := postSortColumns
+	sort := filter.Sort
+	if len(filter.Query) > 0 {
+		sortColumns = searchSortColumns(filter)
+		if len(sort) == 0 {
+			sort = []filters.SortField{{Field: "rank", Desc: true}}
+		}
+	}
+
+	if len(sort) == 0 {
+		sort = []filters.SortField{{Field: "created_at", Desc: true}}
+	}
+
+	keys, cursorValues, err := sortKeys(sortColumns, sort)
+	if err != nil {
+		return nil, err
+	}
+
+	backward := filter.Cursor != nil && filter.Cursor.Backward
+
+	list := &postList{
+		columns:      postListColumns(filter, sort),
+		withAuthor:   filter.Projection.Includes("author"),
+		keys:         keys,
+		cursorValues: cursorValues,
+		wheres:       postWheres(filter),
+	}
+
+	list.ds = goqu.From(goqu.T("post").As("p")).
+		Select(selectExprs(list.columns)...).
+		Order(orderBy(keys, backward)...)
+
+	if list.withAuthor {
+		list.ds = list.ds.Join(goqu.T("author").As("a"), goqu.On(goqu.Ex{"a.id": goqu.I("p.author_id")}))
+	}
+
+	// like counters are joined rather than looked up per row, posts without likes have no counter
+	if sortedBy(sort, "likes") {
+		list.ds = list.ds.LeftJoin(goqu.T("post_reaction_count").As("rl"),
+			goqu.On(goqu.Ex{"rl.post_id": goqu.I("p.id"), "rl.type": models.ReactionLike}))
+	}
+
+	return list, nil
+}
+
+func (l *postList) scan(rows pgx.Rows) (models.Post, error) {
+	var post models.Post
+	if l.withAuthor {
+		post.Author = &models.User{}
+	}
+
+	if err := rows.Scan(scanDests(l.columns, &post)...); err != nil {
+		return post, err
+	}
+
+	if len(post.Snippet) > 0 {
+		post.Snippet = markSnippet(post.Snippet)
+	}
+
+	return post, nil
+}
+
+func (s PostRepository) count(ctx context.Context, wheres []goqu.Expression) (int, error) {
+	ds := goqu.From(goqu.T("post").As("p")).Select(goqu.COUNT(goqu.Star()))
+	if len(wheres) > 0 {
+		ds = ds.Where(goqu.And(wheres...))
+	}
+
+	sql, args, err := ds.ToSQL()
+	if err != nil {
+		return 0, fmt.Errorf("error while creating count sql: %w", err)
+	}
+
+	var total int
+	if err := s.db.QueryRow(ctx, sql, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("error while counting posts: %w", err)
+	}
+
+	return total, nil
+}
+
+func postWheres(filter filters.PostFilter) []goqu.Expression {
+	var wheres []goqu.Expression
+
+	if !filter.FromCreatedAt.IsZero() {
+		wheres = append(wheres, goqu.T("p").Col("created_at").Gte(filter.FromCreatedAt))
+	}
+
+	if !filter.ToCreatedAt.IsZero() {
+		wheres = append(wheres, goqu.T("p").Col("created_at").Lte(filter.ToCreatedAt))
+	}
+
+	if len(filter.Authors) > 0 {
+		wheres = append(wheres, goqu.T("p").Col("author_id").In(filter.Authors))
+	}
+
+	if len(filter.Subject) > 0 {
+		wheres = append(wheres, goqu.T("p").Col("subject").ILike("%"+escapeLike(filter.Subject)+"%"))
+	}
+
+	if len(filter.Query) > 0 {
+		wheres = append(wheres, goqu.L("p.search @@ ?", searchQuery(filter)))
+	}
+
+	if len(filter.Tags) > 0 {
+		wheres = append(wheres, postTagsCondition("p", filter.Tags, filter.TagsAll))
+	}
+
+	if len(filter.Statuses) > 0 {
+		wheres = append(wheres, goqu.T("p").Col("status").In(filter.Statuses))
+	}
+
+	if cond := deletedCondition("p", filter.Deleted); cond != nil {
+		wheres = append(wheres, cond)
+	}
+
+	return wheres
+}
+
+// escapeLike escapes LIKE pattern special characters
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// postListColumns returns columns of requested post fields, sort keys are always selected.
+// Search adds rank and highlighted snippet
+func postListColumns(filter filters.PostFilter, sort []filters.SortField) []column[models.Post] {
+	projection := filter.Projection
+
+	columns := []column[models.Post]{
+		{expr: "p.id", dest: func(p *models.Post) any { return &p.ID }},
+		{expr: "p.created_at", dest: func(p *models.Post) any { return &p.CreatedAt }},
+	}
+
+	if projection.HasField("subject") || sortedBy(sort, "subject") {
+		columns = append(columns, column[models.Post]{expr: "p.subject", dest: func(p *models.Post) any { return &p.Subject }})
+	}
+
+	if projection.HasField("slug") {
+		columns = append(columns, column[models.Post]{expr: "p.slug", dest: func(p *models.Post) any { return &p.Slug }})
+	}
+
+	// body_html is rendered from body by service
+	if projection.HasField("body") || projection.HasField("body_html") || sortedBy(sort, "body") {
+		columns = append(columns, column[models.Post]{expr: goqu.COALESCE(goqu.I("p.body"), "").As("body"), dest: func(p *models.Post) any { return &p.Body }})
+	}
+
+	if projection.HasField("updated_at") || sortedBy(sort, "updated_at") {
+		columns = append(columns, column[models.Post]{expr: "p.updated_at", dest: func(p *models.Post) any { return &p.UpdatedAt }})
+	}
+
+	if projection.HasField("status") {
+		columns = append(columns, column[models.Post]{expr: "p.status", dest: func(p *models.Post) any { return &p.Status }})
+	}
+
+	if projection.HasField("publish_at") {
+		columns = append(columns, column[models.Post]{expr: "p.publish_at", dest: func(p *models.Post) any { return &p.PublishAt }})
+	}
+
+	if projection.HasField("comment_count") {
+		columns = append(columns, column[models.Post]{expr: commentCountSubquery("p").As("comment_count"), dest: func(p *models.Post) any { return &p.CommentCount }})
+	}
+
+	if projection.HasField("reactions") || sortedBy(sort, "likes") {
+		columns = append(columns, column[models.Post]{expr: postReactionsExpr("p").As("reactions"), dest: func(p *models.Post) any { return &p.Reactions }})
+	}
+
+	if projection.HasField("tags") {
+		columns = append(columns, column[models.Post]{expr: postTagsExpr("p").As("tags"), dest: func(p *models.Post) any { return &p.Tags }})
+	}
+
+	if filter.Deleted != filters.DeletedExclude && projection.HasField("deleted_at") {
+		columns = append(columns, column[models.Post]{expr: "p.deleted_at", dest: func(p *models.Post) any { return &p.DeletedAt }})
+	}
+
+	if projection.Includes("author") {
+		columns = append(columns,
+			column[models.Post]{expr: "a.id", dest: func(p *models.Post) any { return &p.Author.ID }},
+			column[models.Post]{expr: "a.name", dest: func(p *models.Post) any { return &p.Author.Name }},
+			column[models.Post]{expr: "a.phonenumber", dest: func(p *models.Post) any { return &p.Author.Phonenumber }},
+			column[models.Post]{expr: "a.created_at", dest: func(p *models.Post) any { return &p.Author.CreatedAt }},
+			column[models.Post]{expr: "a.updated_at", dest: func(p *models.Post) any { return &p.Author.UpdatedAt }},
+		)
+	}
+
+	if len(filter.Query) > 0 {
+		columns = append(columns,
+			column[models.Post]{expr: searchRank(filter).As("rank"), dest: func(p *models.Post) any { return &p.Rank }},
+			column[models.Post]{expr: searchSnippet(filter).As("snippet"), dest: func(p *models.Post) any { return &p.Snippet }},
+		)
+	}
+
+	return columns
+}
+
+// searchQuery is a websearch query of filter in configuration of search column, see ConfigureSearch
+func searchQuery(filter filters.PostFilter) exp.LiteralExpression {
+	return goqu.L("websearch_to_tsquery(post_search_config(), ?)", filter.Query)
+}
+
+func searchRank(filter filters.PostFilter) exp.LiteralExpression {
+	return goqu.L("ts_rank(p.search, ?)::float8", searchQuery(filter))
+}
+
+// snippet delimiters are private use characters, they are removed from post text before highlighting
+const (
+	snippetStart = "\ue000"
+	snippetStop  = "\ue001"
+)
+
+// searchSnippet returns subject and body fragments with matches wrapped into delimiters, see markSnippet
+func searchSnippet(filter filters.PostFilter) exp.LiteralExpression {
+	return goqu.L("ts_headline(post_search_config(), translate(coalesce(p.subject, '') || ' ' || coalesce(p.body, ''), ?, ''), ?, ?)",
+		snippetStart+snippetStop, searchQuery(filter),
+		fmt.Sprintf("StartSel=%s, StopSel=%s, MaxFragments=2, MaxWords=30, MinWords=10", snippetStart, snippetStop))
+}
+
+// markSnippet escapes snippet text as HTML and turns delimiters into mark tags
+func markSnippet(snippet string) string {
+	snippet = html.EscapeString(snippet)
+	snippet = strings.ReplaceAll(snippet, snippetStart, "<mark>")
+
+	return strings.ReplaceAll(snippet, snippetStop, "</mark>")
+}
+
+// searchSortColumns adds rank to sortable columns
+func searchSortColumns(filter filters.PostFilter) map[string]sortColumn[models.Post] {
+	columns := maps.Clone(postSortColumns)
+	columns["rank"] = sortColumn[models.Post]{expr: searchRank(filter), value: func(p models.Post) any { return p.Rank }}
+
+	return columns
+}
+
+// postSortColumns are sortable post columns, not updated post is sorted by creation time,
+// likes by counter of like reactions joined by newPostList
+var postSortColumns = map[string]sortColumn[models.Post]{
+	"id":         {expr: goqu.I("p.id"), value: func(p models.Post) any { return p.ID }},
+	"subject":    {expr: goqu.I("p.subject"), value: func(p models.Post) any { return p.Subject }},
+	"created_at": {expr: goqu.I("p.created_at"), value: func(p models.Post) any { return p.CreatedAt }},
+	"likes":      {expr: goqu.COALESCE(goqu.I("rl.count"), 0), value: func(p models.Post) any { return p.Reactions[models.ReactionLike] }},
+	"updated_at": {
+		expr: goqu.COALESCE(goqu.I("p.updated_at"), goqu.I("p.created_at")),
+		value: func(p models.Post) any {
+			if p.UpdatedAt != nil {
+				return p.UpdatedAt
+			}
+
+			return p.CreatedAt
+		},
+	},
+}
+
+// Update updates post data and returns new post version
+func (s PostRepository) Update(ctx context.Context, id int, postReq filters.PostUpdateRequest) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	var version int
+
+	ds, err := postUpdateDataset(id, postReq)
+	if err != nil {
+		return 0, err
+	}
+
+	sql, args, err := postUpdateReturning(ds, postReq.Tags, "version").ToSQL()
+	if err != nil {
+		return 0, fmt.Errorf("error while preparing update post: %w", err)
+	}
+
+	err = audited(ctx, s.db, func(tx pgx.Tx) error {
+		return tx.QueryRow(ctx, sql, args...).Scan(&version)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, missingRowError(ctx, s.db, "post", id, custom_errors.ErrPostNotFound)
+		}
+
+		return 0, fmt.Errorf("error while updating post: %w", mapPgError(err))
+	}
+
+	return version, nil
+}
+
+// postUpdateDataset builds update of the fields set in request
+func postUpdateDataset(id int, postReq filters.PostUpdateRequest) (*goqu.UpdateDataset, error) {
+	wheres := goqu.Ex{"id": id, "deleted_at": nil}
+	if len(postReq.IfVersions) > 0 {
+		wheres["version"] = postReq.IfVersions
+	}
+
+	updates := make(map[string]interface{}, 4)
+	if postReq.Subject.Set {
+		updates["subject"] = postReq.Subject.Value
+		updates["slug"] = postSlug(postReq.Subject.Value, id)
+	}
+
+	if postReq.Body.Set {
+		if postReq.Body.Null {
+			updates["body"] = nil
+		} else {
+			updates["body"] = postReq.Body.Value
+		}
+	}
+
+	if len(updates) == 0 && !postReq.Tags.Set {
+		return nil, custom_errors.NewValidationError("body", "nothing to update")
+	}
+
+	updates["updated_at"] = time.Now()
+	updates["version"] = goqu.L("version + 1")
+
+	return goqu.Update("post").Where(wheres).Set(updates), nil
+}
+
+// postInsert builds insert of post returning its ID, tags are added in the same statement
+func postInsert(post *models.Post) exp.SQLExpression {
+	record := goqu.Record{"subject": post.Subject, "slug": postSlug(post.Subject, nil), "body": post.Body, "author_id": post.Author.ID}
+	if len(post.Status) > 0 {
+		record["status"] = post.Status
+		record["publish_at"] = post.PublishAt
+	}
+
+	ds := goqu.Insert("post").Rows(record)
+
+	if len(post.Tags) == 0 {
+		return ds.Returning("id")
+	}
+
+	return withPostTags(ds.Returning("id", "version"), post.Tags).Select("id")
+}
+
+// postUpdateReturning makes post update return column and replace post tags when they are set
+func postUpdateReturning(ds *goqu.UpdateDataset, tags filters.Field[[]string], column string) exp.SQLExpression {
+	if !tags.Set {
+		return ds.Returning(column)
+	}
+
+	return withPostTags(ds.Returning("id", "version"), tags.Value).Select(column)
+}
+
+// BulkAdd adds posts in one transaction, see runBulk for atomic mode
+func (s PostRepository) BulkAdd(ctx context.Context, posts []*models.Post, atomic bool) ([]error, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	queries := make([]bulkQuery, 0, len(posts))
+	for _, post := range posts {
+		sql, args, err := postInsert(post).ToSQL()
+		if err != nil {
+			return nil, fmt.Errorf("error while creating sql: %w", err)
+		}
+
+		queries = append(queries, bulkQuery{sql: sql, args: args, scan: scanID(&post.ID, nil)})
+	}
+
+	return runBulk(ctx, s.db, queries, atomic)
+}
+
+// Import adds posts or updates ones with the same external ID in one transaction, see runBulk for atomic mode.
+// Author of every item must be resolved to ID, external ID of a deleted post is a conflict, the post stays in trash
+func (s PostRepository) Import(ctx context.Context, items []filters.PostImportItem, atomic bool) ([]models.ImportResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	results := make([]models.ImportResult, len(items))
+	queries := make([]bulkQuery, 0, len(items))
+	for i, item := range items {
+		ds := goqu.Insert("post").
+			Cols("external_id", "subject", "slug", "body", "author_id").
+			Vals(goqu.Vals{nullString(item.ExternalID), item.Subject, postSlug(item.Subject, nil), item.Body, item.Author}).
+			Returning("id", goqu.L("xmax = 0"))
+
+		if len(item.ExternalID) > 0 {
+			ds = ds.OnConflict(goqu.DoUpdate("external_id", goqu.Record{
+				"subject":    goqu.I("excluded.subject"),
+				"slug":       postSlug(item.Subject, goqu.I("post.id")),
+				"body":       goqu.I("excluded.body"),
+				"author_id":  goqu.I("excluded.author_id"),
+				"updated_at": time.Now(),
+				"version":    goqu.L("post.version + 1"),
+			}).Where(goqu.I("post.deleted_at").IsNull()))
+		}
+
+		sql, args, err := ds.ToSQL()
+		if err != nil {
+			return nil, fmt.Errorf("error while creating sql: %w", err)
+		}
+
+		queries = append(queries, bulkQuery{sql: sql, args: args, scan: scanImported(&results[i])})
+	}
+
+	errs, err := runBulk(ctx, s.db, queries, atomic)
+	if err != nil {
+		return nil, err
+	}
+
+	return importResults(results, errs), nil
+}
+
+// BulkUpdate updates posts in one transaction, see runBulk for atomic mode
+func (s PostRepository) BulkUpdate(ctx context.Context, items []filters.PostBulkUpdateItem, atomic bool) ([]error, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	queries := make([]bulkQuery, 0, len(items))
+	for _, item := range items {
+		ds, err := postUpdateDataset(item.ID, item.PostUpdateRequest)
+		if err != nil {
+			return nil, err
+		}
+
+		sql, args, err := postUpdateReturning(ds, item.Tags, "id").ToSQL()
+		if err != nil {
+			return nil, fmt.Errorf("error while preparing update post: %w", err)
+		}
+
+		var id int
+		queries = append(queries, bulkQuery{sql: sql, args: args, scan: scanID(&id, custom_errors.ErrPostNotFound)})
+	}
+
+	return runBulk(ctx, s.db, queries, atomic)
+}
+
+// BulkDelete removes posts by IDs in one transaction, see runBulk for atomic mode
+func (s PostRepository) BulkDelete(ctx context.Context, ids []int, atomic bool) ([]error, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	queries := make([]bulkQuery, 0, len(ids))
+	for _, id := range ids {
+		sql, args, err := postSoftDelete(id).Returning("id").ToSQL()
+		if err != nil {
+			return nil, fmt.Errorf("error while preparing delete post: %w", err)
+		}
+
+		var deletedID int
+		queries = append(queries, bulkQuery{sql: sql, args: args, scan: scanID(&deletedID, custom_errors.ErrPostNotFound)})
+	}
+
+	return runBulk(ctx, s.db, queries, atomic)
+}
+
+// postSoftDelete marks live post as deleted
+func postSoftDelete(id int) *goqu.UpdateDataset {
+	return goqu.Update("post").
+		Set(goqu.Record{"deleted_at": goqu.L("now()"), "version": goqu.L("version + 1")}).
+		Where(goqu.Ex{"id": id, "deleted_at": nil})
+}
+
+// Delete moves post to trash by ID
 func (s PostRepository) Delete(ctx context.Context, id int) error {
 	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
 
-	ds := goqu.Delete("post").Where(goqu.Ex{"id": id})
-	sql, args, err := ds.ToSQL()
+	sql, args, err := postSoftDelete(id).ToSQL()
 	if err != nil {
 		return fmt.Errorf("error while preparing delete post: %w", err)
 	}
 
-	_, err = s.db.Exec(ctx, sql, args...)
+	var tag pgconn.CommandTag
+	err = audited(ctx, s.db, func(tx pgx.Tx) error {
+		tag, err = tx.Exec(ctx, sql, args...)
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("error while deleting post: %w", err)
 	}
 
+	if tag.RowsAffected() == 0 {
+		return custom_errors.ErrPostNotFound
+	}
+
 	return nil
 }
 
+// Restore brings deleted post back and returns its new version
+func (s PostRepository) Restore(ctx context.Context, id int) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	tx, err := beginAudited(ctx, s.db)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	sql, args, err := goqu.From(goqu.T("post").As("p")).
+		Select("p.deleted_at", "a.deleted_at").
+		Join(goqu.T("author").As("a"), goqu.On(goqu.Ex{"a.id": goqu.I("p.author_id")})).
+		Where(goqu.Ex{"p.id": id}).
+		ForUpdate(exp.Wait).
+		ToSQL()
+	if err != nil {
+		return 0, fmt.Errorf("error while preparing restore post: %w", err)
+	}
+
+	var postDeletedAt, authorDeletedAt *time.Time
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&postDeletedAt, &authorDeletedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, custom_errors.ErrPostNotFound
+		}
+
+		return 0, fmt.Errorf("error while find post by ID %d: %w", id, err)
+	}
+
+	if postDeletedAt == nil {
+		return 0, fmt.Errorf("%w: post is not deleted", custom_errors.ErrConflict)
+	}
+
+	if authorDeletedAt != nil {
+		return 0, fmt.Errorf("%w: author is deleted", custom_errors.ErrConflict)
+	}
+
+	sql, args, err = goqu.Update("post").
+		Set(goqu.Record{"deleted_at": nil, "version": goqu.L("version + 1")}).
+		Where(goqu.Ex{"id": id}).
+		Returning("version").
+		ToSQL()
+	if err != nil {
+		return 0, fmt.Errorf("error while preparing restore post: %w", err)
+	}
+
+	var version int
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&version); err != nil {
+		return 0, fmt.Errorf("error while restoring post: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("error while commit restore post: %w", err)
+	}
+
+	return version, nil
+}
+
+// Purge removes posts deleted before given time and returns amount of removed rows
+func (s PostRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	sql, args, err := goqu.Delete("post").Where(goqu.C("deleted_at").Lt(before)).ToSQL()
+	if err != nil {
+		return 0, fmt.Errorf("error while preparing purge posts: %w", err)
+	}
+
+	var tag pgconn.CommandTag
+	err = audited(ctx, s.db, func(tx pgx.Tx) error {
+		tag, err = tx.Exec(ctx, sql, args...)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error while purging posts: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
 // FindById returns post by ID
 func (s PostRepository) FindById(ctx context.Context, id int) (*models.Post, error) {
 	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
 
 	ds := goqu.From(goqu.T("post").As("p")).
-		Select("p.id", "p.subject", "p.body", "p.created_at", "p.updated_at", "a.id", "a.name", "a.phonenumber", "a.created_at", "a.updated_at").
+		Select("p.id", "p.subject", "p.slug", goqu.COALESCE(goqu.I("p.body"), "").As("body"), "p.created_at", "p.updated_at", "p.status", "p.publish_at", "p.version", postTagsExpr("p").As("tags"), commentCountSubquery("p").As("comment_count"), postReactionsExpr("p").As("reactions"), "a.id", "a.name", "a.phonenumber", "a.created_at", "a.updated_at").
 		Join(goqu.T("author").As("a"), goqu.On(goqu.Ex{"a.id": goqu.I("p.author_id")})).
-		Where(goqu.Ex{"p.id": id})
+		Where(goqu.Ex{"p.id": id, "p.deleted_at": nil})
 
 	sql, args, err := ds.ToSQL()
 	if err != nil {
@@ -192,7 +779,7 @@ func (s PostRepository) FindById(ctx context.Context, id int) (*models.Post, err
 	var post models.Post
 
 	if err := s.db.QueryRow(ctx, sql, args...).
-		Scan(&post.ID, &post.Subject, &post.Body, &post.CreatedAt, &post.UpdatedAt, &author.ID, &author.Name, &author.Phonenumber, &author.CreatedAt, &author.UpdatedAt); err != nil {
+		Scan(&post.ID, &post.Subject, &post.Slug, &post.Body, &post.CreatedAt, &post.UpdatedAt, &post.Status, &post.PublishAt, &post.Version, &post.Tags, &post.CommentCount, &post.Reactions, &author.ID, &author.Name, &author.Phonenumber, &author.CreatedAt, &author.UpdatedAt); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
 		}
@@ -200,7 +787,49 @@ func (s PostRepository) FindById(ctx context.Context, id int) (*models.Post, err
 		return nil, fmt.Errorf("error while find post by ID %d: %w", id, err)
 	}
 
-	post.Author = author
+	post.Author = &author
 
 	return &post, nil
 }
+
+// FindAuthorIds returns author IDs of posts selected by deleted filter by post ID, missing posts are left out
+func (s PostRepository) FindAuthorIds(ctx context.Context, ids []int, deleted filters.Deleted) (map[int]int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	authors := make(map[int]int, len(ids))
+	if len(ids) == 0 {
+		return authors, nil
+	}
+
+	ds := goqu.From(goqu.T("post").As("p")).Select("p.id", "p.author_id").Where(goqu.Ex{"p.id": ids})
+	if cond := deletedCondition("p", deleted); cond != nil {
+		ds = ds.Where(cond)
+	}
+
+	sql, args, err := ds.ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("error while preparing find post authors: %w", err)
+	}
+
+	rows, err := s.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying post authors: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, authorId int
+		if err := rows.Scan(&id, &authorId); err != nil {
+			return nil, fmt.Errorf("error while scanning post author: %w", err)
+		}
+
+		authors[id] = authorId
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error while reading post authors: %w", err)
+	}
+
+	return authors, nil
+}
@@ -2,15 +2,18 @@ package postgres_test
 
 import (
 	"context"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/go-faker/faker/v4"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"github.com/trad3r/hskills/apirest/internal/migrator"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/pagination"
 	"github.com/trad3r/hskills/apirest/internal/repository/filters"
 	"github.com/trad3r/hskills/apirest/internal/repository/postgres"
-	"github.com/trad3r/hskills/apirest/internal/storage"
 	"github.com/trad3r/hskills/apirest/internal/testutils"
 )
 
@@ -56,20 +59,90 @@ func TestPostUpdate(t *testing.T) {
 	require.NotNil(t, post)
 
 	req := filters.PostUpdateRequest{
-		Subject: faker.Sentence(),
-		Body:    faker.Paragraph(),
+		Subject: filters.Value(faker.Sentence()),
+		Body:    filters.Value(faker.Paragraph()),
 	}
 
-	err = pgRepo.Update(ctx, postID, req)
+	_, err = pgRepo.Update(ctx, postID, req)
 	require.NoError(t, err)
 
 	newPost, err := pgRepo.FindById(ctx, postID)
 	require.NoError(t, err)
-	require.Equal(t, req.Subject, newPost.Subject)
-	require.Equal(t, req.Body, newPost.Body)
+	require.Equal(t, req.Subject.Value, newPost.Subject)
+	require.Equal(t, req.Body.Value, newPost.Body)
 	require.NotEmpty(t, newPost.UpdatedAt)
 }
 
+func TestPostFindByIdVersion(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	pgRepo := getPostRepo(t)
+
+	post, err := pgRepo.FindById(ctx, 1)
+	require.NoError(t, err)
+	require.NotNil(t, post)
+	require.Equal(t, 1, post.Version)
+
+	version, err := pgRepo.Update(ctx, post.ID, filters.PostUpdateRequest{Subject: filters.Value("new subject")})
+	require.NoError(t, err)
+	require.Equal(t, 2, version)
+
+	post, err = pgRepo.FindById(ctx, post.ID)
+	require.NoError(t, err)
+	require.Equal(t, version, post.Version)
+}
+
+func TestPostUpdateClearBody(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	pgRepo := getPostRepo(t)
+
+	_, err := pgRepo.Update(ctx, 1, filters.PostUpdateRequest{
+		Body: filters.Null[string](),
+	})
+	require.NoError(t, err)
+
+	post, err := pgRepo.FindById(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, "post 1", post.Subject)
+	require.Empty(t, post.Body)
+}
+
+func TestPostUpdateIfVersion(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	pgRepo := getPostRepo(t)
+
+	post, err := pgRepo.FindById(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, 1, post.Version)
+
+	version, err := pgRepo.Update(ctx, post.ID, filters.PostUpdateRequest{
+		Subject:    filters.Value(faker.Sentence()),
+		IfVersions: []int{post.Version},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, version)
+
+	_, err = pgRepo.Update(ctx, post.ID, filters.PostUpdateRequest{
+		Subject:    filters.Value(faker.Sentence()),
+		IfVersions: []int{post.Version},
+	})
+	require.ErrorIs(t, err, custom_errors.ErrPreconditionFailed)
+
+	_, err = pgRepo.Update(ctx, 100, filters.PostUpdateRequest{
+		Subject:    filters.Value(faker.Sentence()),
+		IfVersions: []int{1},
+	})
+	require.ErrorIs(t, err, custom_errors.ErrPostNotFound)
+}
+
 func TestPostGetList(t *testing.T) {
 	t.Parallel()
 
@@ -111,12 +184,242 @@ func TestPostGetList(t *testing.T) {
 				Subject:       tc.subject,
 				Authors:       tc.users,
 			}
-			posts, err := pgRepo.GetList(ctx, filter)
+			page, err := pgRepo.GetList(ctx, filter)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedCount, len(page.Items))
+		})
+	}
+
+}
+
+func TestPostGetListSort(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	pgRepo := getPostRepo(t)
+
+	testCases := []struct {
+		name        string
+		sort        []filters.SortField
+		expectedIds []int
+	}{
+		{
+			name:        "Subject asc",
+			sort:        []filters.SortField{{Field: "subject"}},
+			expectedIds: []int{1, 2, 3, 4, 5},
+		},
+		{
+			name:        "Subject desc",
+			sort:        []filters.SortField{{Field: "subject", Desc: true}},
+			expectedIds: []int{5, 4, 3, 2, 1},
+		},
+		{
+			name:        "Created at, ID is tiebreaker",
+			sort:        []filters.SortField{{Field: "created_at"}},
+			expectedIds: []int{1, 2, 3, 4, 5},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			page, err := pgRepo.GetList(ctx, filters.PostFilter{Sort: tc.sort})
+			require.NoError(t, err)
+
+			ids := make([]int, 0, len(page.Items))
+			for _, post := range page.Items {
+				ids = append(ids, post.ID)
+			}
+
+			assert.Equal(t, tc.expectedIds, ids)
+		})
+	}
+
+	_, err := pgRepo.GetList(ctx, filters.PostFilter{Sort: []filters.SortField{{Field: "body"}}})
+	assert.Error(t, err)
+}
+
+func TestPostGetListSearch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	pgRepo := getPostRepo(t)
+
+	testCases := []struct {
+		name        string
+		filter      filters.PostFilter
+		expectedIds []int
+	}{
+		{
+			name:        "Websearch query",
+			filter:      filters.PostFilter{Query: "post 3"},
+			expectedIds: []int{3},
+		},
+		{
+			name:        "Excluded word",
+			filter:      filters.PostFilter{Query: "body -1 -2 -3", Sort: []filters.SortField{{Field: "id"}}},
+			expectedIds: []int{4, 5},
+		},
+		{
+			name:        "Subject",
+			filter:      filters.PostFilter{Subject: "post 2"},
+			expectedIds: []int{2},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			page, err := pgRepo.GetList(ctx, tc.filter)
 			require.NoError(t, err)
-			require.Equal(t, tc.expectedCount, len(posts))
+
+			ids := make([]int, 0, len(page.Items))
+			for _, post := range page.Items {
+				ids = append(ids, post.ID)
+
+				if len(tc.filter.Query) > 0 {
+					assert.Positive(t, post.Rank)
+					assert.Contains(t, post.Snippet, "<mark>")
+				}
+			}
+
+			assert.Equal(t, tc.expectedIds, ids)
 		})
 	}
+}
+
+func TestPostSearchConfig(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	db := testutils.PrepareDB(t)
+	pgRepo := postgres.NewPostRepository(db)
+
+	post := &models.Post{Subject: "running", Body: "fast", Author: &models.User{ID: 1}}
+	require.NoError(t, pgRepo.Add(ctx, post))
+
+	search := func(query string) []int {
+		page, err := pgRepo.GetList(ctx, filters.PostFilter{Query: query})
+		require.NoError(t, err)
+
+		ids := make([]int, 0, len(page.Items))
+		for _, p := range page.Items {
+			ids = append(ids, p.ID)
+		}
+
+		return ids
+	}
+
+	// default configuration stems words, simple one does not
+	assert.Equal(t, []int{post.ID}, search("runs"))
+
+	require.NoError(t, postgres.ConfigureSearch(ctx, db, "simple"))
+	assert.Empty(t, search("runs"))
+	assert.Equal(t, []int{post.ID}, search("running"))
+
+	require.NoError(t, postgres.ConfigureSearch(ctx, db, "simple"), "unchanged configuration is kept")
+	assert.Error(t, postgres.ConfigureSearch(ctx, db, "klingon"))
+}
+
+func TestPostGetListSearchEscapesSnippet(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	pgRepo := getPostRepo(t)
+
+	post := &models.Post{Subject: "escaped", Body: "<script>alert(1)</script> \ue000hidden\ue001 markup", Author: &models.User{ID: 1}}
+	require.NoError(t, pgRepo.Add(ctx, post))
+
+	page, err := pgRepo.GetList(ctx, filters.PostFilter{Query: "markup"})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+
+	snippet := page.Items[0].Snippet
+	assert.Contains(t, snippet, "&lt;script&gt;")
+	assert.Contains(t, snippet, "<mark>markup</mark>")
+	assert.NotContains(t, snippet, "<script>")
+	assert.NotContains(t, snippet, "<mark>hidden</mark>")
+}
+
+func TestPostExport(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	pgRepo := getPostRepo(t)
+
+	var ids []int
+	err := pgRepo.Export(ctx, filters.PostFilter{Limit: 1, Sort: []filters.SortField{{Field: "id"}}}, func(post models.Post) error {
+		ids = append(ids, post.ID)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, ids)
+}
+
+func TestPostGetListProjection(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	pgRepo := getPostRepo(t)
+
+	page, err := pgRepo.GetList(ctx, filters.PostFilter{
+		Authors:    []int{1},
+		Projection: filters.Projection{Fields: []string{"subject"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 2)
+
+	for _, post := range page.Items {
+		assert.NotEmpty(t, post.Subject)
+		assert.Empty(t, post.Body)
+		assert.Nil(t, post.Author)
+	}
 
+	page, err = pgRepo.GetList(ctx, filters.PostFilter{
+		Authors:    []int{1},
+		Projection: filters.Projection{Include: []string{"author"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 2)
+	require.NotNil(t, page.Items[0].Author)
+	assert.Equal(t, "John", page.Items[0].Author.Name)
+}
+
+func TestPostGetListCursor(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	pgRepo := getPostRepo(t)
+
+	first, err := pgRepo.GetList(ctx, filters.PostFilter{Limit: 2, WithTotal: true})
+	require.NoError(t, err)
+	require.Len(t, first.Items, 2)
+	require.NotEmpty(t, first.Next)
+	require.Empty(t, first.Prev)
+	require.NotNil(t, first.Total)
+	require.Equal(t, 5, *first.Total)
+
+	cursor, err := pagination.Decode(first.Next)
+	require.NoError(t, err)
+
+	second, err := pgRepo.GetList(ctx, filters.PostFilter{Limit: 2, Cursor: cursor})
+	require.NoError(t, err)
+	require.Len(t, second.Items, 2)
+	require.NotEqual(t, first.Items[1].ID, second.Items[0].ID)
+	require.NotEmpty(t, second.Prev)
+
+	cursor, err = pagination.Decode(second.Prev)
+	require.NoError(t, err)
+
+	back, err := pgRepo.GetList(ctx, filters.PostFilter{Limit: 2, Cursor: cursor})
+	require.NoError(t, err)
+	require.Equal(t, first.Items, back.Items)
+	require.Empty(t, back.Prev)
 }
 
 func TestPostDelete(t *testing.T) {
@@ -138,17 +441,277 @@ func TestPostDelete(t *testing.T) {
 	require.Nil(t, post)
 }
 
-func getPostRepo(t *testing.T) postgres.IPostRepository {
-	dsn := testutils.PreparePostgres(t)
-	err := migrator.ApplyPostgresMigrations("../../../migrations", dsn)
+func TestPostFindAuthorIds(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	pgRepo := getPostRepo(t)
+
+	require.NoError(t, pgRepo.Delete(ctx, 2))
+
+	authors, err := pgRepo.FindAuthorIds(ctx, []int{1, 2, 3, 1000}, filters.DeletedExclude)
+	require.NoError(t, err)
+	assert.Equal(t, map[int]int{1: 1, 3: 2}, authors)
+
+	authors, err = pgRepo.FindAuthorIds(ctx, []int{1, 2, 3, 1000}, filters.DeletedOnly)
+	require.NoError(t, err)
+	assert.Equal(t, map[int]int{2: 1}, authors)
+}
+
+func TestPostBulkAdd(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	pgRepo := getPostRepo(t)
+
+	posts := []*models.Post{
+		{Subject: faker.Sentence(), Body: faker.Paragraph(), Author: &models.User{ID: 1}},
+		{Subject: faker.Sentence(), Body: faker.Paragraph(), Author: &models.User{ID: 2}},
+	}
+
+	errs, err := pgRepo.BulkAdd(ctx, posts, true)
+	require.NoError(t, err)
+	assert.Equal(t, []error{nil, nil}, errs)
+
+	for _, post := range posts {
+		require.NotEmpty(t, post.ID)
+
+		dbPost, err := pgRepo.FindById(ctx, post.ID)
+		require.NoError(t, err)
+		require.NotNil(t, dbPost)
+		assert.Equal(t, post.Subject, dbPost.Subject)
+		assert.Equal(t, post.Author.ID, dbPost.Author.ID)
+	}
+
+	// posts following a rejected one are still added in partial mode
+	posts = []*models.Post{
+		{Subject: faker.Sentence(), Author: &models.User{ID: 1}},
+		{Subject: faker.Sentence(), Author: &models.User{ID: 100000}},
+		{Subject: faker.Sentence(), Author: &models.User{ID: 2}},
+		{Subject: faker.Sentence(), Author: &models.User{ID: 100001}},
+	}
+
+	errs, err = pgRepo.BulkAdd(ctx, posts, false)
+	require.NoError(t, err)
+	require.Len(t, errs, len(posts))
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], custom_errors.ErrConflict)
+	assert.NoError(t, errs[2])
+	assert.ErrorIs(t, errs[3], custom_errors.ErrConflict)
+
+	for _, post := range []*models.Post{posts[0], posts[2]} {
+		dbPost, err := pgRepo.FindById(ctx, post.ID)
+		require.NoError(t, err)
+		require.NotNil(t, dbPost)
+		assert.Equal(t, post.Subject, dbPost.Subject)
+	}
+}
+
+func TestPostBulkUpdate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	testCases := []struct {
+		name    string
+		atomic  bool
+		errs    []error
+		updated bool
+	}{
+		{
+			name:    "Atomic rolls back",
+			atomic:  true,
+			errs:    []error{custom_errors.ErrBulkAborted, custom_errors.ErrPostNotFound},
+			updated: false,
+		},
+		{
+			name:    "Partial keeps successful",
+			atomic:  false,
+			errs:    []error{nil, custom_errors.ErrPostNotFound},
+			updated: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pgRepo := getPostRepo(t)
+
+			subject := faker.Sentence()
+			items := []filters.PostBulkUpdateItem{
+				{ID: 1, PostUpdateRequest: filters.PostUpdateRequest{Subject: filters.Value(subject)}},
+				{ID: 1000, PostUpdateRequest: filters.PostUpdateRequest{Subject: filters.Value(subject)}},
+			}
+
+			errs, err := pgRepo.BulkUpdate(ctx, items, tc.atomic)
+			require.NoError(t, err)
+			require.Len(t, errs, len(tc.errs))
+
+			for i := range tc.errs {
+				assert.ErrorIs(t, errs[i], tc.errs[i])
+			}
+
+			post, err := pgRepo.FindById(ctx, 1)
+			require.NoError(t, err)
+			require.NotNil(t, post)
+			assert.Equal(t, tc.updated, post.Subject == subject)
+		})
+	}
+}
+
+func TestPostBulkDelete(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	pgRepo := getPostRepo(t)
+
+	testCases := []struct {
+		name    string
+		atomic  bool
+		errs    []error
+		deleted bool
+	}{
+		{
+			name:    "Atomic rolls back",
+			atomic:  true,
+			errs:    []error{custom_errors.ErrBulkAborted, custom_errors.ErrPostNotFound},
+			deleted: false,
+		},
+		{
+			name:    "Partial keeps successful",
+			atomic:  false,
+			errs:    []error{nil, custom_errors.ErrPostNotFound},
+			deleted: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			errs, err := pgRepo.BulkDelete(ctx, []int{5, 100}, tc.atomic)
+			require.NoError(t, err)
+			require.Len(t, errs, len(tc.errs))
+
+			for i := range tc.errs {
+				assert.ErrorIs(t, errs[i], tc.errs[i])
+			}
+
+			post, err := pgRepo.FindById(ctx, 5)
+			require.NoError(t, err)
+			assert.Equal(t, tc.deleted, post == nil)
+		})
+	}
+}
+
+func TestPostRevisions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	pgRepo := getPostRepo(t)
+
+	post := &models.Post{Subject: "first", Body: "line 1\nline 2", Author: &models.User{ID: 1}}
+	err := pgRepo.Add(ctx, post)
+	require.NoError(t, err)
+
+	_, err = pgRepo.Update(ctx, post.ID, filters.PostUpdateRequest{Subject: filters.Value("second")})
+	require.NoError(t, err)
+
+	version, err := pgRepo.RestoreRevision(ctx, post.ID, 1, nil)
+	require.NoError(t, err)
+	assert.NotEmpty(t, version)
+
+	page, err := pgRepo.Revisions(ctx, post.ID, filters.RevisionFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 3)
+	assert.Equal(t, 3, page.Items[0].Revision)
+	assert.Equal(t, "first", page.Items[0].Subject)
+	assert.Equal(t, "second", page.Items[1].Subject)
+	assert.Equal(t, "line 1\nline 2", page.Items[1].Body)
+
+	_, err = pgRepo.RestoreRevision(ctx, post.ID, 10, nil)
+	require.ErrorIs(t, err, custom_errors.ErrRevisionNotFound)
+
+	_, err = pgRepo.RestoreRevision(ctx, post.ID, 1, []int{version - 1})
+	require.ErrorIs(t, err, custom_errors.ErrPreconditionFailed)
+}
+
+func TestPostTags(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	pgRepo := getPostRepo(t)
+
+	post := &models.Post{Subject: faker.Sentence(), Tags: []string{"go", "db"}, Author: &models.User{ID: 1}}
+	err := pgRepo.Add(ctx, post)
 	require.NoError(t, err)
 
-	err = testutils.RunFixtures("../../../fixtures", dsn)
+	dbPost, err := pgRepo.FindById(ctx, post.ID)
 	require.NoError(t, err)
+	assert.Equal(t, []string{"db", "go"}, dbPost.Tags)
 
-	db, err := storage.NewDB(context.Background(), dsn)
+	_, err = pgRepo.Update(ctx, post.ID, filters.PostUpdateRequest{Tags: filters.Value([]string{"db", "sql"})})
 	require.NoError(t, err)
 
+	testCases := []struct {
+		name        string
+		tags        []string
+		all         bool
+		expectedIds []int
+	}{
+		{name: "Any tag", tags: []string{"go", "sql"}, expectedIds: []int{post.ID}},
+		{name: "All tags", tags: []string{"db", "sql"}, all: true, expectedIds: []int{post.ID}},
+		{name: "Removed tag", tags: []string{"go"}, expectedIds: []int{}},
+		{name: "Not all tags", tags: []string{"db", "go"}, all: true, expectedIds: []int{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			page, err := pgRepo.GetList(ctx, filters.PostFilter{Limit: 10, Tags: tc.tags, TagsAll: tc.all})
+			require.NoError(t, err)
+
+			ids := make([]int, 0, len(page.Items))
+			for _, p := range page.Items {
+				ids = append(ids, p.ID)
+			}
+
+			assert.Equal(t, tc.expectedIds, ids)
+		})
+	}
+}
+
+func TestPostImportDeleted(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	pgRepo := getPostRepo(t)
+
+	items := []filters.PostImportItem{{ExternalID: "cms-trash", Subject: "Trashed", Body: "body", Author: 1}}
+
+	results, err := pgRepo.Import(ctx, items, true)
+	require.NoError(t, err)
+	require.NoError(t, results[0].Err)
+	id := results[0].ID
+
+	require.NoError(t, pgRepo.Delete(ctx, id))
+
+	items[0].Subject = "Reimported"
+	results, err = pgRepo.Import(ctx, items, false)
+	require.NoError(t, err)
+	assert.ErrorIs(t, results[0].Err, custom_errors.ErrConflict)
+	assert.Zero(t, results[0].ID)
+
+	post, err := pgRepo.FindById(ctx, id)
+	require.NoError(t, err)
+	assert.Nil(t, post, "deleted post stays in trash")
+}
+
+func getPostRepo(t *testing.T) postgres.IPostRepository {
+	db := testutils.PrepareDB(t)
+
 	return postgres.NewPostRepository(db)
 }
 
@@ -169,3 +732,100 @@ func getPostRepo(t *testing.T) postgres.IPostRepository {
 //	}
 //}
 //
+
+func TestPostLifecycle(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	pgRepo := getPostRepo(t)
+
+	publishAt := time.Now().Add(time.Hour)
+	post := &models.Post{Subject: faker.Sentence(), Status: models.PostStatusScheduled, PublishAt: &publishAt, Author: &models.User{ID: 1}}
+	require.NoError(t, pgRepo.Add(ctx, post))
+
+	published := []string{models.PostStatusPublished}
+	isListed := func() bool {
+		page, err := pgRepo.GetList(ctx, filters.PostFilter{Limit: 100, Statuses: published, Authors: []int{1}})
+		require.NoError(t, err)
+
+		return slices.ContainsFunc(page.Items, func(p models.Post) bool { return p.ID == post.ID })
+	}
+
+	assert.False(t, isListed())
+
+	_, err := pgRepo.Transition(ctx, post.ID, filters.PostTransition{From: published, To: models.PostStatusArchived})
+	assert.ErrorIs(t, err, custom_errors.ErrTransitionNotAllowed)
+
+	// scheduler catches up with posts due while it was not running
+	count, err := pgRepo.PublishDue(ctx, publishAt.Add(time.Minute))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, count, int64(1))
+	assert.True(t, isListed())
+
+	dbPost, err := pgRepo.FindById(ctx, post.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, dbPost.UpdatedAt)
+
+	version, err := pgRepo.Transition(ctx, post.ID, filters.PostTransition{From: published, To: models.PostStatusDraft})
+	require.NoError(t, err)
+	assert.False(t, isListed())
+
+	_, err = pgRepo.Transition(ctx, post.ID, filters.PostTransition{From: []string{models.PostStatusDraft}, To: models.PostStatusPublished, IfVersions: []int{version - 1}})
+	assert.ErrorIs(t, err, custom_errors.ErrPreconditionFailed)
+
+	dbPost, err = pgRepo.FindById(ctx, post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.PostStatusDraft, dbPost.Status)
+	assert.Nil(t, dbPost.PublishAt)
+}
+
+func TestPostSlugs(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	pgRepo := getPostRepo(t)
+
+	first := &models.Post{Subject: "Новости недели", Author: &models.User{ID: 1}}
+	require.NoError(t, pgRepo.Add(ctx, first))
+
+	second := &models.Post{Subject: "Новости  недели!", Author: &models.User{ID: 2}}
+	require.NoError(t, pgRepo.Add(ctx, second))
+
+	findSlug := func(id int) string {
+		post, err := pgRepo.FindById(ctx, id)
+		require.NoError(t, err)
+
+		return post.Slug
+	}
+
+	assert.Equal(t, "novosti-nedeli", findSlug(first.ID))
+	assert.Equal(t, "novosti-nedeli-2", findSlug(second.ID))
+
+	_, err := pgRepo.Update(ctx, first.ID, filters.PostUpdateRequest{Subject: filters.Field[string]{Set: true, Value: "Итоги недели"}})
+	require.NoError(t, err)
+	assert.Equal(t, "itogi-nedeli", findSlug(first.ID))
+
+	// former slug still resolves and is not given to other posts
+	id, err := pgRepo.FindIdBySlug(ctx, "novosti-nedeli")
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, id)
+
+	third := &models.Post{Subject: "Новости недели", Author: &models.User{ID: 1}}
+	require.NoError(t, pgRepo.Add(ctx, third))
+	assert.Equal(t, "novosti-nedeli-3", findSlug(third.ID))
+
+	// unchanged subject keeps the slug
+	_, err = pgRepo.Update(ctx, second.ID, filters.PostUpdateRequest{Subject: filters.Field[string]{Set: true, Value: "Новости  недели!"}})
+	require.NoError(t, err)
+	assert.Equal(t, "novosti-nedeli-2", findSlug(second.ID))
+
+	id, err = pgRepo.FindIdBySlug(ctx, "post-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, id)
+
+	id, err = pgRepo.FindIdBySlug(ctx, "missing-slug")
+	require.NoError(t, err)
+	assert.Zero(t, id)
+}
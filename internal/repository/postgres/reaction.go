@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type IReactionRepository interface {
+	Put(ctx context.Context, postID int, userID int, reactionType string) (map[string]int, error)
+	Delete(ctx context.Context, postID int, userID int, reactionType string) (map[string]int, error)
+}
+
+type ReactionRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewReactionRepository(db *pgxpool.Pool) IReactionRepository {
+	return ReactionRepository{
+		db: db,
+	}
+}
+
+// Put adds user reaction to post, repeated reaction is ignored. Returns post reaction counts
+func (s ReactionRepository) Put(ctx context.Context, postID int, userID int, reactionType string) (map[string]int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	sql, args, err := goqu.Insert("reaction").
+		Rows(goqu.Record{"post_id": postID, "user_id": userID, "type": reactionType}).
+		OnConflict(goqu.DoNothing()).
+		ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("error while preparing put reaction: %w", err)
+	}
+
+	return s.write(ctx, postID, sql, args)
+}
+
+// Delete removes user reaction from post, missing reaction is ignored. Returns post reaction counts
+func (s ReactionRepository) Delete(ctx context.Context, postID int, userID int, reactionType string) (map[string]int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	sql, args, err := goqu.Delete("reaction").
+		Where(goqu.Ex{"post_id": postID, "user_id": userID, "type": reactionType}).
+		ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("error while preparing delete reaction: %w", err)
+	}
+
+	return s.write(ctx, postID, sql, args)
+}
+
+// write runs reaction write and reads counts changed by its trigger in the same transaction
+func (s ReactionRepository) write(ctx context.Context, postID int, sql string, args []any) (map[string]int, error) {
+	countsSql, countsArgs, err := goqu.From(goqu.T("post").As("p")).
+		Select(postReactionsExpr("p")).
+		Where(goqu.Ex{"p.id": postID}).
+		ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("error while preparing post reactions: %w", err)
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error while starting reaction write: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return nil, fmt.Errorf("error while writing reaction: %w", mapPgError(err))
+	}
+
+	var counts map[string]int
+	if err := tx.QueryRow(ctx, countsSql, countsArgs...).Scan(&counts); err != nil {
+		return nil, fmt.Errorf("error while reading post reactions: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error while committing reaction write: %w", err)
+	}
+
+	return counts, nil
+}
+
+// postReactionsExpr is a JSON object of non zero reaction counts of the post with table alias
+func postReactionsExpr(alias string) exp.LiteralExpression {
+	return goqu.L("COALESCE(?, '{}'::jsonb)", goqu.From(goqu.T("post_reaction_count").As("rc")).
+		Select(goqu.L("jsonb_object_agg(rc.type, rc.count)")).
+		Where(goqu.Ex{"rc.post_id": goqu.I(alias + ".id")}, goqu.I("rc.count").Gt(0)))
+}
@@ -0,0 +1,78 @@
+package postgres_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/go-faker/faker/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/pagination"
+	"github.com/trad3r/hskills/apirest/internal/repository/filters"
+	"github.com/trad3r/hskills/apirest/internal/repository/postgres"
+	"github.com/trad3r/hskills/apirest/internal/testutils"
+)
+
+func TestPostReactions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	postRepo, reactionRepo := getReactionRepos(t)
+
+	liked := &models.Post{Subject: faker.Sentence(), Author: &models.User{ID: 1}}
+	require.NoError(t, postRepo.Add(ctx, liked))
+
+	// every user likes the post twice concurrently, repeated reaction is not counted
+	var wg sync.WaitGroup
+	for userID := 1; userID <= 5; userID++ {
+		for range 2 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := reactionRepo.Put(ctx, liked.ID, userID, models.ReactionLike)
+				assert.NoError(t, err)
+			}()
+		}
+	}
+	wg.Wait()
+
+	counts, err := reactionRepo.Put(ctx, liked.ID, 1, models.ReactionWow)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{models.ReactionLike: 5, models.ReactionWow: 1}, counts)
+
+	counts, err = reactionRepo.Delete(ctx, liked.ID, 1, models.ReactionWow)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{models.ReactionLike: 5}, counts)
+
+	counts, err = reactionRepo.Delete(ctx, liked.ID, 1, models.ReactionWow)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{models.ReactionLike: 5}, counts)
+
+	dbPost, err := postRepo.FindById(ctx, liked.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 5, dbPost.Reactions[models.ReactionLike])
+
+	page, err := postRepo.GetList(ctx, filters.PostFilter{Limit: 1, Sort: []filters.SortField{{Field: "likes", Desc: true}}})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, liked.ID, page.Items[0].ID)
+
+	// posts without likes follow on the next page
+	cursor, err := pagination.Decode(page.Next)
+	require.NoError(t, err)
+
+	next, err := postRepo.GetList(ctx, filters.PostFilter{Limit: 1, Sort: []filters.SortField{{Field: "likes", Desc: true}}, Cursor: cursor})
+	require.NoError(t, err)
+	require.Len(t, next.Items, 1)
+	assert.NotEqual(t, liked.ID, next.Items[0].ID)
+	assert.Zero(t, next.Items[0].Reactions[models.ReactionLike])
+}
+
+func getReactionRepos(t *testing.T) (postgres.IPostRepository, postgres.IReactionRepository) {
+	db := testutils.PrepareDB(t)
+
+	return postgres.NewPostRepository(db), postgres.NewReactionRepository(db)
+}
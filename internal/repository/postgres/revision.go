@@ -0,0 +1,163 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/hashicorp/go-multierror"
+	"github.com/jackc/pgx/v5"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/repository/filters"
+)
+
+// revisionSortColumns orders revisions by number, it is the unique key of a post revision
+var revisionSortColumns = map[string]sortColumn[models.PostRevision]{
+	"id": {expr: goqu.I("revision"), value: func(r models.PostRevision) any { return r.Revision }},
+}
+
+func revisionSelect() *goqu.SelectDataset {
+	return goqu.From("post_revision").
+		Select("post_id", "revision", goqu.COALESCE(goqu.I("subject"), "").As("subject"), goqu.COALESCE(goqu.I("body"), "").As("body"), "actor", "created_at")
+}
+
+func scanRevision(row pgx.Row) (models.PostRevision, error) {
+	var r models.PostRevision
+	err := row.Scan(&r.PostID, &r.Revision, &r.Subject, &r.Body, &r.Actor, &r.CreatedAt)
+
+	return r, err
+}
+
+// Revisions returns page of post revisions, newest first
+func (s PostRepository) Revisions(ctx context.Context, postID int, filter filters.RevisionFilter) (*models.Page[models.PostRevision], error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	keys, cursorValues, err := sortKeys(revisionSortColumns, []filters.SortField{{Field: "id", Desc: true}})
+	if err != nil {
+		return nil, err
+	}
+
+	ds := revisionSelect().
+		Where(goqu.C("post_id").Eq(postID)).
+		Order(orderBy(keys, filter.Cursor != nil && filter.Cursor.Backward)...).
+		Limit(uint(filter.Limit + 1))
+
+	if filter.Cursor != nil {
+		if err := checkCursor(keys, filter.Cursor); err != nil {
+			return nil, err
+		}
+
+		ds = ds.Where(keysetCondition(keys, filter.Cursor))
+	}
+
+	sql, args, err := ds.ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("error while creating sql: %w", err)
+	}
+
+	rows, err := s.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying post revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var errs error
+	revisions := make([]models.PostRevision, 0, filter.Limit+1)
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			errs = multierror.Append(errs, err)
+			continue
+		}
+
+		revisions = append(revisions, revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+
+	if errs != nil {
+		return nil, errs
+	}
+
+	return newPage(revisions, filter.Limit, 0, keys, filter.Cursor, cursorValues), nil
+}
+
+// Revision returns post revision by number, nil if it is not found
+func (s PostRepository) Revision(ctx context.Context, postID int, revision int) (*models.PostRevision, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	sql, args, err := revisionSelect().Where(goqu.Ex{"post_id": postID, "revision": revision}).ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("error while preparing find post revision: %w", err)
+	}
+
+	r, err := scanRevision(s.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error while find post %d revision %d: %w", postID, revision, err)
+	}
+
+	return &r, nil
+}
+
+// RestoreRevision copies subject and body of the revision to post, which saves them as a new revision.
+// Slug follows the restored subject. It returns new post version
+func (s PostRepository) RestoreRevision(ctx context.Context, postID int, revision int, ifVersions []int) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	// revisions never change, so slug is made of the subject read before update
+	r, err := s.Revision(ctx, postID, revision)
+	if err != nil {
+		return 0, err
+	}
+
+	if r == nil {
+		return 0, custom_errors.ErrRevisionNotFound
+	}
+
+	wheres := goqu.Ex{"post.id": postID, "post.deleted_at": nil, "r.post_id": goqu.I("post.id"), "r.revision": revision}
+	if len(ifVersions) > 0 {
+		wheres["post.version"] = ifVersions
+	}
+
+	sql, args, err := goqu.Update("post").
+		Set(goqu.Record{
+			"subject":    goqu.I("r.subject"),
+			"slug":       postSlug(r.Subject, postID),
+			"body":       goqu.I("r.body"),
+			"updated_at": time.Now(),
+			"version":    goqu.L("post.version + 1"),
+		}).
+		From(goqu.T("post_revision").As("r")).
+		Where(wheres).
+		Returning("post.version").
+		ToSQL()
+	if err != nil {
+		return 0, fmt.Errorf("error while preparing restore post revision: %w", err)
+	}
+
+	var version int
+	err = audited(ctx, s.db, func(tx pgx.Tx) error {
+		return tx.QueryRow(ctx, sql, args...).Scan(&version)
+	})
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return 0, fmt.Errorf("error while restoring post revision: %w", mapPgError(err))
+		}
+
+		return 0, missingRowError(ctx, s.db, "post", postID, custom_errors.ErrPostNotFound)
+	}
+
+	return version, nil
+}
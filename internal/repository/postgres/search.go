@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// postSearchColumn is the post search column definition, see migration 00018
+const postSearchColumn = `ALTER TABLE post ADD COLUMN search tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector(post_search_config(), coalesce(subject, '')), 'A') ||
+    setweight(to_tsvector(post_search_config(), coalesce(body, '')), 'B')
+) STORED`
+
+// ConfigureSearch sets text search configuration of post search, e.g. russian or english.
+// Stored search column doesn't follow the configuration by itself, so it is rebuilt when configuration changes
+func ConfigureSearch(ctx context.Context, db *pgxpool.Pool, config string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	changed, err := searchConfigChanged(ctx, db, config)
+	if err != nil || !changed {
+		return err
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	// other instances starting at the same time wait here and find configuration changed already
+	if _, err := tx.Exec(ctx, "LOCK TABLE post IN ACCESS EXCLUSIVE MODE"); err != nil {
+		return fmt.Errorf("error while locking posts: %w", err)
+	}
+
+	changed, err = searchConfigChanged(ctx, tx, config)
+	if err != nil || !changed {
+		return err
+	}
+
+	var function string
+	err = tx.QueryRow(ctx, "SELECT format('CREATE OR REPLACE FUNCTION post_search_config() RETURNS regconfig LANGUAGE sql IMMUTABLE AS %L', format('SELECT %L::regconfig', $1::text::regconfig::text))", config).
+		Scan(&function)
+	if err != nil {
+		return fmt.Errorf("error while preparing search configuration %s: %w", config, err)
+	}
+
+	for _, sql := range []string{
+		function,
+		"ALTER TABLE post DROP COLUMN search",
+		postSearchColumn,
+		"CREATE INDEX post_search_idx ON post USING GIN (search)",
+	} {
+		if _, err := tx.Exec(ctx, sql); err != nil {
+			return fmt.Errorf("error while rebuilding post search: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error while committing transaction: %w", err)
+	}
+
+	return nil
+}
+
+// queryRower is a pool or a transaction
+type queryRower interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// searchConfigChanged reports whether config differs from current one, unknown config is an error
+func searchConfigChanged(ctx context.Context, db queryRower, config string) (bool, error) {
+	var changed bool
+	if err := db.QueryRow(ctx, "SELECT post_search_config() <> $1::text::regconfig", config).Scan(&changed); err != nil {
+		return false, fmt.Errorf("error while checking search configuration %s: %w", config, err)
+	}
+
+	return changed, nil
+}
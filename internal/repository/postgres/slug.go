@@ -0,0 +1,54 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/jackc/pgx/v5"
+	"github.com/trad3r/hskills/apirest/internal/slug"
+)
+
+// postSlug allocates unique slug of subject for post owner, owner is nil for new posts.
+// See migration 00014 for allocation and history of slugs
+func postSlug(subject string, owner any) exp.SQLFunctionExpression {
+	base := slug.Make(subject)
+	if len(base) == 0 {
+		base = "post"
+	}
+
+	return goqu.Func("post_slug_allocate", base, owner)
+}
+
+// FindIdBySlug returns ID of live post by its current or former slug, 0 when there is no such post
+func (s PostRepository) FindIdBySlug(ctx context.Context, postSlug string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	former := goqu.From("post_slug").Select("post_id").Where(goqu.Ex{"slug": postSlug})
+
+	sql, args, err := goqu.From("post").
+		Select("id").
+		Where(
+			goqu.Ex{"deleted_at": nil},
+			goqu.Or(goqu.Ex{"slug": postSlug}, goqu.C("id").Eq(former)),
+		).
+		ToSQL()
+	if err != nil {
+		return 0, fmt.Errorf("error while preparing find post by slug: %w", err)
+	}
+
+	var id int
+	if err := s.db.QueryRow(ctx, sql, args...).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+
+		return 0, fmt.Errorf("error while find post by slug %q: %w", postSlug, err)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,29 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkSnippet(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		snippet  string
+		expected string
+	}{
+		{name: "Plain text", snippet: "plain text", expected: "plain text"},
+		{name: "Match", snippet: "found " + snippetStart + "word" + snippetStop + " here", expected: "found <mark>word</mark> here"},
+		{name: "Markup is escaped", snippet: `<b onclick="x">` + snippetStart + "bold" + snippetStop + "</b> & more", expected: "&lt;b onclick=&#34;x&#34;&gt;<mark>bold</mark>&lt;/b&gt; &amp; more"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, markSnippet(tc.snippet))
+		})
+	}
+}
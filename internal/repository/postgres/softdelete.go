@@ -0,0 +1,24 @@
+package postgres
+
+import (
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/trad3r/hskills/apirest/internal/repository/filters"
+)
+
+// notDeleted is a condition of live rows of table alias
+func notDeleted(alias string) exp.Expression {
+	return goqu.I(alias + ".deleted_at").IsNull()
+}
+
+// deletedCondition returns condition of deleted filter, nil means every row
+func deletedCondition(alias string, deleted filters.Deleted) exp.Expression {
+	switch deleted {
+	case filters.DeletedInclude:
+		return nil
+	case filters.DeletedOnly:
+		return goqu.I(alias + ".deleted_at").IsNotNull()
+	}
+
+	return notDeleted(alias)
+}
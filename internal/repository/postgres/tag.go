@@ -0,0 +1,248 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/hashicorp/go-multierror"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/repository/filters"
+)
+
+type ITagRepository interface {
+	GetList(ctx context.Context, filter filters.TagFilter) ([]models.Tag, error)
+	Rename(ctx context.Context, name string, newName string) error
+	Merge(ctx context.Context, name string, into string) error
+}
+
+type TagRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTagRepository(db *pgxpool.Pool) ITagRepository {
+	return TagRepository{
+		db: db,
+	}
+}
+
+// GetList returns tags with amount of live posts, most used first
+func (s TagRepository) GetList(ctx context.Context, filter filters.TagFilter) ([]models.Tag, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	postCount := goqu.COUNT(goqu.I("p.id"))
+
+	ds := goqu.From(goqu.T("tag").As("t")).
+		Select("t.name", postCount.As("post_count")).
+		LeftJoin(goqu.T("post_tag").As("pt"), goqu.On(goqu.Ex{"pt.tag_id": goqu.I("t.id")})).
+		LeftJoin(goqu.T("post").As("p"), goqu.On(goqu.Ex{"p.id": goqu.I("pt.post_id"), "p.deleted_at": nil, "p.status": models.PostStatusPublished})).
+		GroupBy("t.id").
+		Order(postCount.Desc(), goqu.I("t.name").Asc()).
+		Limit(uint(filter.Limit))
+
+	if len(filter.Prefix) > 0 {
+		ds = ds.Where(goqu.I("t.name").Like(escapeLike(filter.Prefix) + "%"))
+	}
+
+	sql, args, err := ds.ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("error while creating sql: %w", err)
+	}
+
+	rows, err := s.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying tags: %w", err)
+	}
+	defer rows.Close()
+
+	var errs error
+	tags := make([]models.Tag, 0, filter.Limit)
+	for rows.Next() {
+		var tag models.Tag
+		if err := rows.Scan(&tag.Name, &tag.PostCount); err != nil {
+			errs = multierror.Append(errs, err)
+			continue
+		}
+
+		tags = append(tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+
+	if errs != nil {
+		return nil, errs
+	}
+
+	return tags, nil
+}
+
+// Rename changes tag name, name of another tag is a conflict. Versions of tagged posts are bumped
+func (s TagRepository) Rename(ctx context.Context, name string, newName string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	sql, args, err := goqu.Update("tag").Set(goqu.Record{"name": newName}).Where(goqu.Ex{"name": name}).Returning("id").ToSQL()
+	if err != nil {
+		return fmt.Errorf("error while preparing rename tag: %w", err)
+	}
+
+	return audited(ctx, s.db, func(tx pgx.Tx) error {
+		var id int
+		if err := tx.QueryRow(ctx, sql, args...).Scan(&id); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return custom_errors.ErrTagNotFound
+			}
+
+			return fmt.Errorf("error while renaming tag: %w", mapPgError(err))
+		}
+
+		return bumpTaggedPosts(ctx, tx, id)
+	})
+}
+
+// Merge moves posts of tag name to tag into and removes tag name. Versions of moved posts are bumped
+func (s TagRepository) Merge(ctx context.Context, name string, into string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	return audited(ctx, s.db, func(tx pgx.Tx) error {
+		sql, args, err := goqu.From("tag").Select("id", "name").Where(goqu.C("name").In(name, into)).ForUpdate(exp.Wait).ToSQL()
+		if err != nil {
+			return fmt.Errorf("error while preparing merge tags: %w", err)
+		}
+
+		rows, err := tx.Query(ctx, sql, args...)
+		if err != nil {
+			return fmt.Errorf("error while find tags: %w", err)
+		}
+
+		ids := make(map[string]int, 2)
+		for rows.Next() {
+			var id int
+			var tagName string
+			if err := rows.Scan(&id, &tagName); err != nil {
+				rows.Close()
+				return fmt.Errorf("error while scanning tags: %w", err)
+			}
+
+			ids[tagName] = id
+		}
+		rows.Close()
+
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error while reading tags: %w", err)
+		}
+
+		if len(ids) != 2 {
+			return custom_errors.ErrTagNotFound
+		}
+
+		if err := bumpTaggedPosts(ctx, tx, ids[name]); err != nil {
+			return err
+		}
+
+		sql, args, err = goqu.Insert("post_tag").
+			Cols("post_id", "tag_id").
+			FromQuery(goqu.From("post_tag").Select("post_id", goqu.V(ids[into])).Where(goqu.C("tag_id").Eq(ids[name]))).
+			OnConflict(goqu.DoNothing()).
+			ToSQL()
+		if err != nil {
+			return fmt.Errorf("error while preparing merge tags: %w", err)
+		}
+
+		if _, err := tx.Exec(ctx, sql, args...); err != nil {
+			return fmt.Errorf("error while merging tags: %w", err)
+		}
+
+		sql, args, err = goqu.Delete("tag").Where(goqu.C("id").Eq(ids[name])).ToSQL()
+		if err != nil {
+			return fmt.Errorf("error while preparing delete tag: %w", err)
+		}
+
+		if _, err := tx.Exec(ctx, sql, args...); err != nil {
+			return fmt.Errorf("error while deleting tag: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// bumpTaggedPosts increments version of posts having tag, tags are a part of post representation
+func bumpTaggedPosts(ctx context.Context, tx pgx.Tx, tagID int) error {
+	sql, args, err := goqu.Update("post").
+		Set(goqu.Record{"updated_at": time.Now(), "version": goqu.L("version + 1")}).
+		Where(goqu.C("id").In(goqu.From("post_tag").Select("post_id").Where(goqu.C("tag_id").Eq(tagID)))).
+		ToSQL()
+	if err != nil {
+		return fmt.Errorf("error while preparing bump post versions: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("error while bumping post versions: %w", err)
+	}
+
+	return nil
+}
+
+// withPostTags makes post write replace post tags in the same statement, missing tags are created.
+// The write must return post id and version, tags are changed only if the post row is written
+func withPostTags(write exp.Expression, tags []string) *goqu.SelectDataset {
+	ds := goqu.From("p").With("p", write)
+	postIDs := goqu.From("p").Select("id")
+
+	if len(tags) == 0 {
+		return ds.With("d", goqu.Delete("post_tag").Where(goqu.C("post_id").In(postIDs)))
+	}
+
+	names := make([]any, len(tags))
+	for i, tag := range tags {
+		names[i] = tag
+	}
+
+	// conflicting update returns ID of existing tag
+	tagIDs := goqu.Insert("tag").
+		Cols("name").
+		FromQuery(goqu.From("p").Select(goqu.L("unnest(ARRAY["+strings.Repeat("?, ", len(tags)-1)+"?]::VARCHAR[])", names...))).
+		OnConflict(goqu.DoUpdate("name", goqu.Record{"name": goqu.I("excluded.name")})).
+		Returning("id")
+
+	return ds.
+		With("t", tagIDs).
+		With("d", goqu.Delete("post_tag").Where(goqu.C("post_id").In(postIDs), goqu.C("tag_id").NotIn(goqu.From("t").Select("id")))).
+		With("i", goqu.Insert("post_tag").
+			Cols("post_id", "tag_id").
+			FromQuery(goqu.From("p").CrossJoin(goqu.T("t")).Select("p.id", "t.id")).
+			OnConflict(goqu.DoNothing()))
+}
+
+// postTagsExpr is a sorted array of tag names of the post with table alias
+func postTagsExpr(alias string) exp.LiteralExpression {
+	return goqu.L("ARRAY(?)", goqu.From(goqu.T("post_tag").As("pt")).
+		Join(goqu.T("tag").As("t"), goqu.On(goqu.Ex{"t.id": goqu.I("pt.tag_id")})).
+		Select("t.name").
+		Where(goqu.Ex{"pt.post_id": goqu.I(alias + ".id")}).
+		Order(goqu.I("t.name").Asc()))
+}
+
+// postTagsCondition selects posts having any or all of tags
+func postTagsCondition(alias string, tags []string, all bool) exp.Expression {
+	matched := goqu.From(goqu.T("post_tag").As("pt")).
+		Join(goqu.T("tag").As("t"), goqu.On(goqu.Ex{"t.id": goqu.I("pt.tag_id")})).
+		Where(goqu.Ex{"pt.post_id": goqu.I(alias + ".id"), "t.name": tags})
+
+	if all {
+		return goqu.L("? = ?", matched.Select(goqu.COUNT(goqu.Star())), len(tags))
+	}
+
+	return goqu.L("EXISTS ?", matched.Select(goqu.L("1")))
+}
@@ -0,0 +1,69 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-faker/faker/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/repository/postgres"
+	"github.com/trad3r/hskills/apirest/internal/testutils"
+)
+
+func TestTagRename(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	postRepo, tagRepo := getTagRepos(t)
+
+	tagged := &models.Post{Subject: faker.Sentence(), Tags: []string{"golang"}, Author: &models.User{ID: 1}}
+	require.NoError(t, postRepo.Add(ctx, tagged))
+
+	require.NoError(t, tagRepo.Rename(ctx, "golang", "go"))
+	assert.ErrorIs(t, tagRepo.Rename(ctx, "golang", "go"), custom_errors.ErrTagNotFound)
+
+	post, err := postRepo.FindById(ctx, tagged.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go"}, post.Tags)
+	assert.Equal(t, 2, post.Version)
+
+	untagged, err := postRepo.FindById(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, untagged.Version)
+}
+
+func TestTagMerge(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	postRepo, tagRepo := getTagRepos(t)
+
+	moved := &models.Post{Subject: faker.Sentence(), Tags: []string{"postgresql"}, Author: &models.User{ID: 1}}
+	require.NoError(t, postRepo.Add(ctx, moved))
+
+	kept := &models.Post{Subject: faker.Sentence(), Tags: []string{"postgres"}, Author: &models.User{ID: 1}}
+	require.NoError(t, postRepo.Add(ctx, kept))
+
+	require.NoError(t, tagRepo.Merge(ctx, "postgresql", "postgres"))
+	assert.ErrorIs(t, tagRepo.Merge(ctx, "postgresql", "postgres"), custom_errors.ErrTagNotFound)
+
+	post, err := postRepo.FindById(ctx, moved.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"postgres"}, post.Tags)
+	assert.Equal(t, 2, post.Version)
+
+	post, err = postRepo.FindById(ctx, kept.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, post.Version)
+}
+
+func getTagRepos(t *testing.T) (postgres.IPostRepository, postgres.ITagRepository) {
+	db := testutils.PrepareDB(t)
+
+	return postgres.NewPostRepository(db), postgres.NewTagRepository(db)
+}
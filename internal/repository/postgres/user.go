@@ -4,12 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
 	"github.com/hashicorp/go-multierror"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/trad3r/hskills/apirest/internal/custom_errors"
 	"github.com/trad3r/hskills/apirest/internal/models"
@@ -18,10 +19,19 @@ import (
 
 type IUserRepository interface {
 	Add(ctx context.Context, user *models.User) error
-	GetList(ctx context.Context, filter filters.UserFilter) ([]models.User, error)
-	Update(ctx context.Context, id int, userReq filters.UserUpdateRequest) error
+	GetList(ctx context.Context, filter filters.UserFilter) (*models.Page[models.User], error)
+	Export(ctx context.Context, filter filters.UserFilter, fn func(user models.User) error) error
+	Update(ctx context.Context, id int, userReq filters.UserUpdateRequest) (int, error)
 	Delete(ctx context.Context, id int) error
+	Restore(ctx context.Context, id int) (int, error)
+	SetRole(ctx context.Context, id int, role string) (int, error)
+	Purge(ctx context.Context, before time.Time) (int64, error)
 	FindById(ctx context.Context, id int) (*models.User, error)
+	BulkAdd(ctx context.Context, users []*models.User, atomic bool) ([]error, error)
+	BulkUpdate(ctx context.Context, items []filters.UserBulkUpdateItem, atomic bool) ([]error, error)
+	BulkDelete(ctx context.Context, ids []int, atomic bool) ([]error, error)
+	Import(ctx context.Context, items []filters.UserImportItem, atomic bool) ([]models.ImportResult, error)
+	FindIdByExternalId(ctx context.Context, externalID string) (int, error)
 }
 
 type UserRepository struct {
@@ -39,167 +49,675 @@ func (s UserRepository) Add(ctx context.Context, user *models.User) error {
 	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
 
-	ds := goqu.Insert("author").
-		Cols("name", "phonenumber").
-		Vals(
-			goqu.Vals{user.Name, user.Phonenumber},
-		).
-		Returning("id")
+	record := goqu.Record{"name": user.Name, "phonenumber": user.Phonenumber}
+	if len(user.Login) > 0 {
+		record["login"] = user.Login
+		record["password_hash"] = user.PasswordHash
+	}
+
+	ds := goqu.Insert("author").Rows(record).Returning("id")
 
 	sql, args, err := ds.ToSQL()
 	if err != nil {
 		return fmt.Errorf("error while creating sql: %w", err)
 	}
 
-	err = s.db.QueryRow(ctx, sql, args...).Scan(&user.ID)
+	err = audited(ctx, s.db, func(tx pgx.Tx) error {
+		return tx.QueryRow(ctx, sql, args...).Scan(&user.ID)
+	})
 	if err != nil {
-		return fmt.Errorf("error while inserting user: %w", err)
+		return fmt.Errorf("error while inserting user: %w", mapPgError(err))
 	}
 
 	return nil
 }
 
-// GetList returns user list
-func (s UserRepository) GetList(ctx context.Context, filter filters.UserFilter) ([]models.User, error) {
+// GetList returns user list page
+func (s UserRepository) GetList(ctx context.Context, filter filters.UserFilter) (*models.Page[models.User], error) {
 	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
 
 	var errs error
-	users := make([]models.User, 0, filter.Limit)
+	users := make([]models.User, 0, filter.Limit+1)
+
+	list, err := newUserList(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	ds := list.ds
+
+	if filter.Cursor != nil {
+		if err := checkCursor(list.keys, filter.Cursor); err != nil {
+			return nil, err
+		}
+
+		ds = ds.Where(keysetCondition(list.keys, filter.Cursor))
+	} else if filter.Offset > 0 {
+		ds = ds.Offset(filter.Offset)
+	}
+
+	if filter.Limit > 0 {
+		ds = ds.Limit(filter.Limit + 1)
+	}
+
+	sql, args, err := ds.ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("error while creating sql: %w", err)
+	}
+
+	rows, err := s.db.Query(ctx, sql, args...)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("error while querying users: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(scanDests(list.columns, &user)...); err != nil {
+			errs = multierror.Append(errs, err)
+			continue
+		}
+
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+
+	if errs != nil {
+		return nil, errs
+	}
+
+	page := newPage(users, int(filter.Limit), int(filter.Offset), list.keys, filter.Cursor, list.cursorValues)
+
+	if filter.Projection.Includes("posts") && len(page.Items) > 0 {
+		if err := s.embedLatestPosts(ctx, page.Items, filter.PostsLimit); err != nil {
+			return nil, err
+		}
+	}
+
+	if filter.WithTotal {
+		total, err := s.count(ctx, list.inner)
+		if err != nil {
+			return nil, err
+		}
+
+		page.Total = &total
+	}
+
+	return page, nil
+}
+
+// Export streams every user matching filter to fn in list order, pagination and embedded posts are ignored
+func (s UserRepository) Export(ctx context.Context, filter filters.UserFilter, fn func(user models.User) error) error {
+	list, err := newUserList(filter)
+	if err != nil {
+		return err
+	}
+
+	sql, args, err := list.ds.ToSQL()
+	if err != nil {
+		return fmt.Errorf("error while creating sql: %w", err)
+	}
+
+	rows, err := s.db.Query(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("error while querying users: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(scanDests(list.columns, &user)...); err != nil {
+			return fmt.Errorf("error while scanning user: %w", err)
+		}
+
+		if err := fn(user); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error while reading users: %w", err)
+	}
+
+	return nil
+}
+
+// userList is an ordered user list query without pagination, inner query has filters applied
+type userList struct {
+	ds           *goqu.SelectDataset
+	inner        *goqu.SelectDataset
+	columns      []column[models.User]
+	keys         []sortKey
+	cursorValues func(user models.User) []any
+}
+
+func newUserList(filter filters.UserFilter) (*userList, error) {
 	var wheres []goqu.Expression
 
-	postCountSubquery := goqu.From(goqu.T("post").As("p")).Select(goqu.COUNT("id")).Where(goqu.I("p.author_id").Eq(goqu.I("a.id")))
+	postCountSubquery := goqu.From(goqu.T("post").As("p")).Select(goqu.COUNT("id")).Where(goqu.I("p.author_id").Eq(goqu.I("a.id")), notDeleted("p"), goqu.I("p.status").Eq(models.PostStatusPublished))
 
-	ds := goqu.From(goqu.T("author").As("a")).
-		Select("a.id", "a.name", "a.phonenumber", "a.created_at", "a.updated_at", postCountSubquery.As("post_count"))
+	sort := filter.Sort
+	if len(sort) == 0 {
+		sort = []filters.SortField{{Field: "post_count", Desc: filter.TopPostsAmount == "desc"}}
+	}
+
+	keys, cursorValues, err := sortKeys(userSortColumns, sort)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := userListColumns(filter.Projection, sort, filter.Deleted)
+
+	innerExprs := []interface{}{postCountSubquery.As("post_count")}
+	outerExprs := make([]interface{}, 0, len(columns))
+	for _, c := range columns {
+		if c.expr != "post_count" {
+			innerExprs = append(innerExprs, "a."+c.expr.(string))
+		}
+
+		outerExprs = append(outerExprs, "u."+c.expr.(string))
+	}
+
+	inner := goqu.From(goqu.T("author").As("a")).Select(innerExprs...)
 
 	if filter.FromCreatedAt != nil {
-		wheres = append(wheres, goqu.C("created_at").Gte(filter.FromCreatedAt))
+		wheres = append(wheres, goqu.I("a.created_at").Gte(filter.FromCreatedAt))
 	}
 
 	if filter.ToCreatedAt != nil {
-		wheres = append(wheres, goqu.C("created_at").Lte(filter.ToCreatedAt))
+		wheres = append(wheres, goqu.I("a.created_at").Lte(filter.ToCreatedAt))
 	}
 
 	if len(filter.Name) > 0 {
-		wheres = append(wheres, goqu.C("name").In(filter.Name))
+		wheres = append(wheres, goqu.I("a.name").In(filter.Name))
+	}
+
+	if cond := deletedCondition("a", filter.Deleted); cond != nil {
+		wheres = append(wheres, cond)
 	}
 
 	if len(wheres) > 0 {
-		ds = ds.Where(wheres...)
+		inner = inner.Where(wheres...)
 	}
 
-	ds = ds.
-		Offset(filter.Offset).
-		Limit(filter.Limit)
+	backward := filter.Cursor != nil && filter.Cursor.Backward
+
+	ds := goqu.From(inner.As("u")).
+		Select(outerExprs...).
+		Order(orderBy(keys, backward)...)
+
+	return &userList{
+		ds:           ds,
+		inner:        inner,
+		columns:      columns,
+		keys:         keys,
+		cursorValues: cursorValues,
+	}, nil
+}
 
-	if filter.TopPostsAmount == "desc" {
-		ds = ds.Order(goqu.C("post_count").Desc())
-	} else {
-		ds = ds.Order(goqu.C("post_count").Asc())
+func (s UserRepository) count(ctx context.Context, inner *goqu.SelectDataset) (int, error) {
+	sql, args, err := goqu.From(inner.As("u")).Select(goqu.COUNT(goqu.Star())).ToSQL()
+	if err != nil {
+		return 0, fmt.Errorf("error while creating count sql: %w", err)
 	}
 
-	sql, args, err := ds.ToSQL()
+	var total int
+	if err := s.db.QueryRow(ctx, sql, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("error while counting users: %w", err)
+	}
+
+	return total, nil
+}
+
+// userListColumns returns columns of requested user fields, sort keys are always selected.
+// Column names are unqualified, they are used in both inner and outer queries
+func userListColumns(projection filters.Projection, sort []filters.SortField, deleted filters.Deleted) []column[models.User] {
+	columns := []column[models.User]{
+		{expr: "id", dest: func(u *models.User) any { return &u.ID }},
+		{expr: "post_count", dest: func(u *models.User) any { return &u.PostCount }},
+	}
+
+	if projection.HasField("name") || sortedBy(sort, "name") {
+		columns = append(columns, column[models.User]{expr: "name", dest: func(u *models.User) any { return &u.Name }})
+	}
+
+	if projection.HasField("phonenumber") {
+		columns = append(columns, column[models.User]{expr: "phonenumber", dest: func(u *models.User) any { return &u.Phonenumber }})
+	}
+
+	if projection.HasField("created_at") || sortedBy(sort, "created_at") || sortedBy(sort, "updated_at") {
+		columns = append(columns, column[models.User]{expr: "created_at", dest: func(u *models.User) any { return &u.CreatedAt }})
+	}
+
+	if projection.HasField("updated_at") || sortedBy(sort, "updated_at") {
+		columns = append(columns, column[models.User]{expr: "updated_at", dest: func(u *models.User) any { return &u.UpdatedAt }})
+	}
+
+	if deleted != filters.DeletedExclude && projection.HasField("deleted_at") {
+		columns = append(columns, column[models.User]{expr: "deleted_at", dest: func(u *models.User) any { return &u.DeletedAt }})
+	}
+
+	return columns
+}
+
+// embedLatestPosts sets latest posts of every user, newest first
+func (s UserRepository) embedLatestPosts(ctx context.Context, users []models.User, limit int) error {
+	ids := make([]int, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+
+	ranked := goqu.From(goqu.T("post").As("p")).
+		Select("p.id", "p.author_id", "p.subject", "p.slug", goqu.COALESCE(goqu.I("p.body"), "").As("body"), "p.created_at", "p.updated_at",
+			goqu.ROW_NUMBER().Over(goqu.W().PartitionBy("p.author_id").OrderBy(goqu.I("p.created_at").Desc(), goqu.I("p.id").Desc())).As("rn")).
+		Where(goqu.I("p.author_id").In(ids), notDeleted("p"), goqu.I("p.status").Eq(models.PostStatusPublished))
+
+	sql, args, err := goqu.From(ranked.As("r")).
+		Select("r.id", "r.author_id", "r.subject", "r.slug", "r.body", "r.created_at", "r.updated_at", commentCountSubquery("r").As("comment_count"), postReactionsExpr("r").As("reactions")).
+		Where(goqu.I("r.rn").Lte(limit)).
+		Order(goqu.I("r.author_id").Asc(), goqu.I("r.rn").Asc()).
+		ToSQL()
 	if err != nil {
-		return nil, fmt.Errorf("error while creating sql: %w", err)
+		return fmt.Errorf("error while creating latest posts sql: %w", err)
 	}
 
 	rows, err := s.db.Query(ctx, sql, args...)
-	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
-		return nil, fmt.Errorf("error while querying users: %w", err)
+	if err != nil {
+		return fmt.Errorf("error while querying latest posts: %w", err)
 	}
 	defer rows.Close()
 
+	posts := make(map[int][]models.Post, len(users))
 	for rows.Next() {
-		var user models.User
-		err := rows.Scan(&user.ID, &user.Name, &user.Phonenumber, &user.CreatedAt, &user.UpdatedAt, &user.PostCount)
-		if err != nil {
-			errs = multierror.Append(errs, err)
-			continue
+		var post models.Post
+		var authorID int
+
+		if err := rows.Scan(&post.ID, &authorID, &post.Subject, &post.Slug, &post.Body, &post.CreatedAt, &post.UpdatedAt, &post.CommentCount, &post.Reactions); err != nil {
+			return fmt.Errorf("error while scanning latest posts: %w", err)
 		}
 
-		users = append(users, user)
+		posts[authorID] = append(posts[authorID], post)
 	}
 
 	if err := rows.Err(); err != nil {
-		errs = multierror.Append(errs, err)
+		return fmt.Errorf("error while reading latest posts: %w", err)
+	}
+
+	for i := range users {
+		users[i].Posts = posts[users[i].ID]
 	}
 
-	return users, errs
+	return nil
+}
+
+// userSortColumns are sortable user columns of the outer list query, not updated user is sorted by creation time
+var userSortColumns = map[string]sortColumn[models.User]{
+	"id":         {expr: goqu.I("u.id"), value: func(u models.User) any { return u.ID }},
+	"name":       {expr: goqu.I("u.name"), value: func(u models.User) any { return u.Name }},
+	"created_at": {expr: goqu.I("u.created_at"), value: func(u models.User) any { return u.CreatedAt }},
+	"post_count": {expr: goqu.I("u.post_count"), value: func(u models.User) any { return u.PostCount }},
+	"updated_at": {
+		expr: goqu.COALESCE(goqu.I("u.updated_at"), goqu.I("u.created_at")),
+		value: func(u models.User) any {
+			if u.UpdatedAt != nil {
+				return u.UpdatedAt
+			}
+
+			return u.CreatedAt
+		},
+	},
 }
 
-// Update updates user's name or phone
-func (s UserRepository) Update(ctx context.Context, id int, userReq filters.UserUpdateRequest) error {
+// Update updates user's name or phone and returns new user version
+func (s UserRepository) Update(ctx context.Context, id int, userReq filters.UserUpdateRequest) (int, error) {
 	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
-	var userId int
+	var version int
 
-	ds := goqu.Update("author").
-		Where(goqu.Ex{"id": id}).
-		Returning("id")
+	ds, err := userUpdateDataset(id, userReq)
+	if err != nil {
+		return 0, err
+	}
 
-	updates := make(map[string]interface{}, 3)
-	if len(userReq.Name) > 0 {
-		updates["name"] = userReq.Name
+	sql, args, err := ds.Returning("version").ToSQL()
+	if err != nil {
+		return 0, fmt.Errorf("error while preparing update user: %w", err)
 	}
 
-	if len(userReq.Phonenumber) > 0 {
-		updates["phonenumber"] = userReq.Phonenumber
+	err = audited(ctx, s.db, func(tx pgx.Tx) error {
+		return tx.QueryRow(ctx, sql, args...).Scan(&version)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, missingRowError(ctx, s.db, "author", id, custom_errors.ErrUserNotFound)
+		}
+
+		return 0, fmt.Errorf("error while updating user: %w", mapPgError(err))
 	}
 
-	if len(updates) > 0 {
-		updates["updated_at"] = time.Now()
+	return version, nil
+}
+
+// SetRole changes role of live user and returns new user version
+func (s UserRepository) SetRole(ctx context.Context, id int, role string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	var version int
+
+	sql, args, err := goqu.Update("author").
+		Set(goqu.Record{"role": role, "updated_at": time.Now(), "version": goqu.L("version + 1")}).
+		Where(goqu.Ex{"id": id, "deleted_at": nil}).
+		Returning("version").
+		ToSQL()
+	if err != nil {
+		return 0, fmt.Errorf("error while preparing set user role: %w", err)
 	}
 
-	if len(updates) > 0 {
-		ds = ds.Set(updates)
+	err = audited(ctx, s.db, func(tx pgx.Tx) error {
+		return tx.QueryRow(ctx, sql, args...).Scan(&version)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, custom_errors.ErrUserNotFound
+		}
+
+		return 0, fmt.Errorf("error while setting user role: %w", err)
 	}
 
-	sql, args, err := ds.ToSQL()
+	return version, nil
+}
+
+// userUpdateDataset builds update of the fields set in request
+func userUpdateDataset(id int, userReq filters.UserUpdateRequest) (*goqu.UpdateDataset, error) {
+	wheres := goqu.Ex{"id": id, "deleted_at": nil}
+	if len(userReq.IfVersions) > 0 {
+		wheres["version"] = userReq.IfVersions
+	}
+
+	updates := make(map[string]interface{}, 4)
+	if userReq.Name.Set {
+		updates["name"] = userReq.Name.Value
+	}
+
+	if userReq.Phonenumber.Set {
+		updates["phonenumber"] = userReq.Phonenumber.Value
+	}
+
+	if len(updates) == 0 {
+		return nil, custom_errors.NewValidationError("body", "nothing to update")
+	}
+
+	updates["updated_at"] = time.Now()
+	updates["version"] = goqu.L("version + 1")
+
+	return goqu.Update("author").Where(wheres).Set(updates), nil
+}
+
+// BulkAdd adds users in one transaction, see runBulk for atomic mode
+func (s UserRepository) BulkAdd(ctx context.Context, users []*models.User, atomic bool) ([]error, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	queries := make([]bulkQuery, 0, len(users))
+	for _, user := range users {
+		sql, args, err := goqu.Insert("author").
+			Cols("name", "phonenumber").
+			Vals(goqu.Vals{user.Name, user.Phonenumber}).
+			Returning("id").
+			ToSQL()
+		if err != nil {
+			return nil, fmt.Errorf("error while creating sql: %w", err)
+		}
+
+		queries = append(queries, bulkQuery{sql: sql, args: args, scan: scanID(&user.ID, nil)})
+	}
+
+	return runBulk(ctx, s.db, queries, atomic)
+}
+
+// Import adds users or updates ones with the same external ID in one transaction, see runBulk for atomic mode.
+// External ID of a deleted user is a conflict, the user stays in trash
+func (s UserRepository) Import(ctx context.Context, items []filters.UserImportItem, atomic bool) ([]models.ImportResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	results := make([]models.ImportResult, len(items))
+	queries := make([]bulkQuery, 0, len(items))
+	for i, item := range items {
+		ds := goqu.Insert("author").
+			Cols("external_id", "name", "phonenumber").
+			Vals(goqu.Vals{nullString(item.ExternalID), item.Name, item.Phonenumber}).
+			Returning("id", goqu.L("xmax = 0"))
+
+		if len(item.ExternalID) > 0 {
+			ds = ds.OnConflict(goqu.DoUpdate("external_id", goqu.Record{
+				"name":        goqu.I("excluded.name"),
+				"phonenumber": goqu.I("excluded.phonenumber"),
+				"updated_at":  time.Now(),
+				"version":     goqu.L("author.version + 1"),
+			}).Where(goqu.I("author.deleted_at").IsNull()))
+		}
+
+		sql, args, err := ds.ToSQL()
+		if err != nil {
+			return nil, fmt.Errorf("error while creating sql: %w", err)
+		}
+
+		queries = append(queries, bulkQuery{sql: sql, args: args, scan: scanImported(&results[i])})
+	}
+
+	errs, err := runBulk(ctx, s.db, queries, atomic)
 	if err != nil {
-		return fmt.Errorf("error while preparing update user: %w", err)
+		return nil, err
 	}
 
-	err = s.db.QueryRow(ctx, sql, args...).Scan(&userId)
+	return importResults(results, errs), nil
+}
+
+// FindIdByExternalId returns user ID by external ID, 0 if user is not found
+func (s UserRepository) FindIdByExternalId(ctx context.Context, externalID string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	sql, args, err := goqu.From("author").Select("id").Where(goqu.Ex{"external_id": externalID, "deleted_at": nil}).ToSQL()
 	if err != nil {
-		return fmt.Errorf("error while updating user: %w", err)
+		return 0, fmt.Errorf("error while preparing find user by external ID: %w", err)
 	}
 
-	if userId == 0 {
-		return custom_errors.ErrUserNotFound
+	var id int
+	if err := s.db.QueryRow(ctx, sql, args...).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+
+		return 0, fmt.Errorf("error while find user by external ID %s: %w", externalID, err)
 	}
 
-	return nil
+	return id, nil
 }
 
-// Delete removes user by ID
+// BulkUpdate updates users in one transaction, see runBulk for atomic mode
+func (s UserRepository) BulkUpdate(ctx context.Context, items []filters.UserBulkUpdateItem, atomic bool) ([]error, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	queries := make([]bulkQuery, 0, len(items))
+	for _, item := range items {
+		ds, err := userUpdateDataset(item.ID, item.UserUpdateRequest)
+		if err != nil {
+			return nil, err
+		}
+
+		sql, args, err := ds.Returning("id").ToSQL()
+		if err != nil {
+			return nil, fmt.Errorf("error while preparing update user: %w", err)
+		}
+
+		var id int
+		queries = append(queries, bulkQuery{sql: sql, args: args, scan: scanID(&id, custom_errors.ErrUserNotFound)})
+	}
+
+	return runBulk(ctx, s.db, queries, atomic)
+}
+
+// BulkDelete moves users to trash by IDs in one transaction, see runBulk for atomic mode
+func (s UserRepository) BulkDelete(ctx context.Context, ids []int, atomic bool) ([]error, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	queries := make([]bulkQuery, 0, len(ids))
+	for _, id := range ids {
+		sql, args, err := userSoftDelete(id).ToSQL()
+		if err != nil {
+			return nil, fmt.Errorf("error while preparing delete user: %w", err)
+		}
+
+		var deletedID int
+		queries = append(queries, bulkQuery{sql: sql, args: args, scan: scanID(&deletedID, custom_errors.ErrUserNotFound)})
+	}
+
+	return runBulk(ctx, s.db, queries, atomic)
+}
+
+// userSoftDelete marks live user and its posts as deleted at the same time, it returns ID of deleted user
+func userSoftDelete(id int) *goqu.SelectDataset {
+	author := goqu.Update("author").
+		Set(goqu.Record{"deleted_at": goqu.L("now()"), "version": goqu.L("version + 1")}).
+		Where(goqu.Ex{"id": id, "deleted_at": nil}).
+		Returning("id", "deleted_at")
+
+	posts := goqu.Update("post").
+		Set(goqu.Record{"deleted_at": goqu.I("d.deleted_at"), "version": goqu.L("post.version + 1")}).
+		From(goqu.T("d")).
+		Where(goqu.Ex{"post.author_id": goqu.I("d.id"), "post.deleted_at": nil})
+
+	return goqu.From("d").With("d", author).With("p", posts).Select("id")
+}
+
+// Delete moves user and its posts to trash by ID
 func (s UserRepository) Delete(ctx context.Context, id int) error {
 	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
 
-	ds := goqu.Delete("author").Where(goqu.Ex{"id": id}).Returning("id")
-	sql, args, err := ds.ToSQL()
+	sql, args, err := userSoftDelete(id).ToSQL()
 	if err != nil {
 		return fmt.Errorf("error while preparing delete user: %w", err)
 	}
 
-	err = s.db.QueryRow(ctx, sql, args...).Scan(&id)
+	err = audited(ctx, s.db, func(tx pgx.Tx) error {
+		return tx.QueryRow(ctx, sql, args...).Scan(&id)
+	})
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return custom_errors.ErrUserNotFound
+		}
+
 		return fmt.Errorf("error while deleting user: %w", err)
 	}
 
-	log.Println(id)
 	return nil
 }
 
+// Restore brings deleted user back with posts deleted along with it and returns new user version
+func (s UserRepository) Restore(ctx context.Context, id int) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	tx, err := beginAudited(ctx, s.db)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	sql, args, err := goqu.From("author").Select("deleted_at").Where(goqu.Ex{"id": id}).ForUpdate(exp.Wait).ToSQL()
+	if err != nil {
+		return 0, fmt.Errorf("error while preparing restore user: %w", err)
+	}
+
+	var deletedAt *time.Time
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&deletedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, custom_errors.ErrUserNotFound
+		}
+
+		return 0, fmt.Errorf("error while find user by ID %d: %w", id, err)
+	}
+
+	if deletedAt == nil {
+		return 0, fmt.Errorf("%w: user is not deleted", custom_errors.ErrConflict)
+	}
+
+	sql, args, err = goqu.Update("post").
+		Set(goqu.Record{"deleted_at": nil, "version": goqu.L("version + 1")}).
+		Where(goqu.Ex{"author_id": id, "deleted_at": *deletedAt}).
+		ToSQL()
+	if err != nil {
+		return 0, fmt.Errorf("error while preparing restore user posts: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return 0, fmt.Errorf("error while restoring user posts: %w", err)
+	}
+
+	sql, args, err = goqu.Update("author").
+		Set(goqu.Record{"deleted_at": nil, "version": goqu.L("version + 1")}).
+		Where(goqu.Ex{"id": id}).
+		Returning("version").
+		ToSQL()
+	if err != nil {
+		return 0, fmt.Errorf("error while preparing restore user: %w", err)
+	}
+
+	var version int
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&version); err != nil {
+		return 0, fmt.Errorf("error while restoring user: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("error while commit restore user: %w", err)
+	}
+
+	return version, nil
+}
+
+// Purge removes users deleted before given time with all their posts and returns amount of removed users
+func (s UserRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	sql, args, err := goqu.Delete("author").Where(goqu.C("deleted_at").Lt(before)).ToSQL()
+	if err != nil {
+		return 0, fmt.Errorf("error while preparing purge users: %w", err)
+	}
+
+	var tag pgconn.CommandTag
+	err = audited(ctx, s.db, func(tx pgx.Tx) error {
+		tag, err = tx.Exec(ctx, sql, args...)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error while purging users: %w", mapPgError(err))
+	}
+
+	return tag.RowsAffected(), nil
+}
+
 // FindById returns user by ID
 func (s UserRepository) FindById(ctx context.Context, id int) (*models.User, error) {
 	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
 
-	ds := goqu.From("author").
-		Select("id", "name", "phonenumber", "created_at", "updated_at").
-		Where(goqu.Ex{"id": id})
+	postCountSubquery := goqu.From(goqu.T("post").As("p")).Select(goqu.COUNT("id")).Where(goqu.I("p.author_id").Eq(goqu.I("a.id")), notDeleted("p"), goqu.I("p.status").Eq(models.PostStatusPublished))
+
+	ds := goqu.From(goqu.T("author").As("a")).
+		Select("a.id", "a.name", "a.phonenumber", goqu.COALESCE(goqu.I("a.login"), "").As("login"), "a.role", "a.created_at", "a.updated_at", postCountSubquery.As("post_count"), "a.version").
+		Where(goqu.Ex{"a.id": id, "a.deleted_at": nil})
 
 	sql, args, err := ds.ToSQL()
 	if err != nil {
@@ -208,7 +726,7 @@ func (s UserRepository) FindById(ctx context.Context, id int) (*models.User, err
 
 	var user models.User
 
-	if err := s.db.QueryRow(ctx, sql, args...).Scan(&user.ID, &user.Name, &user.Phonenumber, &user.CreatedAt, &user.UpdatedAt); err != nil {
+	if err := s.db.QueryRow(ctx, sql, args...).Scan(&user.ID, &user.Name, &user.Phonenumber, &user.Login, &user.Role, &user.CreatedAt, &user.UpdatedAt, &user.PostCount, &user.Version); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
 		}
@@ -9,11 +9,10 @@ import (
 	"github.com/go-faker/faker/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"github.com/trad3r/hskills/apirest/internal/migrator"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
 	"github.com/trad3r/hskills/apirest/internal/models"
 	"github.com/trad3r/hskills/apirest/internal/repository/filters"
 	"github.com/trad3r/hskills/apirest/internal/repository/postgres"
-	"github.com/trad3r/hskills/apirest/internal/storage"
 	"github.com/trad3r/hskills/apirest/internal/testutils"
 )
 
@@ -42,6 +41,24 @@ func TestUserAdd(t *testing.T) {
 	assert.Empty(t, dbUser.UpdatedAt)
 }
 
+func TestUserFindById(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	pgRepo := getUserRepo(t)
+
+	user, err := pgRepo.FindById(ctx, 1)
+	require.NoError(t, err)
+	require.NotNil(t, user)
+	assert.Equal(t, "John", user.Name)
+	assert.Equal(t, 2, user.PostCount)
+
+	user, err = pgRepo.FindById(ctx, 100)
+	require.NoError(t, err)
+	assert.Nil(t, user)
+}
+
 func TestUserGetList(t *testing.T) {
 	t.Parallel()
 
@@ -86,16 +103,24 @@ func TestUserGetList(t *testing.T) {
 			count:      2,
 			expectedId: 3,
 		},
+		{
+			name: "Sort by name desc",
+			filter: filters.UserFilter{
+				Sort: []filters.SortField{{Field: "name", Desc: true}, {Field: "id"}},
+			},
+			count:      5,
+			expectedId: 2,
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			list, err := pgRepo.GetList(ctx, tc.filter)
+			page, err := pgRepo.GetList(ctx, tc.filter)
 			require.NoError(t, err)
-			assert.Equal(t, tc.count, len(list))
-			assert.Equal(t, tc.expectedId, list[0].ID)
+			assert.Equal(t, tc.count, len(page.Items))
+			assert.Equal(t, tc.expectedId, page.Items[0].ID)
 		})
 	}
 }
@@ -113,8 +138,8 @@ func TestUserUpdateName(t *testing.T) {
 
 	user.Name = faker.Name()
 
-	err = pgRepo.Update(context.Background(), user.ID, filters.UserUpdateRequest{
-		Name: user.Name,
+	_, err = pgRepo.Update(context.Background(), user.ID, filters.UserUpdateRequest{
+		Name: filters.Value(user.Name),
 	})
 	require.NoError(t, err)
 
@@ -136,8 +161,8 @@ func TestUserUpdatePhone(t *testing.T) {
 
 	user.Phonenumber = faker.Phonenumber()
 
-	err = pgRepo.Update(context.Background(), user.ID, filters.UserUpdateRequest{
-		Phonenumber: user.Phonenumber,
+	_, err = pgRepo.Update(context.Background(), user.ID, filters.UserUpdateRequest{
+		Phonenumber: filters.Value(user.Phonenumber),
 	})
 	require.NoError(t, err)
 
@@ -160,9 +185,9 @@ func TestUserUpdateNameAndPhone(t *testing.T) {
 	user.Name = faker.Name()
 	user.Phonenumber = faker.Phonenumber()
 
-	err = pgRepo.Update(context.Background(), user.ID, filters.UserUpdateRequest{
-		Name:        user.Name,
-		Phonenumber: user.Phonenumber,
+	_, err = pgRepo.Update(context.Background(), user.ID, filters.UserUpdateRequest{
+		Name:        filters.Value(user.Name),
+		Phonenumber: filters.Value(user.Phonenumber),
 	})
 	require.NoError(t, err)
 
@@ -191,16 +216,217 @@ func TestUserDelete(t *testing.T) {
 	require.Empty(t, user)
 }
 
-func getUserRepo(t *testing.T) postgres.IUserRepository {
-	dsn := testutils.PreparePostgres(t)
-	err := migrator.ApplyPostgresMigrations("../../../migrations", dsn)
+func TestUserRestore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	pgRepo := getUserRepo(t)
+
+	user, err := pgRepo.FindById(ctx, 1)
 	require.NoError(t, err)
+	require.NotNil(t, user)
 
-	err = testutils.RunFixtures("../../../fixtures", dsn)
+	_, err = pgRepo.Restore(ctx, user.ID)
+	require.ErrorIs(t, err, custom_errors.ErrConflict)
+
+	err = pgRepo.Delete(ctx, user.ID)
+	require.NoError(t, err)
+
+	page, err := pgRepo.GetList(ctx, filters.UserFilter{Limit: 10, Deleted: filters.DeletedOnly})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, user.ID, page.Items[0].ID)
+	assert.NotNil(t, page.Items[0].DeletedAt)
+
+	version, err := pgRepo.Restore(ctx, user.ID)
 	require.NoError(t, err)
+	assert.Greater(t, version, user.Version)
 
-	db, err := storage.NewDB(context.Background(), dsn)
+	restored, err := pgRepo.FindById(ctx, user.ID)
 	require.NoError(t, err)
+	require.NotNil(t, restored)
+	assert.Equal(t, user.PostCount, restored.PostCount)
+}
+
+func TestUserSetRole(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	pgRepo := getUserRepo(t)
+
+	user, err := pgRepo.FindById(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, models.UserRoleAuthor, user.Role)
+
+	version, err := pgRepo.SetRole(ctx, 1, models.UserRoleModerator)
+	require.NoError(t, err)
+	require.Greater(t, version, user.Version)
+
+	user, err = pgRepo.FindById(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, models.UserRoleModerator, user.Role)
+
+	_, err = pgRepo.SetRole(ctx, 1, "owner")
+	require.Error(t, err)
+
+	_, err = pgRepo.SetRole(ctx, 1000, models.UserRoleAdmin)
+	require.ErrorIs(t, err, custom_errors.ErrUserNotFound)
+}
+
+func TestUserGetListIncludePosts(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	pgRepo := getUserRepo(t)
+
+	page, err := pgRepo.GetList(ctx, filters.UserFilter{
+		Name:       []string{"John"},
+		Projection: filters.Projection{Fields: []string{"name"}, Include: []string{"posts"}},
+		PostsLimit: 1,
+	})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "John", page.Items[0].Name)
+	assert.Empty(t, page.Items[0].Phonenumber)
+	assert.Len(t, page.Items[0].Posts, 1)
+}
+
+func TestUserBulkAdd(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	pgRepo := getUserRepo(t)
+
+	users := []*models.User{
+		{Name: faker.Name(), Phonenumber: faker.Phonenumber()},
+		{Name: faker.Name(), Phonenumber: faker.Phonenumber()},
+	}
+
+	errs, err := pgRepo.BulkAdd(ctx, users, true)
+	require.NoError(t, err)
+	assert.Equal(t, []error{nil, nil}, errs)
+
+	for _, user := range users {
+		require.NotEmpty(t, user.ID)
+
+		dbUser, err := pgRepo.FindById(ctx, user.ID)
+		require.NoError(t, err)
+		require.NotNil(t, dbUser)
+		assert.Equal(t, user.Name, dbUser.Name)
+	}
+}
+
+func TestUserBulkDelete(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	pgRepo := getUserRepo(t)
+
+	testCases := []struct {
+		name    string
+		atomic  bool
+		errs    []error
+		deleted bool
+	}{
+		{
+			name:    "Atomic rolls back",
+			atomic:  true,
+			errs:    []error{custom_errors.ErrBulkAborted, custom_errors.ErrUserNotFound},
+			deleted: false,
+		},
+		{
+			name:    "Partial keeps successful",
+			atomic:  false,
+			errs:    []error{nil, custom_errors.ErrUserNotFound},
+			deleted: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			errs, err := pgRepo.BulkDelete(ctx, []int{5, 100}, tc.atomic)
+			require.NoError(t, err)
+			require.Len(t, errs, len(tc.errs))
+
+			for i := range tc.errs {
+				assert.ErrorIs(t, errs[i], tc.errs[i])
+			}
+
+			user, err := pgRepo.FindById(ctx, 5)
+			require.NoError(t, err)
+			assert.Equal(t, tc.deleted, user == nil)
+		})
+	}
+}
+
+func TestUserImport(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	pgRepo := getUserRepo(t)
+
+	items := []filters.UserImportItem{
+		{ExternalID: "crm-1", Name: "Imported", Phonenumber: "+79000000001"},
+		{Name: "Without key", Phonenumber: "+79000000002"},
+	}
+
+	results, err := pgRepo.Import(ctx, items, true)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.True(t, results[0].Created)
+	assert.True(t, results[1].Created)
+
+	items[0].Name = "Reimported"
+	results, err = pgRepo.Import(ctx, items[:1], true)
+	require.NoError(t, err)
+	require.NoError(t, results[0].Err)
+	assert.False(t, results[0].Created)
+
+	id, err := pgRepo.FindIdByExternalId(ctx, "crm-1")
+	require.NoError(t, err)
+	assert.Equal(t, results[0].ID, id)
+
+	user, err := pgRepo.FindById(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "Reimported", user.Name)
+	assert.Equal(t, 2, user.Version)
+}
+
+func TestUserImportDeleted(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	pgRepo := getUserRepo(t)
+
+	items := []filters.UserImportItem{{ExternalID: "crm-trash", Name: "Trashed", Phonenumber: "+79000000003"}}
+
+	results, err := pgRepo.Import(ctx, items, true)
+	require.NoError(t, err)
+	require.NoError(t, results[0].Err)
+	id := results[0].ID
+
+	require.NoError(t, pgRepo.Delete(ctx, id))
+
+	items[0].Name = "Reimported"
+	results, err = pgRepo.Import(ctx, items, false)
+	require.NoError(t, err)
+	assert.ErrorIs(t, results[0].Err, custom_errors.ErrConflict)
+	assert.Zero(t, results[0].ID)
+
+	user, err := pgRepo.FindById(ctx, id)
+	require.NoError(t, err)
+	assert.Nil(t, user, "deleted user stays in trash")
+}
+
+func getUserRepo(t *testing.T) postgres.IUserRepository {
+	db := testutils.PrepareDB(t)
 
 	return postgres.NewUserRepository(db)
 }
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/TRAD3R/tlog"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/trad3r/hskills/apirest/internal/auth"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/repository/filters"
+	"github.com/trad3r/hskills/apirest/internal/repository/postgres"
+	"github.com/trad3r/hskills/apirest/internal/reqctx"
+)
+
+const (
+	maxAPIKeyNameLength = 64
+	// apiKeyTouchInterval limits writes of last use time of busy keys
+	apiKeyTouchInterval = time.Minute
+)
+
+type IAPIKeyService interface {
+	APIKeyList(req *http.Request) ([]models.APIKey, error)
+	APIKeyAdd(req *http.Request) (*models.APIKey, error)
+	APIKeyCreate(ctx context.Context, addReq filters.APIKeyAddRequest) (*models.APIKey, error)
+	APIKeyRevoke(id int, req *http.Request) error
+	APIKeyRotate(id int, req *http.Request) (*models.APIKey, error)
+	Authenticate(ctx context.Context, key string) (*models.APIKey, error)
+}
+
+type APIKeyService struct {
+	repo   postgres.IAPIKeyRepository
+	logger *tlog.Logger
+}
+
+func NewAPIKeyService(logger *tlog.Logger, db *pgxpool.Pool) IAPIKeyService {
+	return &APIKeyService{
+		repo:   postgres.NewAPIKeyRepository(db),
+		logger: logger,
+	}
+}
+
+func (s *APIKeyService) APIKeyList(req *http.Request) ([]models.APIKey, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), time.Second*10)
+	defer cancel()
+
+	return s.repo.GetList(ctx)
+}
+
+func (s *APIKeyService) APIKeyAdd(req *http.Request) (*models.APIKey, error) {
+	var addReq filters.APIKeyAddRequest
+
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decodeJSON(body, &addReq, false); err != nil {
+		return nil, err
+	}
+
+	return s.APIKeyCreate(req.Context(), addReq)
+}
+
+// APIKeyCreate makes a new key, the returned key is the only copy of its secret
+func (s *APIKeyService) APIKeyCreate(ctx context.Context, addReq filters.APIKeyAddRequest) (*models.APIKey, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	addReq.Name = strings.TrimSpace(addReq.Name)
+	if len(addReq.Name) == 0 || utf8.RuneCountInString(addReq.Name) > maxAPIKeyNameLength {
+		return nil, custom_errors.NewValidationError("name", fmt.Sprintf("must be 1 to %d characters", maxAPIKeyNameLength))
+	}
+
+	scopes, err := validateScopes(addReq.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	if addReq.ExpiresAt != nil && !addReq.ExpiresAt.After(time.Now()) {
+		return nil, custom_errors.NewValidationError("expires_at", "must be in the future")
+	}
+
+	key, prefix, hash, err := auth.NewAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey := &models.APIKey{
+		Name:      addReq.Name,
+		Prefix:    prefix,
+		Scopes:    scopes,
+		ExpiresAt: addReq.ExpiresAt,
+	}
+
+	if user := reqctx.User(ctx); user != nil {
+		apiKey.CreatedBy = &user.ID
+	}
+
+	if err := s.repo.Add(ctx, apiKey, hash); err != nil {
+		return nil, err
+	}
+
+	apiKey.Key = key
+
+	return apiKey, nil
+}
+
+func (s *APIKeyService) APIKeyRevoke(id int, req *http.Request) error {
+	ctx, cancel := context.WithTimeout(req.Context(), time.Second*10)
+	defer cancel()
+
+	return s.repo.Revoke(ctx, id)
+}
+
+// APIKeyRotate gives active key a new secret keeping its scopes
+func (s *APIKeyService) APIKeyRotate(id int, req *http.Request) (*models.APIKey, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), time.Second*10)
+	defer cancel()
+
+	key, prefix, hash, err := auth.NewAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey, err := s.repo.Rotate(ctx, id, prefix, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey.Key = key
+
+	return apiKey, nil
+}
+
+// Authenticate returns active key and records its use
+func (s *APIKeyService) Authenticate(ctx context.Context, key string) (*models.APIKey, error) {
+	apiKey, err := s.repo.FindByHash(ctx, auth.HashToken(key))
+	if err != nil {
+		return nil, err
+	}
+
+	if apiKey == nil {
+		return nil, custom_errors.ErrInvalidToken
+	}
+
+	if apiKey.LastUsedAt == nil || time.Since(*apiKey.LastUsedAt) > apiKeyTouchInterval {
+		// failed bookkeeping does not fail the request
+		if err := s.repo.Touch(ctx, apiKey.ID); err != nil {
+			s.logger.Error("error while touching API key", "err", err.Error())
+		}
+	}
+
+	return apiKey, nil
+}
+
+// validateScopes checks scopes are known and returns them without duplicates
+func validateScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, custom_errors.NewValidationError("scopes", "is required")
+	}
+
+	unique := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !slices.Contains(auth.Scopes, scope) {
+			return nil, custom_errors.NewValidationError("scopes", fmt.Sprintf("unknown scope %q, expected one of %s", scope, strings.Join(auth.Scopes, ", ")))
+		}
+
+		if !slices.Contains(unique, scope) {
+			unique = append(unique, scope)
+		}
+	}
+
+	return unique, nil
+}
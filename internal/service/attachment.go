@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/TRAD3R/tlog"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/trad3r/hskills/apirest/internal/blobstore"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/policy"
+	"github.com/trad3r/hskills/apirest/internal/repository/filters"
+	"github.com/trad3r/hskills/apirest/internal/repository/postgres"
+)
+
+const (
+	attachmentFormField = "file"
+	// multipartOverhead is room for multipart headers and boundaries on top of file size
+	multipartOverhead     = 1 << 20
+	maxAttachmentFilename = 255
+	// sniffLength is how many leading bytes content type detection looks at
+	sniffLength = 512
+)
+
+// AttachmentLimits are checked on upload, content type is detected from the content rather than trusted from the client
+type AttachmentLimits struct {
+	MaxSize      int64
+	AllowedTypes []string
+}
+
+type IAttachmentService interface {
+	AttachmentList(postID int, req *http.Request) (*models.Page[models.Attachment], error)
+	AttachmentAdd(postID int, req *http.Request) (*models.Attachment, error)
+	AttachmentGet(postID int, id int, req *http.Request) (*models.Attachment, io.ReadCloser, error)
+	AttachmentDelete(postID int, id int, req *http.Request) error
+}
+
+type AttachmentService struct {
+	repo   postgres.IAttachmentRepository
+	posts  postgres.IPostRepository
+	store  blobstore.Store
+	limits AttachmentLimits
+	logger *tlog.Logger
+}
+
+func NewAttachmentService(logger *tlog.Logger, db *pgxpool.Pool, store blobstore.Store, limits AttachmentLimits) IAttachmentService {
+	return &AttachmentService{
+		repo:   postgres.NewAttachmentRepository(db),
+		posts:  postgres.NewPostRepository(db),
+		store:  store,
+		limits: limits,
+		logger: logger,
+	}
+}
+
+// AttachmentList returns every attachment of the post
+func (s *AttachmentService) AttachmentList(postID int, req *http.Request) (*models.Page[models.Attachment], error) {
+	ctx := req.Context()
+
+	if err := existingPost(ctx, s.posts, postID); err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.repo.GetList(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Page[models.Attachment]{Items: attachments}, nil
+}
+
+// AttachmentAdd stores file field of multipart request as attachment of the post
+func (s *AttachmentService) AttachmentAdd(postID int, req *http.Request) (*models.Attachment, error) {
+	ctx := req.Context()
+
+	if err := authorizePost(ctx, s.posts, postID, filters.DeletedExclude, policy.EditPost); err != nil {
+		return nil, err
+	}
+
+	req.Body = http.MaxBytesReader(nil, req.Body, s.limits.MaxSize+multipartOverhead)
+
+	mr, err := req.MultipartReader()
+	if err != nil {
+		return nil, fmt.Errorf("%w: multipart/form-data is expected", custom_errors.ErrUnsupportedMediaType)
+	}
+
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, custom_errors.NewValidationError(attachmentFormField, "is required")
+		}
+
+		if err != nil {
+			return nil, uploadError(err)
+		}
+
+		if part.FormName() != attachmentFormField {
+			_ = part.Close()
+			continue
+		}
+
+		attachment, err := s.upload(ctx, postID, part.FileName(), part)
+		_ = part.Close()
+
+		return attachment, err
+	}
+}
+
+// AttachmentGet returns attachment with its content, the caller closes the content
+func (s *AttachmentService) AttachmentGet(postID int, id int, req *http.Request) (*models.Attachment, io.ReadCloser, error) {
+	ctx := req.Context()
+
+	if err := existingPost(ctx, s.posts, postID); err != nil {
+		return nil, nil, err
+	}
+
+	attachment, err := s.repo.FindById(ctx, postID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if attachment == nil {
+		return nil, nil, custom_errors.ErrAttachmentNotFound
+	}
+
+	content, err := s.store.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, blobstore.ErrNotFound) {
+			return nil, nil, custom_errors.ErrAttachmentNotFound
+		}
+
+		return nil, nil, err
+	}
+
+	return attachment, content, nil
+}
+
+// AttachmentDelete removes attachment, its content is removed from storage by the cleanup job
+func (s *AttachmentService) AttachmentDelete(postID int, id int, req *http.Request) error {
+	ctx := req.Context()
+
+	if err := authorizePost(ctx, s.posts, postID, filters.DeletedExclude, policy.EditPost); err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ctx, postID, id)
+}
+
+// upload spools content into temporary file to check its size and type and to count checksum before it is stored
+func (s *AttachmentService) upload(ctx context.Context, postID int, filename string, content io.Reader) (*models.Attachment, error) {
+	filename = path.Base(strings.ReplaceAll(filename, `\`, "/"))
+	if filename == "." || filename == "/" || len(filename) == 0 {
+		return nil, custom_errors.NewValidationError(attachmentFormField, "must have a file name")
+	}
+
+	if utf8.RuneCountInString(filename) > maxAttachmentFilename {
+		return nil, custom_errors.NewValidationError(attachmentFormField, fmt.Sprintf("file name must be at most %d characters", maxAttachmentFilename))
+	}
+
+	tmp, err := os.CreateTemp("", "attachment-*")
+	if err != nil {
+		return nil, fmt.Errorf("error while creating upload file: %w", err)
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(content, s.limits.MaxSize+1))
+	if err != nil {
+		return nil, uploadError(err)
+	}
+
+	if size > s.limits.MaxSize {
+		return nil, fmt.Errorf("%w: attachment must be at most %d bytes", custom_errors.ErrPayloadTooLarge, s.limits.MaxSize)
+	}
+
+	if size == 0 {
+		return nil, custom_errors.NewValidationError(attachmentFormField, "must not be empty")
+	}
+
+	contentType, err := detectContentType(tmp)
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.Contains(s.limits.AllowedTypes, contentType) {
+		return nil, fmt.Errorf("%w: %s attachments are not allowed", custom_errors.ErrUnsupportedMediaType, contentType)
+	}
+
+	key, err := attachmentKey(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("error while reading upload file: %w", err)
+	}
+
+	if err := s.store.Put(ctx, key, tmp, size, contentType); err != nil {
+		return nil, err
+	}
+
+	attachment := &models.Attachment{
+		PostID:      postID,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        size,
+		Checksum:    hex.EncodeToString(hash.Sum(nil)),
+		StorageKey:  key,
+	}
+
+	if err := s.repo.Add(ctx, attachment); err != nil {
+		if err := s.store.Delete(context.WithoutCancel(ctx), key); err != nil {
+			s.logger.Error("error removing object of failed attachment", "key", key, "err", err.Error())
+		}
+
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// detectContentType sniffs media type of file content without parameters
+func detectContentType(f *os.File) (string, error) {
+	head := make([]byte, sniffLength)
+	n, err := f.ReadAt(head, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("error while reading upload file: %w", err)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	if err != nil {
+		return "", fmt.Errorf("error while detecting content type: %w", err)
+	}
+
+	return mediaType, nil
+}
+
+// attachmentKey makes unguessable storage key of post attachment
+func attachmentKey(postID int) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error while making attachment key: %w", err)
+	}
+
+	return fmt.Sprintf("posts/%d/%s", postID, hex.EncodeToString(b)), nil
+}
+
+// uploadError reports exceeded request size limit as too large payload
+func uploadError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return fmt.Errorf("%w: request must be at most %d bytes", custom_errors.ErrPayloadTooLarge, maxBytesErr.Limit)
+	}
+
+	return fmt.Errorf("%w: %s", custom_errors.ErrInvalidBody, err)
+}
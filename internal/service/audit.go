@@ -0,0 +1,140 @@
+package service
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/TRAD3R/tlog"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/policy"
+	"github.com/trad3r/hskills/apirest/internal/repository/filters"
+	"github.com/trad3r/hskills/apirest/internal/repository/postgres"
+)
+
+var (
+	auditEntities = []string{filters.AuditEntityUser, filters.AuditEntityPost}
+	auditActions  = []string{"create", "update", "delete", "restore", "purge"}
+)
+
+type IAuditService interface {
+	AuditHistory(entity string, id int, req *http.Request) (*models.Page[models.AuditEntry], error)
+	AuditList(req *http.Request) (*models.Page[models.AuditEntry], error)
+}
+
+type AuditService struct {
+	repo   postgres.IAuditRepository
+	posts  postgres.IPostRepository
+	logger *tlog.Logger
+}
+
+func NewAuditService(logger *tlog.Logger, db *pgxpool.Pool) IAuditService {
+	return &AuditService{
+		repo:   postgres.NewAuditRepository(db),
+		posts:  postgres.NewPostRepository(db),
+		logger: logger,
+	}
+}
+
+// AuditHistory returns changes of one user or post, newest first.
+// History of a user is shown to that user and admins, history of a post to those who can see the post,
+// moderators see history of deleted posts as well
+func (s *AuditService) AuditHistory(entity string, id int, req *http.Request) (*models.Page[models.AuditEntry], error) {
+	ctx := req.Context()
+
+	filter, err := parseAuditPage(req.URL.Query())
+	if err != nil {
+		return nil, err
+	}
+
+	actor := policy.ActorFrom(ctx)
+
+	switch entity {
+	case filters.AuditEntityUser:
+		if err := policy.EditUser(actor, id); err != nil {
+			return nil, err
+		}
+	case filters.AuditEntityPost:
+		if policy.ModeratePosts(actor) != nil {
+			if err := existingPost(ctx, s.posts, id); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	filter.Entity = entity
+	filter.EntityID = id
+
+	return s.repo.GetList(ctx, filter)
+}
+
+// AuditList returns audit entries matching request filters, newest first
+func (s *AuditService) AuditList(req *http.Request) (*models.Page[models.AuditEntry], error) {
+	ctx := req.Context()
+
+	filter, err := parseAuditFilters(req.URL.Query())
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetList(ctx, filter)
+}
+
+// parseAuditPage reads limit and cursor params
+func parseAuditPage(query url.Values) (filters.AuditFilter, error) {
+	var filter filters.AuditFilter
+	var err error
+
+	filter.Limit, filter.Cursor, err = parsePage(query)
+
+	return filter, err
+}
+
+func parseAuditFilters(query url.Values) (filters.AuditFilter, error) {
+	filter, err := parseAuditPage(query)
+	if err != nil {
+		return filter, err
+	}
+
+	filter.Entity = query.Get("entity")
+	if len(filter.Entity) > 0 && !slices.Contains(auditEntities, filter.Entity) {
+		return filter, custom_errors.NewValidationError("entity", "must be one of user, post")
+	}
+
+	entityID := query.Get("entity_id")
+	if len(entityID) > 0 {
+		filter.EntityID, err = strconv.Atoi(entityID)
+		if err != nil || filter.EntityID < 1 {
+			return filter, custom_errors.NewValidationError("entity_id", "must be a positive integer")
+		}
+	}
+
+	filter.Action = query.Get("action")
+	if len(filter.Action) > 0 && !slices.Contains(auditActions, filter.Action) {
+		return filter, custom_errors.NewValidationError("action", "must be one of create, update, delete, restore, purge")
+	}
+
+	filter.Actor = query.Get("actor")
+
+	from := query.Get("from")
+	if len(from) > 0 {
+		filter.From, err = time.Parse(time.RFC3339, from)
+		if err != nil {
+			return filter, custom_errors.NewValidationError("from", "invalid time format, expected RFC 3339")
+		}
+	}
+
+	to := query.Get("to")
+	if len(to) > 0 {
+		filter.To, err = time.Parse(time.RFC3339, to)
+		if err != nil {
+			return filter, custom_errors.NewValidationError("to", "invalid time format, expected RFC 3339")
+		}
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,310 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/TRAD3R/tlog"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/trad3r/hskills/apirest/internal/auth"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/repository/filters"
+	"github.com/trad3r/hskills/apirest/internal/repository/postgres"
+	"github.com/trad3r/hskills/apirest/internal/reqctx"
+)
+
+var loginRegexp = regexp.MustCompile(`^[a-z0-9._-]+$`)
+
+const (
+	minLoginLength    = 3
+	maxLoginLength    = 64
+	minPasswordLength = 8
+	maxPasswordLength = 128
+)
+
+type IAuthService interface {
+	Login(req *http.Request) (*models.TokenPair, error)
+	Refresh(req *http.Request) (*models.TokenPair, error)
+	Logout(req *http.Request) error
+	Authenticate(ctx context.Context, token string) (*models.User, error)
+	ChangePassword(userId int, req *http.Request) error
+}
+
+type AuthService struct {
+	repo       postgres.IAuthRepository
+	issuer     *auth.Issuer
+	refreshTTL time.Duration
+	// dummyHash is verified for unknown logins, so they take as long as wrong passwords
+	dummyHash string
+	logger    *tlog.Logger
+}
+
+func NewAuthService(logger *tlog.Logger, db *pgxpool.Pool, issuer *auth.Issuer, refreshTTL time.Duration) (IAuthService, error) {
+	dummyHash, err := auth.HashPassword("dummy password")
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthService{
+		repo:       postgres.NewAuthRepository(db),
+		issuer:     issuer,
+		refreshTTL: refreshTTL,
+		dummyHash:  dummyHash,
+		logger:     logger,
+	}, nil
+}
+
+// Login checks login and password and starts a new session
+func (s *AuthService) Login(req *http.Request) (*models.TokenPair, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), time.Second*10)
+	defer cancel()
+
+	var loginReq filters.LoginRequest
+
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decodeJSON(body, &loginReq, false); err != nil {
+		return nil, err
+	}
+
+	credentials, err := s.repo.FindCredentials(ctx, strings.ToLower(loginReq.Login))
+	if err != nil {
+		return nil, err
+	}
+
+	hash := s.dummyHash
+	if credentials != nil {
+		hash = credentials.PasswordHash
+	}
+
+	match, err := auth.VerifyPassword(loginReq.Password, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if credentials == nil || !match {
+		return nil, custom_errors.ErrInvalidCredentials
+	}
+
+	refreshToken, refreshHash, err := auth.NewRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	session, err := s.repo.AddSession(ctx, credentials.UserID, refreshHash, now.Add(s.refreshTTL))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.tokenPair(session, refreshToken, now)
+}
+
+// Refresh exchanges refresh token for a new token pair, every refresh token is accepted once
+func (s *AuthService) Refresh(req *http.Request) (*models.TokenPair, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), time.Second*10)
+	defer cancel()
+
+	var refreshReq filters.RefreshRequest
+
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decodeJSON(body, &refreshReq, false); err != nil {
+		return nil, err
+	}
+
+	if len(refreshReq.RefreshToken) == 0 {
+		return nil, custom_errors.NewValidationError("refresh_token", "is required")
+	}
+
+	refreshToken, refreshHash, err := auth.NewRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	session, err := s.repo.Rotate(ctx, auth.HashToken(refreshReq.RefreshToken), refreshHash, now.Add(s.refreshTTL))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.tokenPair(session, refreshToken, now)
+}
+
+// Logout revokes session of refresh token, access tokens of the session stop working too
+func (s *AuthService) Logout(req *http.Request) error {
+	ctx, cancel := context.WithTimeout(req.Context(), time.Second*10)
+	defer cancel()
+
+	var refreshReq filters.RefreshRequest
+
+	body, err := readBody(req)
+	if err != nil {
+		return err
+	}
+
+	if err := decodeJSON(body, &refreshReq, false); err != nil {
+		return err
+	}
+
+	if len(refreshReq.RefreshToken) == 0 {
+		return custom_errors.NewValidationError("refresh_token", "is required")
+	}
+
+	return s.repo.RevokeSession(ctx, auth.HashToken(refreshReq.RefreshToken))
+}
+
+// Authenticate returns user of access token, the token session must be active
+func (s *AuthService) Authenticate(ctx context.Context, token string) (*models.User, error) {
+	claims, err := s.issuer.Parse(token)
+	if err != nil {
+		return nil, custom_errors.ErrInvalidToken
+	}
+
+	user, err := s.repo.SessionUser(ctx, claims.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil || user.ID != claims.UserID() {
+		return nil, custom_errors.ErrInvalidToken
+	}
+
+	return user, nil
+}
+
+// ChangePassword sets new password of the authenticated user and ends all user sessions
+func (s *AuthService) ChangePassword(userId int, req *http.Request) error {
+	ctx, cancel := context.WithTimeout(req.Context(), time.Second*10)
+	defer cancel()
+
+	user := reqctx.User(ctx)
+	if user == nil {
+		return custom_errors.ErrUnauthorized
+	}
+
+	if user.ID != userId {
+		return fmt.Errorf("%w: password of another user", custom_errors.ErrForbidden)
+	}
+
+	var changeReq filters.PasswordChangeRequest
+
+	body, err := readBody(req)
+	if err != nil {
+		return err
+	}
+
+	if err := decodeJSON(body, &changeReq, false); err != nil {
+		return err
+	}
+
+	if err := validatePassword(changeReq.Password); err != nil {
+		return err
+	}
+
+	credentials, err := s.repo.FindCredentialsById(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	if credentials == nil {
+		return custom_errors.ErrUserNotFound
+	}
+
+	match, err := auth.VerifyPassword(changeReq.CurrentPassword, credentials.PasswordHash)
+	if err != nil {
+		return err
+	}
+
+	if !match {
+		return custom_errors.ErrInvalidCredentials
+	}
+
+	hash, err := auth.HashPassword(changeReq.Password)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.SetPassword(ctx, userId, hash)
+}
+
+func (s *AuthService) tokenPair(session *models.Session, refreshToken string, now time.Time) (*models.TokenPair, error) {
+	accessToken, err := s.issuer.Issue(session.UserID, session.ID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.issuer.TTL().Seconds()),
+	}, nil
+}
+
+// newCredentials validates login and password of a new user and returns normalized login and password hash
+func newCredentials(login string, password string) (string, string, error) {
+	login = strings.ToLower(login)
+	if err := validateLogin(login); err != nil {
+		return "", "", err
+	}
+
+	if err := validatePassword(password); err != nil {
+		return "", "", err
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return "", "", err
+	}
+
+	return login, hash, nil
+}
+
+// currentUser returns user of access token, API keys and anonymous callers do not act as users
+func currentUser(ctx context.Context) (*models.User, error) {
+	if user := reqctx.User(ctx); user != nil {
+		return user, nil
+	}
+
+	if reqctx.Scopes(ctx) != nil {
+		return nil, fmt.Errorf("%w: API key can not act as a user", custom_errors.ErrForbidden)
+	}
+
+	return nil, custom_errors.ErrUnauthorized
+}
+
+func validateLogin(login string) error {
+	if len(login) < minLoginLength || len(login) > maxLoginLength {
+		return custom_errors.NewValidationError("login", fmt.Sprintf("must be %d to %d characters", minLoginLength, maxLoginLength))
+	}
+
+	if !loginRegexp.MatchString(login) {
+		return custom_errors.NewValidationError("login", "must contain only latin letters, digits, dots, dashes and underscores")
+	}
+
+	return nil
+}
+
+func validatePassword(password string) error {
+	length := utf8.RuneCountInString(password)
+	if length < minPasswordLength || length > maxPasswordLength {
+		return custom_errors.NewValidationError("password", fmt.Sprintf("must be %d to %d characters", minPasswordLength, maxPasswordLength))
+	}
+
+	return nil
+}
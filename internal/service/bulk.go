@@ -0,0 +1,81 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/models"
+)
+
+const maxBulkItems = 5000
+
+// parseBulkMode returns true for all-or-nothing mode, which is the default one
+func parseBulkMode(query url.Values) (bool, error) {
+	switch query.Get("mode") {
+	case "", "atomic":
+		return true, nil
+	case "partial":
+		return false, nil
+	}
+
+	return false, custom_errors.NewValidationError("mode", "must be atomic or partial")
+}
+
+// decodeBulk reads JSON array of bulk items from request body
+func decodeBulk[T any](req *http.Request, items *[]T) error {
+	body, err := readBody(req)
+	if err != nil {
+		return err
+	}
+
+	if err := decodeJSON(body, items, false); err != nil {
+		return err
+	}
+
+	if len(*items) == 0 || len(*items) > maxBulkItems {
+		return custom_errors.NewValidationError("items", fmt.Sprintf("must contain from 1 to %d items", maxBulkItems))
+	}
+
+	return nil
+}
+
+// bulkApply validates every item and writes valid ones, results are aligned with items.
+// In atomic mode nothing is written when any item is invalid
+func bulkApply(count int, atomic bool, validate func(i int) error, write func(valid []int) ([]error, error)) ([]models.BulkResult, error) {
+	results := make([]models.BulkResult, count)
+	valid := make([]int, 0, count)
+
+	for i := 0; i < count; i++ {
+		if err := validate(i); err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		valid = append(valid, i)
+	}
+
+	if atomic && len(valid) < count {
+		for _, i := range valid {
+			results[i].Err = custom_errors.ErrBulkAborted
+		}
+
+		return results, nil
+	}
+
+	if len(valid) == 0 {
+		return results, nil
+	}
+
+	errs, err := write(valid)
+	if err != nil {
+		return nil, err
+	}
+
+	for j, i := range valid {
+		results[i].Err = errs[j]
+	}
+
+	return results, nil
+}
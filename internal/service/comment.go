@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/TRAD3R/tlog"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/policy"
+	"github.com/trad3r/hskills/apirest/internal/repository/filters"
+	"github.com/trad3r/hskills/apirest/internal/repository/postgres"
+)
+
+const maxCommentBodyLength = 4000
+
+type ICommentService interface {
+	CommentList(postID int, req *http.Request) (*models.Page[models.Comment], error)
+	CommentAdd(postID int, req *http.Request) (*models.Comment, error)
+	CommentUpdate(id int, req *http.Request) (int, error)
+	CommentDelete(id int, req *http.Request) error
+}
+
+type CommentService struct {
+	repo   postgres.ICommentRepository
+	posts  postgres.IPostRepository
+	logger *tlog.Logger
+}
+
+func NewCommentService(logger *tlog.Logger, db *pgxpool.Pool) ICommentService {
+	return &CommentService{
+		repo:   postgres.NewCommentRepository(db),
+		posts:  postgres.NewPostRepository(db),
+		logger: logger,
+	}
+}
+
+// CommentList returns page of top level comments of the post, parent param lists replies of that comment instead
+func (s *CommentService) CommentList(postID int, req *http.Request) (*models.Page[models.Comment], error) {
+	ctx := req.Context()
+
+	query := req.URL.Query()
+
+	filter := filters.CommentFilter{PostID: postID}
+
+	var err error
+	filter.Limit, filter.Cursor, err = parsePage(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if parent := query.Get("parent"); len(parent) > 0 {
+		parentID, err := strconv.Atoi(parent)
+		if err != nil || parentID < 1 {
+			return nil, custom_errors.NewValidationError("parent", "must be a positive integer")
+		}
+
+		filter.ParentID = &parentID
+	}
+
+	if err := existingPost(ctx, s.posts, postID); err != nil {
+		return nil, err
+	}
+
+	if filter.ParentID != nil {
+		if _, err := s.parentComment(ctx, postID, *filter.ParentID, "parent"); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.repo.GetList(ctx, filter)
+}
+
+// CommentAdd adds comment of the authenticated user to the post, parent_id makes it a reply to comment of the same post
+func (s *CommentService) CommentAdd(postID int, req *http.Request) (*models.Comment, error) {
+	ctx := req.Context()
+
+	author, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var commentAddReq filters.CommentAddRequest
+
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decodeJSON(body, &commentAddReq, false); err != nil {
+		return nil, err
+	}
+
+	commentAddReq.Body = strings.TrimSpace(commentAddReq.Body)
+	if err := validateCommentBody(commentAddReq.Body); err != nil {
+		return nil, err
+	}
+
+	if err := existingPost(ctx, s.posts, postID); err != nil {
+		return nil, err
+	}
+
+	if commentAddReq.ParentID != nil {
+		if _, err := s.parentComment(ctx, postID, *commentAddReq.ParentID, "parent_id"); err != nil {
+			return nil, err
+		}
+	}
+
+	comment := &models.Comment{
+		PostID:   postID,
+		ParentID: commentAddReq.ParentID,
+		Author:   &models.User{ID: author.ID, Name: author.Name},
+		Body:     commentAddReq.Body,
+	}
+
+	if err := s.repo.Add(ctx, comment); err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// CommentUpdate changes comment body and returns new comment version
+func (s *CommentService) CommentUpdate(id int, req *http.Request) (int, error) {
+	ctx := req.Context()
+
+	if err := s.authorizeComment(ctx, id, policy.EditComment); err != nil {
+		return 0, err
+	}
+
+	var commentUpdateReq filters.CommentUpdateRequest
+
+	body, err := readBody(req)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := decodeJSON(body, &commentUpdateReq, false); err != nil {
+		return 0, err
+	}
+
+	commentUpdateReq.Body = strings.TrimSpace(commentUpdateReq.Body)
+	if err := validateCommentBody(commentUpdateReq.Body); err != nil {
+		return 0, err
+	}
+
+	commentUpdateReq.IfVersions, err = ifMatchVersions(req, id)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.repo.Update(ctx, id, commentUpdateReq)
+}
+
+// CommentDelete removes comment with its replies
+func (s *CommentService) CommentDelete(id int, req *http.Request) error {
+	ctx := req.Context()
+
+	if err := s.authorizeComment(ctx, id, policy.DeleteComment); err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ctx, id)
+}
+
+// authorizeComment checks caller may change comment with decide. Comments of posts hidden from the caller
+// are frozen like the post itself, only moderators may still change them
+func (s *CommentService) authorizeComment(ctx context.Context, id int, decide func(policy.Actor, int) error) error {
+	comment, err := s.repo.FindById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if comment == nil {
+		return custom_errors.ErrCommentNotFound
+	}
+
+	actor := policy.ActorFrom(ctx)
+	if policy.ModeratePosts(actor) != nil {
+		if err := existingPost(ctx, s.posts, comment.PostID); err != nil {
+			return err
+		}
+	}
+
+	return decide(actor, comment.Author.ID)
+}
+
+// parentComment returns comment that must belong to the post, field is reported otherwise
+func (s *CommentService) parentComment(ctx context.Context, postID int, id int, field string) (*models.Comment, error) {
+	comment, err := s.repo.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if comment == nil || comment.PostID != postID {
+		return nil, custom_errors.NewValidationError(field, "comment is not found in the post")
+	}
+
+	return comment, nil
+}
+
+func validateCommentBody(body string) error {
+	if len(body) == 0 {
+		return custom_errors.NewValidationError("body", "must not be empty")
+	}
+
+	if utf8.RuneCountInString(body) > maxCommentBodyLength {
+		return custom_errors.NewValidationError("body", fmt.Sprintf("must be at most %d characters", maxCommentBodyLength))
+	}
+
+	return nil
+}
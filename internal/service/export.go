@@ -0,0 +1,168 @@
+package service
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/trad3r/hskills/apirest/internal/dataformat"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/policy"
+	"github.com/trad3r/hskills/apirest/internal/repository/filters"
+)
+
+// PostExport writes every post matching request filters to w in format, pagination params are ignored
+func (r *PostService) PostExport(req *http.Request, format string, w io.Writer) error {
+	filter, err := parsePostFilters(req.URL.Query())
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeDeleted(req.Context(), filter.Deleted, policy.ModeratePosts); err != nil {
+		return err
+	}
+
+	writer, err := dataformat.New(format, w, postColumns(filter), searchKeys(filter, filter.Projection.Keys()))
+	if err != nil {
+		return err
+	}
+
+	if err := r.repo.Export(req.Context(), filter, writer.Write); err != nil {
+		return err
+	}
+
+	return writer.Flush()
+}
+
+// UserExport writes every user matching request filters to w in format, pagination params are ignored
+func (s *UserService) UserExport(req *http.Request, format string, w io.Writer) error {
+	filter, err := parseUserFilters(req.URL.Query())
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeDeleted(req.Context(), filter.Deleted, policy.ManageUsers); err != nil {
+		return err
+	}
+
+	writer, err := dataformat.New(format, w, userColumns(filter), filter.Projection.Keys())
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Export(req.Context(), filter, writer.Write); err != nil {
+		return err
+	}
+
+	return writer.Flush()
+}
+
+// postColumns returns CSV columns of requested post fields, author is flattened
+func postColumns(filter filters.PostFilter) []dataformat.Column[models.Post] {
+	projection := filter.Projection
+
+	columns := []dataformat.Column[models.Post]{
+		{Name: "id", Value: func(p models.Post) string { return strconv.Itoa(p.ID) }},
+	}
+
+	if projection.HasField("subject") {
+		columns = append(columns, dataformat.Column[models.Post]{Name: "subject", Value: func(p models.Post) string { return p.Subject }})
+	}
+
+	if projection.HasField("slug") {
+		columns = append(columns, dataformat.Column[models.Post]{Name: "slug", Value: func(p models.Post) string { return p.Slug }})
+	}
+
+	if projection.HasField("body") {
+		columns = append(columns, dataformat.Column[models.Post]{Name: "body", Value: func(p models.Post) string { return p.Body }})
+	}
+
+	if projection.HasField("created_at") {
+		columns = append(columns, dataformat.Column[models.Post]{Name: "created_at", Value: func(p models.Post) string { return formatTime(p.CreatedAt) }})
+	}
+
+	if projection.HasField("updated_at") {
+		columns = append(columns, dataformat.Column[models.Post]{Name: "updated_at", Value: func(p models.Post) string { return formatTime(p.UpdatedAt) }})
+	}
+
+	if projection.HasField("status") {
+		columns = append(columns, dataformat.Column[models.Post]{Name: "status", Value: func(p models.Post) string { return p.Status }})
+	}
+
+	if projection.HasField("publish_at") {
+		columns = append(columns, dataformat.Column[models.Post]{Name: "publish_at", Value: func(p models.Post) string { return formatTime(p.PublishAt) }})
+	}
+
+	if projection.HasField("comment_count") {
+		columns = append(columns, dataformat.Column[models.Post]{Name: "comment_count", Value: func(p models.Post) string { return strconv.Itoa(p.CommentCount) }})
+	}
+
+	if projection.HasField("tags") {
+		columns = append(columns, dataformat.Column[models.Post]{Name: "tags", Value: func(p models.Post) string { return strings.Join(p.Tags, ",") }})
+	}
+
+	if filter.Deleted != filters.DeletedExclude && projection.HasField("deleted_at") {
+		columns = append(columns, dataformat.Column[models.Post]{Name: "deleted_at", Value: func(p models.Post) string { return formatTime(p.DeletedAt) }})
+	}
+
+	if projection.Includes("author") {
+		columns = append(columns,
+			dataformat.Column[models.Post]{Name: "author_id", Value: func(p models.Post) string { return strconv.Itoa(p.Author.ID) }},
+			dataformat.Column[models.Post]{Name: "author_name", Value: func(p models.Post) string { return p.Author.Name }},
+		)
+	}
+
+	if len(filter.Query) > 0 {
+		columns = append(columns,
+			dataformat.Column[models.Post]{Name: "rank", Value: func(p models.Post) string { return strconv.FormatFloat(p.Rank, 'f', -1, 64) }},
+			dataformat.Column[models.Post]{Name: "snippet", Value: func(p models.Post) string { return p.Snippet }},
+		)
+	}
+
+	return columns
+}
+
+// userColumns returns CSV columns of requested user fields
+func userColumns(filter filters.UserFilter) []dataformat.Column[models.User] {
+	projection := filter.Projection
+
+	columns := []dataformat.Column[models.User]{
+		{Name: "id", Value: func(u models.User) string { return strconv.Itoa(u.ID) }},
+	}
+
+	if projection.HasField("name") {
+		columns = append(columns, dataformat.Column[models.User]{Name: "name", Value: func(u models.User) string { return u.Name }})
+	}
+
+	if projection.HasField("phonenumber") {
+		columns = append(columns, dataformat.Column[models.User]{Name: "phonenumber", Value: func(u models.User) string { return u.Phonenumber }})
+	}
+
+	if projection.HasField("created_at") {
+		columns = append(columns, dataformat.Column[models.User]{Name: "created_at", Value: func(u models.User) string { return formatTime(u.CreatedAt) }})
+	}
+
+	if projection.HasField("updated_at") {
+		columns = append(columns, dataformat.Column[models.User]{Name: "updated_at", Value: func(u models.User) string { return formatTime(u.UpdatedAt) }})
+	}
+
+	if filter.Deleted != filters.DeletedExclude && projection.HasField("deleted_at") {
+		columns = append(columns, dataformat.Column[models.User]{Name: "deleted_at", Value: func(u models.User) string { return formatTime(u.DeletedAt) }})
+	}
+
+	if projection.HasField("post_count") {
+		columns = append(columns, dataformat.Column[models.User]{Name: "post_count", Value: func(u models.User) string { return strconv.Itoa(u.PostCount) }})
+	}
+
+	return columns
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Format(time.RFC3339Nano)
+}
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/dataformat"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/policy"
+	"github.com/trad3r/hskills/apirest/internal/repository/filters"
+)
+
+const (
+	maxImportRows        = 100000
+	maxExternalIDLength  = 64
+	maxPostSubjectLength = 255
+)
+
+// ImportOptions controls import, dry run only validates rows
+type ImportOptions struct {
+	DryRun bool
+	Atomic bool
+}
+
+var userImportFields = []dataformat.Field[filters.UserImportItem]{
+	{Name: "external_id", Set: func(u *filters.UserImportItem, v string) error { u.ExternalID = v; return nil }},
+	{Name: "name", Set: func(u *filters.UserImportItem, v string) error { u.Name = v; return nil }},
+	{Name: "phonenumber", Set: func(u *filters.UserImportItem, v string) error { u.Phonenumber = v; return nil }},
+}
+
+var postImportFields = []dataformat.Field[filters.PostImportItem]{
+	{Name: "external_id", Set: func(p *filters.PostImportItem, v string) error { p.ExternalID = v; return nil }},
+	{Name: "subject", Set: func(p *filters.PostImportItem, v string) error { p.Subject = v; return nil }},
+	{Name: "body", Set: func(p *filters.PostImportItem, v string) error { p.Body = v; return nil }},
+	{Name: "author", Set: func(p *filters.PostImportItem, v string) error {
+		if len(v) == 0 {
+			return nil
+		}
+
+		var err error
+		p.Author, err = strconv.Atoi(v)
+
+		return err
+	}},
+	{Name: "author_external_id", Set: func(p *filters.PostImportItem, v string) error { p.AuthorExternalID = v; return nil }},
+}
+
+// UserImport imports users from CSV or NDJSON request body
+func (s *UserService) UserImport(req *http.Request) (*models.ImportReport, error) {
+	if err := policy.ManageUsers(policy.ActorFrom(req.Context())); err != nil {
+		return nil, err
+	}
+
+	format, opts, err := parseImportRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.UserImportFrom(req.Context(), format, req.Body, opts)
+}
+
+// UserImportFrom imports users from r in format
+func (s *UserService) UserImportFrom(ctx context.Context, format string, r io.Reader, opts ImportOptions) (*models.ImportReport, error) {
+	reader, err := dataformat.NewReader(format, r, userImportFields)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", custom_errors.ErrInvalidBody, err)
+	}
+
+	return importRows(reader, opts, func(item *filters.UserImportItem) error {
+		if err := validateExternalID(item.ExternalID); err != nil {
+			return err
+		}
+
+		if err := validateUserName(item.Name); err != nil {
+			return err
+		}
+
+		return validatePhonenumber(item.Phonenumber)
+	}, func(items []filters.UserImportItem) ([]models.ImportResult, error) {
+		return s.repo.Import(ctx, items, opts.Atomic)
+	})
+}
+
+// ImportPosts imports posts from CSV or NDJSON request body
+func (up *UserPostService) ImportPosts(req *http.Request) (*models.ImportReport, error) {
+	if err := policy.ModeratePosts(policy.ActorFrom(req.Context())); err != nil {
+		return nil, err
+	}
+
+	format, opts, err := parseImportRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return up.ImportPostsFrom(req.Context(), format, req.Body, opts)
+}
+
+// ImportPostsFrom imports posts from r in format, authors must exist
+func (up *UserPostService) ImportPostsFrom(ctx context.Context, format string, r io.Reader, opts ImportOptions) (*models.ImportReport, error) {
+	reader, err := dataformat.NewReader(format, r, postImportFields)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", custom_errors.ErrInvalidBody, err)
+	}
+
+	authorIDs := make(map[int]bool)
+	externalIDs := make(map[string]int)
+
+	return importRows(reader, opts, func(item *filters.PostImportItem) error {
+		if err := validateExternalID(item.ExternalID); err != nil {
+			return err
+		}
+
+		if len(item.Subject) == 0 {
+			return custom_errors.NewValidationError("subject", "is required")
+		}
+
+		if utf8.RuneCountInString(item.Subject) > maxPostSubjectLength {
+			return custom_errors.NewValidationError("subject", fmt.Sprintf("must be at most %d characters", maxPostSubjectLength))
+		}
+
+		switch {
+		case len(item.AuthorExternalID) > 0:
+			id, ok := externalIDs[item.AuthorExternalID]
+			if !ok {
+				var err error
+				id, err = up.u.FindIDByExternalID(ctx, item.AuthorExternalID)
+				if err != nil {
+					return err
+				}
+
+				externalIDs[item.AuthorExternalID] = id
+			}
+
+			if id == 0 {
+				return custom_errors.NewValidationError("author_external_id", "does not exist")
+			}
+
+			item.Author = id
+		case item.Author > 0:
+			exists, ok := authorIDs[item.Author]
+			if !ok {
+				author, err := up.u.FindByID(ctx, item.Author)
+				if err != nil {
+					return err
+				}
+
+				exists = author != nil
+				authorIDs[item.Author] = exists
+			}
+
+			if !exists {
+				return custom_errors.NewValidationError("author", "does not exist")
+			}
+		default:
+			return custom_errors.NewValidationError("author", "is required")
+		}
+
+		return nil
+	}, func(items []filters.PostImportItem) ([]models.ImportResult, error) {
+		return up.p.PostBulkImport(ctx, items, opts.Atomic)
+	})
+}
+
+// importRows reads and validates every row, then writes valid rows unless it's a dry run.
+// In atomic mode nothing is written when any row fails
+func importRows[T any](reader dataformat.Reader[T], opts ImportOptions, validate func(item *T) error, write func(items []T) ([]models.ImportResult, error)) (*models.ImportReport, error) {
+	report := &models.ImportReport{DryRun: opts.DryRun, Errors: []models.ImportError{}}
+
+	var items []T
+	var lines []int
+
+	for {
+		item, line, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var rowErr *dataformat.RowError
+		if err != nil && !errors.As(err, &rowErr) {
+			return nil, fmt.Errorf("%w: %s", custom_errors.ErrInvalidBody, err)
+		}
+
+		report.Total++
+		if report.Total > maxImportRows {
+			return nil, custom_errors.NewValidationError("body", fmt.Sprintf("must contain at most %d rows", maxImportRows))
+		}
+
+		if err == nil {
+			err = validate(&item)
+		}
+
+		if err != nil {
+			addImportError(report, line, err)
+			continue
+		}
+
+		items = append(items, item)
+		lines = append(lines, line)
+	}
+
+	if opts.DryRun || len(items) == 0 || (opts.Atomic && report.Failed > 0) {
+		report.Aborted = opts.Atomic && report.Failed > 0
+		return report, nil
+	}
+
+	results, err := write(items)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, res := range results {
+		switch {
+		case errors.Is(res.Err, custom_errors.ErrBulkAborted):
+			report.Aborted = true
+		case res.Err != nil:
+			addImportError(report, lines[i], res.Err)
+		case res.Created:
+			report.Created++
+		default:
+			report.Updated++
+		}
+	}
+
+	if report.Aborted {
+		report.Created, report.Updated = 0, 0
+	}
+
+	return report, nil
+}
+
+// addImportError reports failed row, unexpected errors are logged and hidden from report
+func addImportError(report *models.ImportReport, line int, err error) {
+	report.Failed++
+
+	var validationErr *custom_errors.ValidationError
+	var rowErr *dataformat.RowError
+
+	switch {
+	case errors.As(err, &validationErr):
+		report.Errors = append(report.Errors, models.ImportError{Line: line, Field: validationErr.Field, Message: validationErr.Message})
+	case errors.As(err, &rowErr):
+		report.Errors = append(report.Errors, models.ImportError{Line: line, Message: rowErr.Err.Error()})
+	case errors.Is(err, custom_errors.ErrConflict):
+		report.Errors = append(report.Errors, models.ImportError{Line: line, Message: err.Error()})
+	default:
+		log.Printf("import error on line %d: %v", line, err)
+		report.Errors = append(report.Errors, models.ImportError{Line: line, Message: "internal error"})
+	}
+}
+
+// parseImportRequest reads import format from Content-Type and options from query
+func parseImportRequest(req *http.Request) (string, ImportOptions, error) {
+	var opts ImportOptions
+
+	format, err := requestMediaType(req)
+	if err != nil {
+		return "", opts, err
+	}
+
+	if format != dataformat.MIMECSV && format != dataformat.MIMENDJSON {
+		return "", opts, fmt.Errorf("%w: %s, expected %s or %s", custom_errors.ErrUnsupportedMediaType, format, dataformat.MIMECSV, dataformat.MIMENDJSON)
+	}
+
+	if req.Body == nil {
+		return "", opts, fmt.Errorf("%w: body is empty", custom_errors.ErrInvalidBody)
+	}
+
+	opts.Atomic, err = parseBulkMode(req.URL.Query())
+	if err != nil {
+		return "", opts, err
+	}
+
+	opts.DryRun, err = parseBool(req.URL.Query(), "dry_run")
+	if err != nil {
+		return "", opts, err
+	}
+
+	return format, opts, nil
+}
+
+func parseBool(query url.Values, name string) (bool, error) {
+	value := query.Get(name)
+	if len(value) == 0 {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, custom_errors.NewValidationError(name, "must be true or false")
+	}
+
+	return b, nil
+}
+
+func validateExternalID(externalID string) error {
+	if len(externalID) > maxExternalIDLength {
+		return custom_errors.NewValidationError("external_id", fmt.Sprintf("must be at most %d characters", maxExternalIDLength))
+	}
+
+	return nil
+}
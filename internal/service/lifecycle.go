@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/policy"
+	"github.com/trad3r/hskills/apirest/internal/repository/filters"
+)
+
+// PostPublish publishes the post now, or schedules it when publish_at of the body is in the future.
+// Returns new post version
+func (r *PostService) PostPublish(id int, req *http.Request) (int, error) {
+	ctx := req.Context()
+
+	var publishReq struct {
+		PublishAt *time.Time `json:"publish_at"`
+	}
+
+	body, err := readBody(req)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := decodeJSON(body, &publishReq, false); err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	transition := filters.PostTransition{
+		From:      []string{models.PostStatusDraft, models.PostStatusScheduled, models.PostStatusArchived},
+		To:        models.PostStatusPublished,
+		PublishAt: &now,
+	}
+
+	if publishReq.PublishAt != nil {
+		if !publishReq.PublishAt.After(now) {
+			return 0, custom_errors.NewValidationError("publish_at", "must be in the future")
+		}
+
+		// archived post is published again only immediately
+		transition.From = []string{models.PostStatusDraft, models.PostStatusScheduled}
+		transition.To = models.PostStatusScheduled
+		transition.PublishAt = publishReq.PublishAt
+	}
+
+	return r.transition(ctx, id, transition, req)
+}
+
+// PostUnpublish moves published or scheduled post back to drafts and returns new post version
+func (r *PostService) PostUnpublish(id int, req *http.Request) (int, error) {
+	ctx := req.Context()
+
+	return r.transition(ctx, id, filters.PostTransition{
+		From: []string{models.PostStatusPublished, models.PostStatusScheduled},
+		To:   models.PostStatusDraft,
+	}, req)
+}
+
+// PostArchive hides published post from public lists and returns new post version
+func (r *PostService) PostArchive(id int, req *http.Request) (int, error) {
+	ctx := req.Context()
+
+	post, err := findPost(ctx, r.repo, id)
+	if err != nil {
+		return 0, err
+	}
+
+	// publication time is kept, the transition fails if the post is changed meanwhile
+	return r.transition(ctx, id, filters.PostTransition{
+		From:       []string{models.PostStatusPublished},
+		To:         models.PostStatusArchived,
+		PublishAt:  post.PublishAt,
+		IfVersions: []int{post.Version},
+	}, req)
+}
+
+func (r *PostService) transition(ctx context.Context, id int, transition filters.PostTransition, req *http.Request) (int, error) {
+	if err := authorizePost(ctx, r.repo, id, filters.DeletedExclude, policy.EditPost); err != nil {
+		return 0, err
+	}
+
+	versions, err := ifMatchVersions(req, id)
+	if err != nil {
+		return 0, err
+	}
+
+	if versions != nil {
+		transition.IfVersions = versions
+	}
+
+	return r.repo.Transition(ctx, id, transition)
+}
+
+// newPostStatus resolves status of created post, post is published at once unless it is a draft or scheduled
+func newPostStatus(status string, publishAt *time.Time, now time.Time) (string, *time.Time, error) {
+	if len(status) == 0 {
+		status = models.PostStatusPublished
+		if publishAt != nil {
+			status = models.PostStatusScheduled
+		}
+	}
+
+	switch status {
+	case models.PostStatusDraft:
+		if publishAt != nil {
+			return "", nil, custom_errors.NewValidationError("publish_at", "is allowed for scheduled posts only")
+		}
+
+		return status, nil, nil
+	case models.PostStatusPublished:
+		if publishAt != nil {
+			return "", nil, custom_errors.NewValidationError("publish_at", "is allowed for scheduled posts only")
+		}
+
+		return status, &now, nil
+	case models.PostStatusScheduled:
+		if publishAt == nil || !publishAt.After(now) {
+			return "", nil, custom_errors.NewValidationError("publish_at", "must be in the future")
+		}
+
+		return status, publishAt, nil
+	}
+
+	return "", nil, custom_errors.NewValidationError("status", "must be one of draft, scheduled, published")
+}
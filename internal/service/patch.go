@@ -0,0 +1,129 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/jsonpatch"
+)
+
+const (
+	mediaTypeJSON       = "application/json"
+	mediaTypeMergePatch = "application/merge-patch+json"
+	mediaTypeJSONPatch  = "application/json-patch+json"
+)
+
+// decodePatch decodes PATCH request body into update request v according to request content type.
+// JSON patch is applied to the document returned by current, replaced is true in that case
+// and fields absent in v have been removed by the patch
+func decodePatch(req *http.Request, v any, current func() (any, error)) (replaced bool, err error) {
+	mediaType, err := requestMediaType(req)
+	if err != nil {
+		return false, err
+	}
+
+	body, err := readBody(req)
+	if err != nil {
+		return false, err
+	}
+
+	switch mediaType {
+	case mediaTypeJSON:
+		return false, decodeJSON(body, v, false)
+	case mediaTypeMergePatch:
+		return false, decodeJSON(body, v, true)
+	case mediaTypeJSONPatch:
+		doc, err := current()
+		if err != nil {
+			return false, err
+		}
+
+		docBody, err := json.Marshal(doc)
+		if err != nil {
+			return false, fmt.Errorf("failed to marshal document: %w", err)
+		}
+
+		patched, err := jsonpatch.Apply(docBody, body)
+		if err != nil {
+			if errors.Is(err, jsonpatch.ErrTestFailed) {
+				return false, fmt.Errorf("%w: %s", custom_errors.ErrConflict, err)
+			}
+
+			return false, custom_errors.NewValidationError("patch", err.Error())
+		}
+
+		if err := decodeJSON(patched, v, true); err != nil {
+			return false, custom_errors.NewValidationError("patch", err.Error())
+		}
+
+		return true, nil
+	}
+
+	return false, fmt.Errorf("%w: %s", custom_errors.ErrUnsupportedMediaType, mediaType)
+}
+
+// checkIfMatch verifies If-Match header allows current resource version
+func checkIfMatch(req *http.Request, id int, version int) error {
+	versions, err := ifMatchVersions(req, id)
+	if err != nil {
+		return err
+	}
+
+	if versions != nil && !slices.Contains(versions, version) {
+		return custom_errors.ErrPreconditionFailed
+	}
+
+	return nil
+}
+
+func requestMediaType(req *http.Request) (string, error) {
+	contentType := req.Header.Get("Content-Type")
+	if len(contentType) == 0 {
+		return mediaTypeJSON, nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", custom_errors.ErrUnsupportedMediaType, contentType)
+	}
+
+	return mediaType, nil
+}
+
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+
+	return body, nil
+}
+
+// decodeJSON unmarshals body into v, strict mode rejects unknown fields
+func decodeJSON(body []byte, v any, strict bool) error {
+	if len(body) == 0 {
+		return nil
+	}
+
+	d := json.NewDecoder(bytes.NewReader(body))
+	if strict {
+		d.DisallowUnknownFields()
+	}
+
+	if err := d.Decode(v); err != nil {
+		return fmt.Errorf("%w: %s", custom_errors.ErrInvalidBody, err)
+	}
+
+	return nil
+}
@@ -2,43 +2,67 @@ package service
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
+	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/TRAD3R/tlog"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/markdown"
 	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/policy"
 	"github.com/trad3r/hskills/apirest/internal/repository/filters"
 	"github.com/trad3r/hskills/apirest/internal/repository/postgres"
 )
 
+const maxSearchQueryLength = 256
+
 type IPostService interface {
-	PostList(req *http.Request) ([]models.Post, error)
-	PostAdd(ctx context.Context, subject string, body string, author models.User) error
-	PostUpdate(req *http.Request) error
+	PostList(req *http.Request) (*models.Page[models.Post], error)
+	PostListByAuthor(authorId int, req *http.Request) (*models.Page[models.Post], error)
+	PostExport(req *http.Request, format string, w io.Writer) error
+	PostGet(req *http.Request) (*models.Post, error)
+	PostGetBySlug(slug string, req *http.Request) (*models.Post, error)
+	PostAdd(ctx context.Context, postAddReq filters.PostAddRequest, author models.User) error
+	PostPreview(req *http.Request) (*models.PostPreview, error)
+	PostUpdate(req *http.Request) (int, error)
+	PostReplace(req *http.Request) (int, error)
 	PostDelete(req *http.Request) error
+	PostRestore(id int, req *http.Request) (int, error)
+	PostRevisions(id int, req *http.Request) (*models.Page[models.PostRevision], error)
+	PostRevision(id int, revision int, req *http.Request) (*models.PostRevision, error)
+	PostDiff(id int, req *http.Request) (*models.PostDiff, error)
+	PostRestoreRevision(id int, revision int, req *http.Request) (int, error)
+	PostPublish(id int, req *http.Request) (int, error)
+	PostUnpublish(id int, req *http.Request) (int, error)
+	PostArchive(id int, req *http.Request) (int, error)
+	PostBulkAdd(ctx context.Context, posts []*models.Post, atomic bool) ([]error, error)
+	PostBulkImport(ctx context.Context, items []filters.PostImportItem, atomic bool) ([]models.ImportResult, error)
+	PostBulkUpdate(req *http.Request) ([]models.BulkResult, error)
+	PostBulkDelete(req *http.Request) ([]models.BulkResult, error)
 }
 
 type PostService struct {
-	repo   postgres.IPostRepository
-	logger *tlog.Logger
+	repo     postgres.IPostRepository
+	renderer *markdown.Renderer
+	logger   *tlog.Logger
 }
 
-func NewPostService(logger *tlog.Logger, db *pgxpool.Pool) IPostService {
+func NewPostService(logger *tlog.Logger, db *pgxpool.Pool, renderer *markdown.Renderer) IPostService {
 	return &PostService{
-		repo:   postgres.NewPostRepository(db),
-		logger: logger,
+		repo:     postgres.NewPostRepository(db),
+		renderer: renderer,
+		logger:   logger,
 	}
 }
 
-func (r *PostService) PostList(req *http.Request) ([]models.Post, error) {
+func (r *PostService) PostList(req *http.Request) (*models.Page[models.Post], error) {
 	ctx, cancel := context.WithTimeout(req.Context(), time.Second*10)
 	defer cancel()
 
@@ -47,42 +71,268 @@ func (r *PostService) PostList(req *http.Request) ([]models.Post, error) {
 		return nil, err
 	}
 
-	return r.repo.GetList(ctx, filter)
+	bare, err := parseBare(req.URL.Query())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeDeleted(ctx, filter.Deleted, policy.ModeratePosts); err != nil {
+		return nil, err
+	}
+
+	render, err := parseRender(req)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := r.repo.GetList(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.renderPosts(ctx, page.Items, render); err != nil {
+		return nil, err
+	}
+
+	page.Fields = searchKeys(filter, filter.Projection.Keys())
+	page.Bare = bare
+
+	return page, nil
+}
+
+// PostListByAuthor lists posts of one author, author from query is ignored.
+// Unlike public lists it takes status param, so authors and moderators can list drafts
+func (r *PostService) PostListByAuthor(authorId int, req *http.Request) (*models.Page[models.Post], error) {
+	ctx, cancel := context.WithTimeout(req.Context(), time.Second*10)
+	defer cancel()
+
+	query := req.URL.Query()
+
+	filter, err := parsePostFilters(query)
+	if err != nil {
+		return nil, err
+	}
+
+	bare, err := parseBare(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeDeleted(ctx, filter.Deleted, policy.ModeratePosts); err != nil {
+		return nil, err
+	}
+
+	if query.Has("status") {
+		filter.Statuses, err = parseList(query.Get("status"), "status", models.PostStatuses)
+		if err != nil {
+			return nil, err
+		}
+
+		if slices.ContainsFunc(filter.Statuses, func(status string) bool { return status != models.PostStatusPublished }) {
+			if err := policy.ViewPost(policy.ActorFrom(ctx), authorId); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	render, err := parseRender(req)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.Authors = []int{authorId}
+
+	page, err := r.repo.GetList(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.renderPosts(ctx, page.Items, render); err != nil {
+		return nil, err
+	}
+
+	page.Fields = searchKeys(filter, filter.Projection.Keys())
+	page.Bare = bare
+
+	return page, nil
+}
+
+func (r *PostService) PostGet(req *http.Request) (*models.Post, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), time.Second*10)
+	defer cancel()
+
+	id, err := getIdFromPath(req.URL.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.postGet(ctx, id, req)
+}
+
+// PostGetBySlug returns post by its current or former slug, the slug of returned post is always current
+func (r *PostService) PostGetBySlug(slug string, req *http.Request) (*models.Post, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), time.Second*10)
+	defer cancel()
+
+	id, err := r.repo.FindIdBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	if id == 0 {
+		return nil, custom_errors.ErrPostNotFound
+	}
+
+	return r.postGet(ctx, id, req)
 }
 
-func (r *PostService) PostAdd(ctx context.Context, subject string, body string, author models.User) error {
+func (r *PostService) postGet(ctx context.Context, id int, req *http.Request) (*models.Post, error) {
+	render, err := parseRender(req)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := findPost(ctx, r.repo, id)
+	if err != nil {
+		return nil, err
+	}
+
+	posts := []models.Post{*post}
+	if err := r.renderPosts(ctx, posts, render); err != nil {
+		return nil, err
+	}
+
+	return &posts[0], nil
+}
+
+func (r *PostService) PostAdd(ctx context.Context, postAddReq filters.PostAddRequest, author models.User) error {
+	tags, err := normalizeTags(postAddReq.Tags, "tags")
+	if err != nil {
+		return err
+	}
+
+	status, publishAt, err := newPostStatus(postAddReq.Status, postAddReq.PublishAt, time.Now())
+	if err != nil {
+		return err
+	}
+
 	post := models.Post{
-		Subject: subject,
-		Body:    body,
-		Author:  author,
+		Subject:   postAddReq.Subject,
+		Body:      postAddReq.Body,
+		Tags:      tags,
+		Status:    status,
+		PublishAt: publishAt,
+		Author:    &author,
 	}
 
 	return r.repo.Add(ctx, &post)
 }
 
-func (r *PostService) PostUpdate(req *http.Request) error {
+func (r *PostService) PostUpdate(req *http.Request) (int, error) {
 	ctx, cancel := context.WithTimeout(req.Context(), time.Second*10)
 	defer cancel()
 
 	id, err := getIdFromPath(req.URL.Path)
 	if err != nil {
-		return err
+		return 0, err
+	}
+
+	if err := authorizePost(ctx, r.repo, id, filters.DeletedExclude, policy.EditPost); err != nil {
+		return 0, err
 	}
 
 	var postUpdateReq filters.PostUpdateRequest
 
-	if req.Body != nil {
-		reqBody, err := io.ReadAll(req.Body)
+	versions, err := ifMatchVersions(req, id)
+	if err != nil {
+		return 0, err
+	}
+
+	replaced, err := decodePatch(req, &postUpdateReq, func() (any, error) {
+		post, err := r.repo.FindById(ctx, id)
 		if err != nil {
-			log.Printf("Failed to read body: %v", err)
-			return errors.New("Failed to read body")
+			return nil, err
 		}
 
-		err = json.Unmarshal(reqBody, &postUpdateReq)
-		if err != nil {
-			log.Printf("Failed to unmarshal body: %v", err)
-			return errors.New("Failed to unmarshal body")
+		if post == nil {
+			return nil, custom_errors.ErrPostNotFound
+		}
+
+		if err := checkIfMatch(req, id, post.Version); err != nil {
+			return nil, err
 		}
+
+		// patched document is written only if nobody has changed the post meanwhile
+		versions = []int{post.Version}
+
+		return filters.PostReplaceRequest{Subject: post.Subject, Body: &post.Body, Tags: post.Tags}, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if replaced {
+		postUpdateReq.Subject = postUpdateReq.Subject.OrNull()
+		postUpdateReq.Body = postUpdateReq.Body.OrNull()
+		postUpdateReq.Tags = postUpdateReq.Tags.OrNull()
+	}
+
+	if err := validatePostUpdate(&postUpdateReq); err != nil {
+		return 0, err
+	}
+
+	postUpdateReq.IfVersions = versions
+
+	return r.repo.Update(ctx, id, postUpdateReq)
+}
+
+func (r *PostService) PostReplace(req *http.Request) (int, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), time.Second*10)
+	defer cancel()
+
+	id, err := getIdFromPath(req.URL.Path)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := authorizePost(ctx, r.repo, id, filters.DeletedExclude, policy.EditPost); err != nil {
+		return 0, err
+	}
+
+	var postReplaceReq filters.PostReplaceRequest
+
+	body, err := readBody(req)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := decodeJSON(body, &postReplaceReq, false); err != nil {
+		return 0, err
+	}
+
+	if len(postReplaceReq.Subject) == 0 {
+		return 0, custom_errors.NewValidationError("subject", "is required")
+	}
+
+	tags, err := normalizeTags(postReplaceReq.Tags, "tags")
+	if err != nil {
+		return 0, err
+	}
+
+	versions, err := ifMatchVersions(req, id)
+	if err != nil {
+		return 0, err
+	}
+
+	postUpdateReq := filters.PostUpdateRequest{
+		Subject:    filters.Value(postReplaceReq.Subject),
+		Body:       filters.Null[string](),
+		Tags:       filters.Value(tags),
+		IfVersions: versions,
+	}
+
+	if postReplaceReq.Body != nil {
+		postUpdateReq.Body = filters.Value(*postReplaceReq.Body)
 	}
 
 	return r.repo.Update(ctx, id, postUpdateReq)
@@ -97,7 +347,215 @@ func (r *PostService) PostDelete(req *http.Request) error {
 		return err
 	}
 
-	return r.repo.Delete(ctx, id)
+	if err := authorizePost(ctx, r.repo, id, filters.DeletedExclude, policy.DeletePost); err != nil {
+		return err
+	}
+
+	if err := r.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	r.renderer.Forget(id)
+
+	return nil
+}
+
+// PostRestore brings deleted post back and returns new post version
+func (r *PostService) PostRestore(id int, req *http.Request) (int, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), time.Second*10)
+	defer cancel()
+
+	if err := authorizePost(ctx, r.repo, id, filters.DeletedInclude, policy.RestorePost); err != nil {
+		return 0, err
+	}
+
+	return r.repo.Restore(ctx, id)
+}
+
+func (r *PostService) PostBulkAdd(ctx context.Context, posts []*models.Post, atomic bool) ([]error, error) {
+	return r.repo.BulkAdd(ctx, posts, atomic)
+}
+
+func (r *PostService) PostBulkImport(ctx context.Context, items []filters.PostImportItem, atomic bool) ([]models.ImportResult, error) {
+	return r.repo.Import(ctx, items, atomic)
+}
+
+func (r *PostService) PostBulkUpdate(req *http.Request) ([]models.BulkResult, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), time.Minute)
+	defer cancel()
+
+	atomic, err := parseBulkMode(req.URL.Query())
+	if err != nil {
+		return nil, err
+	}
+
+	var items []filters.PostBulkUpdateItem
+	if err := decodeBulk(req, &items); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, len(items))
+	for i := range items {
+		ids[i] = items[i].ID
+	}
+
+	authors, err := r.repo.FindAuthorIds(ctx, ids, filters.DeletedExclude)
+	if err != nil {
+		return nil, err
+	}
+
+	actor := policy.ActorFrom(ctx)
+
+	results, err := bulkApply(len(items), atomic, func(i int) error {
+		if items[i].ID < 1 {
+			return custom_errors.NewValidationError("id", "must be a positive integer")
+		}
+
+		// missing posts are reported by the repository
+		if authorId, ok := authors[items[i].ID]; ok {
+			if err := policy.EditPost(actor, authorId); err != nil {
+				return err
+			}
+		}
+
+		if !items[i].Subject.Set && !items[i].Body.Set && !items[i].Tags.Set {
+			return custom_errors.NewValidationError("body", "nothing to update")
+		}
+
+		return validatePostUpdate(&items[i].PostUpdateRequest)
+	}, func(valid []int) ([]error, error) {
+		batch := make([]filters.PostBulkUpdateItem, 0, len(valid))
+		for _, i := range valid {
+			batch = append(batch, items[i])
+		}
+
+		return r.repo.BulkUpdate(ctx, batch, atomic)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range results {
+		results[i].ID = items[i].ID
+	}
+
+	return results, nil
+}
+
+func (r *PostService) PostBulkDelete(req *http.Request) ([]models.BulkResult, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), time.Minute)
+	defer cancel()
+
+	atomic, err := parseBulkMode(req.URL.Query())
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int
+	if err := decodeBulk(req, &ids); err != nil {
+		return nil, err
+	}
+
+	authors, err := r.repo.FindAuthorIds(ctx, ids, filters.DeletedExclude)
+	if err != nil {
+		return nil, err
+	}
+
+	actor := policy.ActorFrom(ctx)
+
+	results, err := bulkApply(len(ids), atomic, func(i int) error {
+		if ids[i] < 1 {
+			return custom_errors.NewValidationError("id", "must be a positive integer")
+		}
+
+		if authorId, ok := authors[ids[i]]; ok {
+			return policy.DeletePost(actor, authorId)
+		}
+
+		return nil
+	}, func(valid []int) ([]error, error) {
+		batch := make([]int, 0, len(valid))
+		for _, i := range valid {
+			batch = append(batch, ids[i])
+		}
+
+		return r.repo.BulkDelete(ctx, batch, atomic)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range results {
+		results[i].ID = ids[i]
+	}
+
+	return results, nil
+}
+
+// findPost returns live post or ErrPostNotFound, unpublished post is found for its author and moderators only
+func findPost(ctx context.Context, posts postgres.IPostRepository, id int) (*models.Post, error) {
+	post, err := posts.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if post == nil {
+		return nil, custom_errors.ErrPostNotFound
+	}
+
+	if post.Status != models.PostStatusPublished && policy.ViewPost(policy.ActorFrom(ctx), post.Author.ID) != nil {
+		return nil, custom_errors.ErrPostNotFound
+	}
+
+	return post, nil
+}
+
+// existingPost checks that post exists, is not deleted and is visible to the caller
+func existingPost(ctx context.Context, posts postgres.IPostRepository, id int) error {
+	_, err := findPost(ctx, posts, id)
+	return err
+}
+
+// authorizePost checks caller may change post selected by deleted filter with decide
+func authorizePost(ctx context.Context, posts postgres.IPostRepository, id int, deleted filters.Deleted, decide func(policy.Actor, int) error) error {
+	authors, err := posts.FindAuthorIds(ctx, []int{id}, deleted)
+	if err != nil {
+		return err
+	}
+
+	authorId, ok := authors[id]
+	if !ok {
+		return custom_errors.ErrPostNotFound
+	}
+
+	return decide(policy.ActorFrom(ctx), authorId)
+}
+
+// validatePostUpdate checks request fields, tags are normalized in place and null tags clear them
+func validatePostUpdate(postReq *filters.PostUpdateRequest) error {
+	if postReq.Subject.Set && (postReq.Subject.Null || len(postReq.Subject.Value) == 0) {
+		return custom_errors.NewValidationError("subject", "is required")
+	}
+
+	if postReq.Tags.Set {
+		tags, err := normalizeTags(postReq.Tags.Value, "tags")
+		if err != nil {
+			return err
+		}
+
+		postReq.Tags = filters.Value(tags)
+	}
+
+	return nil
+}
+
+// searchKeys keeps search rank and snippet in sparse response
+func searchKeys(filter filters.PostFilter, keys []string) []string {
+	if len(keys) == 0 || len(filter.Query) == 0 {
+		return keys
+	}
+
+	return append(keys, "rank", "snippet")
 }
 
 func parsePostFilters(query url.Values) (filters.PostFilter, error) {
@@ -108,17 +566,15 @@ func parsePostFilters(query url.Values) (filters.PostFilter, error) {
 	if len(from) > 0 {
 		filter.FromCreatedAt, err = time.Parse("2006-01-02", from)
 		if err != nil {
-			log.Printf("failed to parse from: %v", err)
-			return filter, errors.New("invalid format for from")
+			return filter, custom_errors.NewValidationError("from", "invalid date format, expected YYYY-MM-DD")
 		}
 	}
 
 	to := query.Get("to")
 	if len(to) > 0 {
-		filter.ToCreatedAt, err = time.Parse("2006-01-02", from)
+		filter.ToCreatedAt, err = time.Parse("2006-01-02", to)
 		if err != nil {
-			log.Printf("failed to parse to: %v", err)
-			return filter, errors.New("invalid format for to")
+			return filter, custom_errors.NewValidationError("to", "invalid date format, expected YYYY-MM-DD")
 		}
 	}
 
@@ -127,8 +583,7 @@ func parsePostFilters(query url.Values) (filters.PostFilter, error) {
 		for _, authorId := range strings.Split(authors, ",") {
 			author, err := strconv.Atoi(authorId)
 			if err != nil {
-				log.Printf("failed to parse author: %v", err)
-				continue
+				return filter, custom_errors.NewValidationError("author", "must be a comma separated list of IDs")
 			}
 
 			filter.Authors = append(filter.Authors, author)
@@ -138,24 +593,59 @@ func parsePostFilters(query url.Values) (filters.PostFilter, error) {
 	offset := query.Get("offset")
 	if len(offset) > 0 {
 		filter.Offset, err = strconv.Atoi(offset)
-		if err != nil {
-			log.Printf("failed to parse offset: %v", err)
-			return filter, errors.New("invalid format for offset")
+		if err != nil || filter.Offset < 0 {
+			return filter, custom_errors.NewValidationError("offset", "must be a non-negative integer")
 		}
 	}
 
 	limit := query.Get("limit")
 	if len(limit) > 0 {
 		filter.Limit, err = strconv.Atoi(limit)
-		if err != nil {
-			log.Printf("failed to parse limit: %v", err)
-			return filter, errors.New("invalid format for limit")
+		if err != nil || filter.Limit < 1 {
+			return filter, custom_errors.NewValidationError("limit", "must be a positive integer")
 		}
+
+		filter.Limit = min(filter.Limit, maxLimit)
 	} else {
-		filter.Limit = 10
+		filter.Limit = defaultLimit
+	}
+
+	filter.Cursor, err = parseCursor(query)
+	if err != nil {
+		return filter, err
 	}
 
+	filter.WithTotal = query.Get("total") == "true"
+
 	filter.Subject = query.Get("subject")
 
+	filter.Query = strings.TrimSpace(query.Get("q"))
+	if len(filter.Query) > maxSearchQueryLength {
+		return filter, custom_errors.NewValidationError("q", fmt.Sprintf("must be at most %d characters", maxSearchQueryLength))
+	}
+
+	filter.Sort, err = parseSort(query.Get("sort"))
+	if err != nil {
+		return filter, err
+	}
+
+	// author is embedded by default as it was before include param
+	filter.Projection, err = parseProjection(query, postFields, postIncludes, postIncludes)
+	if err != nil {
+		return filter, err
+	}
+
+	filter.Deleted, err = parseDeleted(query)
+	if err != nil {
+		return filter, err
+	}
+
+	// public lists show published posts only
+	filter.Statuses = []string{models.PostStatusPublished}
+
+	if err := parseTagFilter(query, &filter); err != nil {
+		return filter, err
+	}
+
 	return filter, nil
 }
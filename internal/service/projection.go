@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/policy"
+	"github.com/trad3r/hskills/apirest/internal/repository/filters"
+)
+
+const (
+	defaultEmbeddedPosts = 3
+	maxEmbeddedPosts     = 20
+)
+
+var (
+	postFields   = []string{"id", "subject", "slug", "body", "body_html", "created_at", "updated_at", "deleted_at", "status", "publish_at", "tags", "comment_count", "reactions"}
+	postIncludes = []string{"author"}
+	userFields   = []string{"id", "name", "phonenumber", "created_at", "updated_at", "deleted_at", "post_count"}
+	userIncludes = []string{"posts"}
+)
+
+// parseProjection reads fields and include params, defaultInclude is used when include is absent
+func parseProjection(query url.Values, fields, includes, defaultInclude []string) (filters.Projection, error) {
+	var projection filters.Projection
+	var err error
+
+	projection.Fields, err = parseList(query.Get("fields"), "fields", fields)
+	if err != nil {
+		return projection, err
+	}
+
+	if !query.Has("include") {
+		projection.Include = defaultInclude
+		return projection, nil
+	}
+
+	projection.Include, err = parseList(query.Get("include"), "include", includes)
+	if err != nil {
+		return projection, err
+	}
+
+	return projection, nil
+}
+
+// parseList splits comma separated list checking every value is allowed
+func parseList(value, param string, allowed []string) ([]string, error) {
+	if len(value) == 0 {
+		return nil, nil
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if !slices.Contains(allowed, item) {
+			return nil, custom_errors.NewValidationError(param, "must be a comma separated list of "+strings.Join(allowed, ", "))
+		}
+
+		if !slices.Contains(list, item) {
+			list = append(list, item)
+		}
+	}
+
+	return list, nil
+}
+
+// parseSort reads comma separated sort fields, leading minus means descending order.
+// Allowed fields are checked by repository
+func parseSort(value string) ([]filters.SortField, error) {
+	if len(value) == 0 {
+		return nil, nil
+	}
+
+	var sort []filters.SortField
+	for _, item := range strings.Split(value, ",") {
+		field := filters.SortField{Field: strings.TrimSpace(item)}
+		if strings.HasPrefix(field.Field, "-") {
+			field.Field = field.Field[1:]
+			field.Desc = true
+		}
+
+		if len(field.Field) == 0 {
+			return nil, custom_errors.NewValidationError("sort", "must be a comma separated list of fields, prefixed with - for descending order")
+		}
+
+		if slices.ContainsFunc(sort, func(f filters.SortField) bool { return f.Field == field.Field }) {
+			return nil, custom_errors.NewValidationError("sort", "field "+field.Field+" is repeated")
+		}
+
+		sort = append(sort, field)
+	}
+
+	return sort, nil
+}
+
+// parseDeleted reads which deleted rows are listed, deleted rows are hidden by default
+func parseDeleted(query url.Values) (filters.Deleted, error) {
+	deleted := filters.Deleted(query.Get("deleted"))
+	switch deleted {
+	case filters.DeletedExclude, filters.DeletedInclude, filters.DeletedOnly:
+		return deleted, nil
+	}
+
+	return "", custom_errors.NewValidationError("deleted", "must be one of include, only")
+}
+
+// authorizeDeleted checks caller may list deleted rows with decide, live rows are listed for everyone
+func authorizeDeleted(ctx context.Context, deleted filters.Deleted, decide func(policy.Actor) error) error {
+	if deleted == filters.DeletedExclude {
+		return nil
+	}
+
+	return decide(policy.ActorFrom(ctx))
+}
+
+// parsePostsLimit reads amount of embedded latest posts
+func parsePostsLimit(query url.Values) (int, error) {
+	value := query.Get("posts_limit")
+	if len(value) == 0 {
+		return defaultEmbeddedPosts, nil
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 {
+		return 0, custom_errors.NewValidationError("posts_limit", "must be a positive integer")
+	}
+
+	return min(limit, maxEmbeddedPosts), nil
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/TRAD3R/tlog"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/repository/postgres"
+)
+
+type IReactionService interface {
+	ReactionPut(postID int, reactionType string, req *http.Request) (*models.PostReactions, error)
+	ReactionDelete(postID int, reactionType string, req *http.Request) (*models.PostReactions, error)
+}
+
+type ReactionService struct {
+	repo   postgres.IReactionRepository
+	posts  postgres.IPostRepository
+	logger *tlog.Logger
+}
+
+func NewReactionService(logger *tlog.Logger, db *pgxpool.Pool) IReactionService {
+	return &ReactionService{
+		repo:   postgres.NewReactionRepository(db),
+		posts:  postgres.NewPostRepository(db),
+		logger: logger,
+	}
+}
+
+// ReactionPut adds reaction of the authenticated user to the post, putting it again changes nothing
+func (s *ReactionService) ReactionPut(postID int, reactionType string, req *http.Request) (*models.PostReactions, error) {
+	ctx := req.Context()
+
+	userID, err := s.reactionUser(ctx, postID, reactionType)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.repo.Put(ctx, postID, userID, reactionType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PostReactions{PostID: postID, Reactions: counts}, nil
+}
+
+// ReactionDelete removes reaction of the authenticated user from the post
+func (s *ReactionService) ReactionDelete(postID int, reactionType string, req *http.Request) (*models.PostReactions, error) {
+	ctx := req.Context()
+
+	userID, err := s.reactionUser(ctx, postID, reactionType)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.repo.Delete(ctx, postID, userID, reactionType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PostReactions{PostID: postID, Reactions: counts}, nil
+}
+
+// reactionUser validates reaction type and returns the authenticated user reacting to existing post
+func (s *ReactionService) reactionUser(ctx context.Context, postID int, reactionType string) (int, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if !slices.Contains(models.ReactionTypes, reactionType) {
+		return 0, custom_errors.NewValidationError("type", "must be one of "+strings.Join(models.ReactionTypes, ", "))
+	}
+
+	if err := existingPost(ctx, s.posts, postID); err != nil {
+		return 0, err
+	}
+
+	return user.ID, nil
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/models"
+)
+
+const renderHTML = "html"
+
+// PostPreview renders body of the request the same way saved posts are rendered, nothing is stored
+func (r *PostService) PostPreview(req *http.Request) (*models.PostPreview, error) {
+	var previewReq struct {
+		Body string `json:"body"`
+	}
+
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decodeJSON(body, &previewReq, false); err != nil {
+		return nil, err
+	}
+
+	html, err := r.renderer.Render(previewReq.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PostPreview{BodyHTML: html}, nil
+}
+
+// renderPosts fills body_html of posts when the request asks for rendering
+func (r *PostService) renderPosts(ctx context.Context, posts []models.Post, render bool) error {
+	if !render {
+		return nil
+	}
+
+	for i := range posts {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		html, err := r.renderer.RenderPost(posts[i].ID, posts[i].Body)
+		if err != nil {
+			return err
+		}
+
+		posts[i].BodyHTML = html
+	}
+
+	return nil
+}
+
+// parseRender tells whether post bodies are rendered to HTML, either by render=html query param
+// or by render=html parameter of accepted media type, e.g. Accept: application/json; render=html
+func parseRender(req *http.Request) (bool, error) {
+	query := req.URL.Query()
+	if query.Has("render") {
+		if query.Get("render") != renderHTML {
+			return false, custom_errors.NewValidationError("render", "must be html")
+		}
+
+		return true, nil
+	}
+
+	for _, accept := range req.Header.Values("Accept") {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			_, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+			if err == nil && params["render"] == renderHTML {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
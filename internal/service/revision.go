@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/policy"
+	"github.com/trad3r/hskills/apirest/internal/repository/filters"
+	"github.com/trad3r/hskills/apirest/internal/textdiff"
+)
+
+// PostRevisions returns page of post revisions, newest first
+func (r *PostService) PostRevisions(id int, req *http.Request) (*models.Page[models.PostRevision], error) {
+	ctx := req.Context()
+
+	var filter filters.RevisionFilter
+	var err error
+
+	filter.Limit, filter.Cursor, err = parsePage(req.URL.Query())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := existingPost(ctx, r.repo, id); err != nil {
+		return nil, err
+	}
+
+	return r.repo.Revisions(ctx, id, filter)
+}
+
+// PostRevision returns one revision of a post
+func (r *PostService) PostRevision(id int, revision int, req *http.Request) (*models.PostRevision, error) {
+	ctx := req.Context()
+
+	if err := existingPost(ctx, r.repo, id); err != nil {
+		return nil, err
+	}
+
+	return r.findRevision(ctx, id, revision)
+}
+
+// PostDiff returns line diff of subject and body between revisions from and to of query params
+func (r *PostService) PostDiff(id int, req *http.Request) (*models.PostDiff, error) {
+	ctx := req.Context()
+
+	query := req.URL.Query()
+
+	from, err := parseRevision(query, "from")
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := parseRevision(query, "to")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := existingPost(ctx, r.repo, id); err != nil {
+		return nil, err
+	}
+
+	fromRevision, err := r.findRevision(ctx, id, from)
+	if err != nil {
+		return nil, err
+	}
+
+	toRevision, err := r.findRevision(ctx, id, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PostDiff{
+		From:    from,
+		To:      to,
+		Subject: textdiff.Lines(fromRevision.Subject, toRevision.Subject),
+		Body:    textdiff.Lines(fromRevision.Body, toRevision.Body),
+	}, nil
+}
+
+// PostRestoreRevision makes the revision current again as a new revision and returns new post version
+func (r *PostService) PostRestoreRevision(id int, revision int, req *http.Request) (int, error) {
+	ctx := req.Context()
+
+	if err := authorizePost(ctx, r.repo, id, filters.DeletedExclude, policy.EditPost); err != nil {
+		return 0, err
+	}
+
+	versions, err := ifMatchVersions(req, id)
+	if err != nil {
+		return 0, err
+	}
+
+	return r.repo.RestoreRevision(ctx, id, revision, versions)
+}
+
+func (r *PostService) findRevision(ctx context.Context, id int, revision int) (*models.PostRevision, error) {
+	rev, err := r.repo.Revision(ctx, id, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	if rev == nil {
+		return nil, custom_errors.ErrRevisionNotFound
+	}
+
+	return rev, nil
+}
+
+// parseRevision reads required revision number param
+func parseRevision(query url.Values, param string) (int, error) {
+	revision, err := strconv.Atoi(query.Get(param))
+	if err != nil || revision < 1 {
+		return 0, custom_errors.NewValidationError(param, "must be a positive revision number")
+	}
+
+	return revision, nil
+}
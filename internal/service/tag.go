@@ -0,0 +1,177 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/TRAD3R/tlog"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/repository/filters"
+	"github.com/trad3r/hskills/apirest/internal/repository/postgres"
+)
+
+const (
+	maxTagLength = 32
+	maxPostTags  = 10
+)
+
+var tagRegexp = regexp.MustCompile(`^[\p{L}\p{N}][\p{L}\p{N}_-]*$`)
+
+type ITagService interface {
+	TagList(req *http.Request) (*models.Page[models.Tag], error)
+	TagRename(name string, req *http.Request) error
+	TagMerge(name string, req *http.Request) error
+}
+
+type TagService struct {
+	repo   postgres.ITagRepository
+	logger *tlog.Logger
+}
+
+func NewTagService(logger *tlog.Logger, db *pgxpool.Pool) ITagService {
+	return &TagService{
+		repo:   postgres.NewTagRepository(db),
+		logger: logger,
+	}
+}
+
+// TagList returns most used tags, prefix param limits them to tags starting with it
+func (s *TagService) TagList(req *http.Request) (*models.Page[models.Tag], error) {
+	ctx := req.Context()
+
+	query := req.URL.Query()
+
+	filter := filters.TagFilter{Limit: maxLimit, Prefix: strings.ToLower(strings.TrimSpace(query.Get("prefix")))}
+	if limit := query.Get("limit"); len(limit) > 0 {
+		var err error
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit < 1 {
+			return nil, custom_errors.NewValidationError("limit", "must be a positive integer")
+		}
+
+		filter.Limit = min(filter.Limit, maxLimit)
+	}
+
+	tags, err := s.repo.GetList(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Page[models.Tag]{Items: tags}, nil
+}
+
+// TagRename changes tag name for every post
+func (s *TagService) TagRename(name string, req *http.Request) error {
+	ctx := req.Context()
+
+	var renameReq struct {
+		Name string `json:"name"`
+	}
+
+	if err := decodeTagRequest(req, &renameReq); err != nil {
+		return err
+	}
+
+	newName, err := normalizeTag(renameReq.Name, "name")
+	if err != nil {
+		return err
+	}
+
+	return s.repo.Rename(ctx, strings.ToLower(name), newName)
+}
+
+// TagMerge moves posts of tag to the tag from request body and removes the merged tag
+func (s *TagService) TagMerge(name string, req *http.Request) error {
+	ctx := req.Context()
+
+	var mergeReq struct {
+		Into string `json:"into"`
+	}
+
+	if err := decodeTagRequest(req, &mergeReq); err != nil {
+		return err
+	}
+
+	into, err := normalizeTag(mergeReq.Into, "into")
+	if err != nil {
+		return err
+	}
+
+	name = strings.ToLower(name)
+	if name == into {
+		return custom_errors.NewValidationError("into", "must differ from merged tag")
+	}
+
+	return s.repo.Merge(ctx, name, into)
+}
+
+func decodeTagRequest(req *http.Request, v any) error {
+	body, err := readBody(req)
+	if err != nil {
+		return err
+	}
+
+	return decodeJSON(body, v, false)
+}
+
+// normalizeTags lowercases tags and drops duplicates, field is reported on invalid tag
+func normalizeTags(tags []string, field string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag, err := normalizeTag(tag, field)
+		if err != nil {
+			return nil, err
+		}
+
+		if !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+
+	if len(normalized) > maxPostTags {
+		return nil, custom_errors.NewValidationError(field, fmt.Sprintf("must have at most %d tags", maxPostTags))
+	}
+
+	return normalized, nil
+}
+
+func normalizeTag(tag string, field string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+
+	if len([]rune(tag)) > maxTagLength || !tagRegexp.MatchString(tag) {
+		return "", custom_errors.NewValidationError(field, fmt.Sprintf("tag must be 1 to %d letters, digits, - or _ starting with letter or digit", maxTagLength))
+	}
+
+	return tag, nil
+}
+
+// parseTagFilter reads tag and tag_mode params, posts having any of tags are selected by default
+func parseTagFilter(query url.Values, filter *filters.PostFilter) error {
+	tags := query.Get("tag")
+	if len(tags) == 0 {
+		return nil
+	}
+
+	var err error
+	filter.Tags, err = normalizeTags(strings.Split(tags, ","), "tag")
+	if err != nil {
+		return err
+	}
+
+	switch query.Get("tag_mode") {
+	case "", "any":
+	case "all":
+		filter.TagsAll = true
+	default:
+		return custom_errors.NewValidationError("tag_mode", "must be one of any, all")
+	}
+
+	return nil
+}
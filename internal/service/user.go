@@ -3,32 +3,57 @@ package service
 import (
 	"context"
 	"encoding/json"
-	"errors"
+	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"net/url"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/TRAD3R/tlog"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/etag"
 	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/pagination"
+	"github.com/trad3r/hskills/apirest/internal/policy"
 	"github.com/trad3r/hskills/apirest/internal/repository/filters"
 	"github.com/trad3r/hskills/apirest/internal/repository/postgres"
 )
 
+var phonenumberRegexp = regexp.MustCompile(`^\+?[0-9]+$`)
+
 var (
 	defaultLimit = 10
+	maxLimit     = 100
+)
+
+const (
+	maxUserNameLength    = 30
+	maxPhonenumberLength = 12
 )
 
 type IUserService interface {
-	UserList(req *http.Request) ([]models.User, error)
+	UserList(req *http.Request) (*models.Page[models.User], error)
+	UserExport(req *http.Request, format string, w io.Writer) error
+	UserGet(userId int, req *http.Request) (*models.User, error)
 	UserAdd(req *http.Request) (*models.User, error)
-	UserUpdate(userId int, req *http.Request) error
+	UserUpdate(userId int, req *http.Request) (int, error)
+	UserReplace(userId int, req *http.Request) (int, error)
 	UserDelete(userId int, req *http.Request) error
+	UserRestore(userId int, req *http.Request) (int, error)
+	UserSetRole(userId int, req *http.Request) (int, error)
+	UserBulkAdd(req *http.Request) ([]models.BulkResult, error)
+	UserBulkUpdate(req *http.Request) ([]models.BulkResult, error)
+	UserBulkDelete(req *http.Request) ([]models.BulkResult, error)
+	UserImport(req *http.Request) (*models.ImportReport, error)
+	UserImportFrom(ctx context.Context, format string, r io.Reader, opts ImportOptions) (*models.ImportReport, error)
 	FindByID(ctx context.Context, userId int) (*models.User, error)
+	FindIDByExternalID(ctx context.Context, externalID string) (int, error)
 }
 
 type UserService struct {
@@ -43,50 +68,89 @@ func NewUserService(logger *tlog.Logger, db *pgxpool.Pool) IUserService {
 	}
 }
 
-func (s *UserService) UserList(req *http.Request) ([]models.User, error) {
-	ctx, cancel := context.WithCancel(req.Context())
-	defer cancel()
+func (s *UserService) UserList(req *http.Request) (*models.Page[models.User], error) {
+	ctx := req.Context()
 
 	filter, err := parseUserFilters(req.URL.Query())
 	if err != nil {
-		s.logger.Error(err.Error())
-		return nil, errors.New("invalid request params")
+		return nil, err
+	}
+
+	bare, err := parseBare(req.URL.Query())
+	if err != nil {
+		return nil, err
 	}
 
-	users, err := s.repo.GetList(ctx, filter)
+	if err := authorizeDeleted(ctx, filter.Deleted, policy.ManageUsers); err != nil {
+		return nil, err
+	}
+
+	page, err := s.repo.GetList(ctx, filter)
 	if err != nil {
-		s.logger.Error(err.Error())
-		return nil, errors.New("users not found")
+		return nil, err
 	}
 
-	return users, nil
+	page.Fields = filter.Projection.Keys()
+	page.Bare = bare
+
+	return page, nil
+}
+
+func (s *UserService) UserGet(userId int, req *http.Request) (*models.User, error) {
+	ctx := req.Context()
+
+	user, err := s.repo.FindById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, custom_errors.ErrUserNotFound
+	}
+
+	return user, nil
 }
 
 func (s *UserService) UserAdd(req *http.Request) (*models.User, error) {
-	ctx, cancel := context.WithCancel(req.Context())
-	defer cancel()
+	ctx := req.Context()
 
 	var userAddReq filters.UserAddRequest
 
 	if req.Body != nil {
 		reqBody, err := io.ReadAll(req.Body)
 		if err != nil {
-			log.Printf("Failed to read body: %v", err)
-			return nil, errors.New("Failed to read body")
+			return nil, fmt.Errorf("failed to read body: %w", err)
 		}
 
 		err = json.Unmarshal(reqBody, &userAddReq)
 		if err != nil {
-			log.Printf("Failed to unmarshal body: %v", err)
-			return nil, errors.New("Failed to unmarshal body")
+			return nil, fmt.Errorf("%w: %s", custom_errors.ErrInvalidBody, err)
 		}
 	}
 
+	if err := validateUserName(userAddReq.Name); err != nil {
+		return nil, err
+	}
+
+	if err := validatePhonenumber(userAddReq.Phonenumber); err != nil {
+		return nil, err
+	}
+
 	user := &models.User{
 		Name:        userAddReq.Name,
 		Phonenumber: userAddReq.Phonenumber,
 	}
 
+	if len(userAddReq.Login) > 0 || len(userAddReq.Password) > 0 {
+		login, hash, err := newCredentials(userAddReq.Login, userAddReq.Password)
+		if err != nil {
+			return nil, err
+		}
+
+		user.Login = login
+		user.PasswordHash = hash
+	}
+
 	err := s.repo.Add(ctx, user)
 	if err != nil {
 		return nil, err
@@ -95,40 +159,293 @@ func (s *UserService) UserAdd(req *http.Request) (*models.User, error) {
 	return user, nil
 }
 
-func (s *UserService) UserUpdate(userId int, req *http.Request) error {
-	ctx, cancel := context.WithCancel(req.Context())
-	defer cancel()
+func (s *UserService) UserUpdate(userId int, req *http.Request) (int, error) {
+	ctx := req.Context()
+
+	if err := policy.EditUser(policy.ActorFrom(ctx), userId); err != nil {
+		return 0, err
+	}
 
 	var userUpdateReq filters.UserUpdateRequest
 
-	if req.Body != nil {
-		reqBody, err := io.ReadAll(req.Body)
+	versions, err := ifMatchVersions(req, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	replaced, err := decodePatch(req, &userUpdateReq, func() (any, error) {
+		user, err := s.repo.FindById(ctx, userId)
 		if err != nil {
-			log.Printf("Failed to read body: %v", err)
-			return errors.New("Failed to read body")
+			return nil, err
 		}
 
-		err = json.Unmarshal(reqBody, &userUpdateReq)
-		if err != nil {
-			log.Printf("Failed to unmarshal body: %v", err)
-			return errors.New("Failed to unmarshal body")
+		if user == nil {
+			return nil, custom_errors.ErrUserNotFound
 		}
+
+		if err := checkIfMatch(req, userId, user.Version); err != nil {
+			return nil, err
+		}
+
+		// patched document is written only if nobody has changed the user meanwhile
+		versions = []int{user.Version}
+
+		return filters.UserAddRequest{Name: user.Name, Phonenumber: user.Phonenumber}, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if replaced {
+		userUpdateReq.Name = userUpdateReq.Name.OrNull()
+		userUpdateReq.Phonenumber = userUpdateReq.Phonenumber.OrNull()
+	}
+
+	if err := validateUserUpdate(userUpdateReq); err != nil {
+		return 0, err
 	}
 
+	userUpdateReq.IfVersions = versions
+
 	return s.repo.Update(ctx, userId, userUpdateReq)
 }
 
+func (s *UserService) UserReplace(userId int, req *http.Request) (int, error) {
+	ctx := req.Context()
+
+	if err := policy.EditUser(policy.ActorFrom(ctx), userId); err != nil {
+		return 0, err
+	}
+
+	var userReplaceReq filters.UserAddRequest
+
+	body, err := readBody(req)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := decodeJSON(body, &userReplaceReq, false); err != nil {
+		return 0, err
+	}
+
+	if err := validateUserName(userReplaceReq.Name); err != nil {
+		return 0, err
+	}
+
+	if err := validatePhonenumber(userReplaceReq.Phonenumber); err != nil {
+		return 0, err
+	}
+
+	versions, err := ifMatchVersions(req, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.repo.Update(ctx, userId, filters.UserUpdateRequest{
+		Name:        filters.Value(userReplaceReq.Name),
+		Phonenumber: filters.Value(userReplaceReq.Phonenumber),
+		IfVersions:  versions,
+	})
+}
+
 func (s *UserService) UserDelete(userId int, req *http.Request) error {
-	ctx, cancel := context.WithCancel(req.Context())
-	defer cancel()
+	ctx := req.Context()
+
+	if err := policy.ManageUsers(policy.ActorFrom(ctx)); err != nil {
+		return err
+	}
 
 	return s.repo.Delete(ctx, userId)
 }
 
+// UserRestore brings deleted user back with its posts and returns new user version
+func (s *UserService) UserRestore(userId int, req *http.Request) (int, error) {
+	ctx := req.Context()
+
+	if err := policy.ManageUsers(policy.ActorFrom(ctx)); err != nil {
+		return 0, err
+	}
+
+	return s.repo.Restore(ctx, userId)
+}
+
+// UserSetRole changes role of another user and returns new user version
+func (s *UserService) UserSetRole(userId int, req *http.Request) (int, error) {
+	ctx := req.Context()
+
+	if err := policy.SetRole(policy.ActorFrom(ctx), userId); err != nil {
+		return 0, err
+	}
+
+	var roleReq filters.UserRoleRequest
+
+	body, err := readBody(req)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := decodeJSON(body, &roleReq, false); err != nil {
+		return 0, err
+	}
+
+	if !slices.Contains(models.UserRoles, roleReq.Role) {
+		return 0, custom_errors.NewValidationError("role", fmt.Sprintf("must be one of %s", strings.Join(models.UserRoles, ", ")))
+	}
+
+	return s.repo.SetRole(ctx, userId, roleReq.Role)
+}
+
+func (s *UserService) UserBulkAdd(req *http.Request) ([]models.BulkResult, error) {
+	ctx := req.Context()
+
+	if err := policy.ManageUsers(policy.ActorFrom(ctx)); err != nil {
+		return nil, err
+	}
+
+	atomic, err := parseBulkMode(req.URL.Query())
+	if err != nil {
+		return nil, err
+	}
+
+	var items []filters.UserAddRequest
+	if err := decodeBulk(req, &items); err != nil {
+		return nil, err
+	}
+
+	users := make([]*models.User, len(items))
+
+	results, err := bulkApply(len(items), atomic, func(i int) error {
+		if err := validateUserName(items[i].Name); err != nil {
+			return err
+		}
+
+		if err := validatePhonenumber(items[i].Phonenumber); err != nil {
+			return err
+		}
+
+		users[i] = &models.User{Name: items[i].Name, Phonenumber: items[i].Phonenumber}
+
+		return nil
+	}, func(valid []int) ([]error, error) {
+		batch := make([]*models.User, 0, len(valid))
+		for _, i := range valid {
+			batch = append(batch, users[i])
+		}
+
+		return s.repo.BulkAdd(ctx, batch, atomic)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range results {
+		if results[i].Err == nil {
+			results[i].ID = users[i].ID
+		}
+	}
+
+	return results, nil
+}
+
+func (s *UserService) UserBulkUpdate(req *http.Request) ([]models.BulkResult, error) {
+	ctx := req.Context()
+
+	atomic, err := parseBulkMode(req.URL.Query())
+	if err != nil {
+		return nil, err
+	}
+
+	var items []filters.UserBulkUpdateItem
+	if err := decodeBulk(req, &items); err != nil {
+		return nil, err
+	}
+
+	actor := policy.ActorFrom(ctx)
+
+	results, err := bulkApply(len(items), atomic, func(i int) error {
+		if items[i].ID < 1 {
+			return custom_errors.NewValidationError("id", "must be a positive integer")
+		}
+
+		if err := policy.EditUser(actor, items[i].ID); err != nil {
+			return err
+		}
+
+		if !items[i].Name.Set && !items[i].Phonenumber.Set {
+			return custom_errors.NewValidationError("body", "nothing to update")
+		}
+
+		return validateUserUpdate(items[i].UserUpdateRequest)
+	}, func(valid []int) ([]error, error) {
+		batch := make([]filters.UserBulkUpdateItem, 0, len(valid))
+		for _, i := range valid {
+			batch = append(batch, items[i])
+		}
+
+		return s.repo.BulkUpdate(ctx, batch, atomic)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range results {
+		results[i].ID = items[i].ID
+	}
+
+	return results, nil
+}
+
+func (s *UserService) UserBulkDelete(req *http.Request) ([]models.BulkResult, error) {
+	ctx := req.Context()
+
+	if err := policy.ManageUsers(policy.ActorFrom(ctx)); err != nil {
+		return nil, err
+	}
+
+	atomic, err := parseBulkMode(req.URL.Query())
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int
+	if err := decodeBulk(req, &ids); err != nil {
+		return nil, err
+	}
+
+	results, err := bulkApply(len(ids), atomic, func(i int) error {
+		if ids[i] < 1 {
+			return custom_errors.NewValidationError("id", "must be a positive integer")
+		}
+
+		return nil
+	}, func(valid []int) ([]error, error) {
+		batch := make([]int, 0, len(valid))
+		for _, i := range valid {
+			batch = append(batch, ids[i])
+		}
+
+		return s.repo.BulkDelete(ctx, batch, atomic)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range results {
+		results[i].ID = ids[i]
+	}
+
+	return results, nil
+}
+
 func (s *UserService) FindByID(ctx context.Context, userId int) (*models.User, error) {
 	return s.repo.FindById(ctx, userId)
 }
 
+// FindIDByExternalID returns user ID by external ID, 0 if user is not found
+func (s *UserService) FindIDByExternalID(ctx context.Context, externalID string) (int, error) {
+	return s.repo.FindIdByExternalId(ctx, externalID)
+}
+
 func parseUserFilters(query url.Values) (filters.UserFilter, error) {
 	var filter filters.UserFilter
 
@@ -136,8 +453,7 @@ func parseUserFilters(query url.Values) (filters.UserFilter, error) {
 	if len(from) > 0 {
 		filterFrom, err := time.Parse("2006-01-02", from)
 		if err != nil {
-			log.Printf("failed to parse from: %v", err)
-			return filter, errors.New("invalid format for from")
+			return filter, custom_errors.NewValidationError("from", "invalid date format, expected YYYY-MM-DD")
 		}
 
 		filter.FromCreatedAt = &filterFrom
@@ -147,8 +463,7 @@ func parseUserFilters(query url.Values) (filters.UserFilter, error) {
 	if len(to) > 0 {
 		filterTo, err := time.Parse("2006-01-02", to)
 		if err != nil {
-			log.Printf("failed to parse to: %v", err)
-			return filter, errors.New("invalid format for to")
+			return filter, custom_errors.NewValidationError("to", "invalid date format, expected YYYY-MM-DD")
 		}
 
 		filter.ToCreatedAt = &filterTo
@@ -163,9 +478,8 @@ func parseUserFilters(query url.Values) (filters.UserFilter, error) {
 	offset := query.Get("offset")
 	if len(offset) > 0 {
 		filterOffset, err := strconv.Atoi(offset)
-		if err != nil {
-			log.Printf("failed to parse offset: %v", err)
-			return filter, errors.New("invalid format for offset")
+		if err != nil || filterOffset < 0 {
+			return filter, custom_errors.NewValidationError("offset", "must be a non-negative integer")
 		}
 
 		filter.Offset = uint(filterOffset)
@@ -174,18 +488,168 @@ func parseUserFilters(query url.Values) (filters.UserFilter, error) {
 	limit := query.Get("limit")
 	if len(limit) > 0 {
 		filterLimit, err := strconv.Atoi(limit)
-		if err != nil {
-			log.Printf("failed to parse limit: %v", err)
-			return filter, errors.New("invalid format for limit")
+		if err != nil || filterLimit < 1 {
+			return filter, custom_errors.NewValidationError("limit", "must be a positive integer")
 		}
 
-		filter.Limit = uint(filterLimit)
+		filter.Limit = uint(min(filterLimit, maxLimit))
 	} else {
 		filter.Limit = uint(defaultLimit)
 	}
 
+	cursor, err := parseCursor(query)
+	if err != nil {
+		return filter, err
+	}
+
+	filter.Cursor = cursor
+	filter.WithTotal = query.Get("total") == "true"
+
+	// asc and desc are kept for clients ordering by post count before sort fields
 	sort := query.Get("sort")
-	filter.TopPostsAmount = sort
+	if sort == "asc" || sort == "desc" {
+		filter.TopPostsAmount = sort
+	} else {
+		filter.Sort, err = parseSort(sort)
+		if err != nil {
+			return filter, err
+		}
+	}
+
+	filter.Projection, err = parseProjection(query, userFields, userIncludes, nil)
+	if err != nil {
+		return filter, err
+	}
+
+	filter.PostsLimit, err = parsePostsLimit(query)
+	if err != nil {
+		return filter, err
+	}
+
+	filter.Deleted, err = parseDeleted(query)
+	if err != nil {
+		return filter, err
+	}
 
 	return filter, nil
 }
+
+// ifMatchVersions returns resource versions allowed by If-Match header, nil means any version
+func ifMatchVersions(req *http.Request, id int) ([]int, error) {
+	header := req.Header.Get("If-Match")
+	if len(header) == 0 {
+		return nil, nil
+	}
+
+	versions, any := etag.ParseIfMatch(header, id)
+	if any {
+		return nil, nil
+	}
+
+	if len(versions) == 0 {
+		return nil, custom_errors.ErrPreconditionFailed
+	}
+
+	return versions, nil
+}
+
+// parsePage reads limit and cursor params of lists without offset pagination
+func parsePage(query url.Values) (int, *pagination.Cursor, error) {
+	limit := defaultLimit
+	if value := query.Get("limit"); len(value) > 0 {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			return 0, nil, custom_errors.NewValidationError("limit", "must be a positive integer")
+		}
+
+		limit = min(limit, maxLimit)
+	}
+
+	cursor, err := parseCursor(query)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return limit, cursor, nil
+}
+
+// parseCursor decodes cursor query param
+func parseCursor(query url.Values) (*pagination.Cursor, error) {
+	token := query.Get("cursor")
+	if len(token) == 0 {
+		return nil, nil
+	}
+
+	cursor, err := pagination.Decode(token)
+	if err != nil {
+		return nil, custom_errors.NewValidationError("cursor", err.Error())
+	}
+
+	return cursor, nil
+}
+
+// parseBare reads paging param of user and post lists. The lists are plain item arrays as before cursors,
+// their cursors and total are sent in Link and X-Total-Count headers. paging=cursor answers with page envelope
+func parseBare(query url.Values) (bool, error) {
+	switch query.Get("paging") {
+	case "":
+		return true, nil
+	case "cursor":
+		return false, nil
+	}
+
+	return false, custom_errors.NewValidationError("paging", "must be cursor")
+}
+
+func validateUserUpdate(userReq filters.UserUpdateRequest) error {
+	if userReq.Name.Set {
+		if userReq.Name.Null {
+			return custom_errors.NewValidationError("name", "can't be null")
+		}
+
+		if err := validateUserName(userReq.Name.Value); err != nil {
+			return err
+		}
+	}
+
+	if userReq.Phonenumber.Set {
+		if userReq.Phonenumber.Null {
+			return custom_errors.NewValidationError("phonenumber", "can't be null")
+		}
+
+		if err := validatePhonenumber(userReq.Phonenumber.Value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateUserName(name string) error {
+	if len(name) == 0 {
+		return custom_errors.NewValidationError("name", "is required")
+	}
+
+	if utf8.RuneCountInString(name) > maxUserNameLength {
+		return custom_errors.NewValidationError("name", fmt.Sprintf("must be at most %d characters", maxUserNameLength))
+	}
+
+	return nil
+}
+
+func validatePhonenumber(phone string) error {
+	if len(phone) == 0 {
+		return custom_errors.NewValidationError("phonenumber", "is required")
+	}
+
+	if len(phone) > maxPhonenumberLength {
+		return custom_errors.NewValidationError("phonenumber", fmt.Sprintf("must be at most %d characters", maxPhonenumberLength))
+	}
+
+	if !phonenumberRegexp.MatchString(phone) {
+		return custom_errors.NewValidationError("phonenumber", "must contain only digits with optional leading +")
+	}
+
+	return nil
+}
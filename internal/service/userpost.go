@@ -3,19 +3,26 @@ package service
 import (
 	"context"
 	"encoding/json"
-	"errors"
+	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/trad3r/hskills/apirest/internal/custom_errors"
+	"github.com/trad3r/hskills/apirest/internal/models"
+	"github.com/trad3r/hskills/apirest/internal/policy"
 	"github.com/trad3r/hskills/apirest/internal/repository/filters"
 )
 
 type IUserPostService interface {
 	AddPost(req *http.Request) error
+	AddPosts(req *http.Request) ([]models.BulkResult, error)
+	AuthorPosts(userId int, req *http.Request) (*models.Page[models.Post], error)
+	AddAuthorPost(userId int, req *http.Request) error
+	ImportPosts(req *http.Request) (*models.ImportReport, error)
+	ImportPostsFrom(ctx context.Context, format string, r io.Reader, opts ImportOptions) (*models.ImportReport, error)
 }
 
 type UserPostService struct {
@@ -36,48 +43,189 @@ func (up *UserPostService) AddPost(req *http.Request) error {
 	if req.Body != nil {
 		reqBody, err := io.ReadAll(req.Body)
 		if err != nil {
-			log.Printf("Failed to read body: %v", err)
-			return errors.New("Failed to read body")
+			return fmt.Errorf("failed to read body: %w", err)
 		}
 
 		err = json.Unmarshal(reqBody, &postAddReq)
 		if err != nil {
-			log.Printf("Failed to unmarshal body: %v", err)
-			return errors.New("Failed to unmarshal body")
+			return fmt.Errorf("%w: %s", custom_errors.ErrInvalidBody, err)
 		}
 	}
 
 	if len(postAddReq.Subject) == 0 {
-		return errors.New("subject field is required")
+		return custom_errors.NewValidationError("subject", "is required")
 	}
 
 	if postAddReq.Author < 1 {
-		return errors.New("author field is required")
+		return custom_errors.NewValidationError("author", "is required")
+	}
+
+	if err := policy.CreatePost(policy.ActorFrom(ctx), postAddReq.Author); err != nil {
+		return err
 	}
 
 	author, err := up.u.FindByID(ctx, postAddReq.Author)
 	if err != nil {
-		log.Printf("failed to get author: %v", err)
-		return errors.New("failed to check author")
+		return fmt.Errorf("failed to get author: %w", err)
+	}
+
+	if author == nil {
+		return custom_errors.NewValidationError("author", "does not exist")
+	}
+
+	return up.p.PostAdd(ctx, postAddReq, *author)
+}
+
+func (up *UserPostService) AddPosts(req *http.Request) ([]models.BulkResult, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), time.Minute)
+	defer cancel()
+
+	atomic, err := parseBulkMode(req.URL.Query())
+	if err != nil {
+		return nil, err
+	}
+
+	var items []filters.PostAddRequest
+	if err := decodeBulk(req, &items); err != nil {
+		return nil, err
+	}
+
+	actor := policy.ActorFrom(ctx)
+	authors := make(map[int]*models.User)
+	posts := make([]*models.Post, len(items))
+
+	results, err := bulkApply(len(items), atomic, func(i int) error {
+		if len(items[i].Subject) == 0 {
+			return custom_errors.NewValidationError("subject", "is required")
+		}
+
+		if items[i].Author < 1 {
+			return custom_errors.NewValidationError("author", "is required")
+		}
+
+		if err := policy.CreatePost(actor, items[i].Author); err != nil {
+			return err
+		}
+
+		author, ok := authors[items[i].Author]
+		if !ok {
+			var err error
+			author, err = up.u.FindByID(ctx, items[i].Author)
+			if err != nil {
+				return fmt.Errorf("failed to get author: %w", err)
+			}
+
+			authors[items[i].Author] = author
+		}
+
+		if author == nil {
+			return custom_errors.NewValidationError("author", "does not exist")
+		}
+
+		tags, err := normalizeTags(items[i].Tags, "tags")
+		if err != nil {
+			return err
+		}
+
+		status, publishAt, err := newPostStatus(items[i].Status, items[i].PublishAt, time.Now())
+		if err != nil {
+			return err
+		}
+
+		posts[i] = &models.Post{Subject: items[i].Subject, Body: items[i].Body, Tags: tags, Status: status, PublishAt: publishAt, Author: author}
+
+		return nil
+	}, func(valid []int) ([]error, error) {
+		batch := make([]*models.Post, 0, len(valid))
+		for _, i := range valid {
+			batch = append(batch, posts[i])
+		}
+
+		return up.p.PostBulkAdd(ctx, batch, atomic)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range results {
+		if results[i].Err == nil {
+			results[i].ID = posts[i].ID
+		}
+	}
+
+	return results, nil
+}
+
+// AuthorPosts lists posts of existing user
+func (up *UserPostService) AuthorPosts(userId int, req *http.Request) (*models.Page[models.Post], error) {
+	ctx, cancel := context.WithTimeout(req.Context(), time.Second*10)
+	defer cancel()
+
+	if _, err := up.existingAuthor(ctx, userId); err != nil {
+		return nil, err
+	}
+
+	return up.p.PostListByAuthor(userId, req)
+}
+
+// AddAuthorPost adds post of existing user, author is taken from path
+func (up *UserPostService) AddAuthorPost(userId int, req *http.Request) error {
+	ctx, cancel := context.WithTimeout(req.Context(), time.Second*10)
+	defer cancel()
+
+	if err := policy.CreatePost(policy.ActorFrom(ctx), userId); err != nil {
+		return err
+	}
+
+	author, err := up.existingAuthor(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	body, err := readBody(req)
+	if err != nil {
+		return err
+	}
+
+	var postAddReq filters.PostAddRequest
+	if err := decodeJSON(body, &postAddReq, false); err != nil {
+		return err
+	}
+
+	if len(postAddReq.Subject) == 0 {
+		return custom_errors.NewValidationError("subject", "is required")
+	}
+
+	if postAddReq.Author != 0 && postAddReq.Author != userId {
+		return custom_errors.NewValidationError("author", "must match user from path")
+	}
+
+	return up.p.PostAdd(ctx, postAddReq, *author)
+}
+
+// existingAuthor returns user or ErrUserNotFound
+func (up *UserPostService) existingAuthor(ctx context.Context, userId int) (*models.User, error) {
+	author, err := up.u.FindByID(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get author: %w", err)
 	}
 
 	if author == nil {
-		log.Printf("Failed to find author with id %d", postAddReq.Author)
-		return errors.New("author does not exist")
+		return nil, custom_errors.ErrUserNotFound
 	}
 
-	return up.p.PostAdd(ctx, postAddReq.Subject, postAddReq.Body, *author)
+	return author, nil
 }
 
 func getIdFromPath(path string) (int, error) {
 	pathParts := strings.Split(path, "/")
 	if len(pathParts) != 3 {
-		return 0, errors.New("Invalid path")
+		return 0, custom_errors.NewValidationError("id", "invalid path")
 	}
 
 	id, err := strconv.Atoi(pathParts[2])
 	if err != nil {
-		return 0, errors.New("Invalid id")
+		return 0, custom_errors.NewValidationError("id", "must be an integer")
 	}
 
 	return id, nil
@@ -0,0 +1,74 @@
+package slug
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxLength is the longest slug Make returns
+const MaxLength = 80
+
+// cyrillic transliterates Russian, Ukrainian and Belarusian letters, hard and soft signs are dropped
+var cyrillic = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya", 'є': "ye", 'і': "i", 'ї': "yi", 'ґ': "g", 'ў': "u",
+}
+
+// Make returns lowercase ASCII slug of s: Cyrillic is transliterated, accents are stripped
+// and other characters become single dashes. It is empty when s has no letters or digits
+func Make(s string) string {
+	var b strings.Builder
+	dash := false
+
+	write := func(part string) {
+		if len(part) == 0 {
+			return
+		}
+
+		if dash && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+
+		dash = false
+		b.WriteString(part)
+	}
+
+	for _, r := range strings.ToLower(s) {
+		if t, ok := cyrillic[r]; ok {
+			write(t)
+			continue
+		}
+
+		// decomposition splits accented latin letter into base letter and combining marks
+		for _, d := range norm.NFD.String(string(r)) {
+			switch {
+			case d < unicode.MaxASCII && (unicode.IsLetter(d) || unicode.IsDigit(d)):
+				write(string(d))
+			case unicode.Is(unicode.Mn, d):
+			default:
+				dash = true
+			}
+		}
+	}
+
+	return truncate(b.String())
+}
+
+// truncate cuts slug to MaxLength at the last dash when there is one
+func truncate(s string) string {
+	if len(s) <= MaxLength {
+		return s
+	}
+
+	s = s[:MaxLength]
+	if i := strings.LastIndexByte(s, '-'); i > 0 {
+		s = s[:i]
+	}
+
+	return strings.TrimRight(s, "-")
+}
@@ -0,0 +1,49 @@
+package slug_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/trad3r/hskills/apirest/internal/slug"
+)
+
+func TestMake(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		subject string
+		slug    string
+	}{
+		{name: "latin", subject: "Hello World", slug: "hello-world"},
+		{name: "russian", subject: "Привет, мир!", slug: "privet-mir"},
+		{name: "multi letter", subject: "Щука ищет жёлтую юбку", slug: "shchuka-ishchet-zhyoltuyu-yubku"},
+		{name: "signs are dropped", subject: "Объявление о подъезде", slug: "obyavlenie-o-podezde"},
+		{name: "ukrainian", subject: "Їжак і ґудзик", slug: "yizhak-i-gudzik"},
+		{name: "accents", subject: "Crème brûlée", slug: "creme-brulee"},
+		{name: "separators are collapsed", subject: "  a -- b__c  ", slug: "a-b-c"},
+		{name: "digits", subject: "Go 1.22 release", slug: "go-1-22-release"},
+		{name: "no letters", subject: "!!! ???", slug: ""},
+		{name: "other scripts", subject: "日本 tokyo", slug: "tokyo"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.slug, slug.Make(tc.subject))
+		})
+	}
+}
+
+func TestMakeLong(t *testing.T) {
+	t.Parallel()
+
+	s := slug.Make(strings.Repeat("слово ", 30))
+
+	assert.LessOrEqual(t, len(s), slug.MaxLength)
+	assert.True(t, strings.HasPrefix(s, "slovo-slovo"))
+	assert.False(t, strings.HasSuffix(s, "-"))
+	assert.True(t, strings.HasSuffix(s, "slovo"))
+}
@@ -4,12 +4,18 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"path/filepath"
+	"runtime"
 	"testing"
 	"time"
 
 	"github.com/go-testfixtures/testfixtures/v3"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
+	"github.com/trad3r/hskills/apirest/internal/migrator"
+	"github.com/trad3r/hskills/apirest/internal/storage"
 	"github.com/trad3r/hskills/apirest/internal/testpostgres"
 )
 
@@ -39,6 +45,32 @@ func PreparePostgres(t *testing.T) (connStr string) {
 	return connStr
 }
 
+// PrepareDB starts PostgreSQL with migrations and fixtures applied and returns pool connected to it
+func PrepareDB(t *testing.T) *pgxpool.Pool {
+	dsn := PreparePostgres(t)
+
+	root := projectRoot()
+
+	err := migrator.ApplyPostgresMigrations(filepath.Join(root, "migrations"), dsn)
+	require.NoError(t, err)
+
+	err = RunFixtures(filepath.Join(root, "fixtures"), dsn)
+	require.NoError(t, err)
+
+	db, err := storage.NewDB(context.Background(), dsn)
+	require.NoError(t, err)
+
+	t.Cleanup(db.Close)
+
+	return db
+}
+
+// projectRoot is the module directory, tests of any package find migrations and fixtures from it
+func projectRoot() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..")
+}
+
 func RunFixtures(fixturesPath string, dsn string) error {
 	var db *sql.DB
 	var fixtures *testfixtures.Loader
@@ -70,3 +102,37 @@ func skipShort(t *testing.T) {
 		t.Skip("skipping test in short mode")
 	}
 }
+
+// PrepareMinio starts MinIO as a local stand-in of S3 and returns its endpoint and credentials
+func PrepareMinio(t *testing.T) (endpoint string, accessKey string, secretKey string) {
+	skipShort(t)
+	ctx := context.Background()
+
+	accessKey, secretKey = "minioadmin", "minioadmin"
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "minio/minio:RELEASE.2024-07-16T23-46-41Z",
+			ExposedPorts: []string{"9000/tcp"},
+			Env: map[string]string{
+				"MINIO_ROOT_USER":     accessKey,
+				"MINIO_ROOT_PASSWORD": secretKey,
+			},
+			Cmd:        []string{"server", "/data"},
+			WaitingFor: wait.ForHTTP("/minio/health/live").WithPort("9000/tcp").WithStartupTimeout(time.Minute),
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate minio container: %s", err)
+		}
+	})
+
+	endpoint, err = container.PortEndpoint(ctx, "9000/tcp", "")
+	require.NoError(t, err)
+
+	return endpoint, accessKey, secretKey
+}
@@ -0,0 +1,103 @@
+// Package textdiff computes line-level differences between two texts
+package textdiff
+
+import "strings"
+
+// Op is the kind of diff line
+type Op string
+
+const (
+	OpEqual  Op = "equal"
+	OpInsert Op = "insert"
+	OpDelete Op = "delete"
+)
+
+// maxCells limits the LCS table size, larger changes are reported as a whole replacement
+const maxCells = 4_000_000
+
+// Line is a line of the old text, the new text or both
+type Line struct {
+	Op   Op     `json:"op"`
+	Text string `json:"text"`
+}
+
+// Lines returns line diff turning a into b, deleted lines go before inserted ones
+func Lines(a, b string) []Line {
+	return diff(split(a), split(b))
+}
+
+func split(s string) []string {
+	if len(s) == 0 {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+func diff(a, b []string) []Line {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]Line, 0, len(a)+len(b))
+	lines = appendLines(lines, OpEqual, a[:prefix])
+	lines = append(lines, middle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+
+	return appendLines(lines, OpEqual, a[len(a)-suffix:])
+}
+
+// middle diffs the changed part by the longest common subsequence of lines
+func middle(a, b []string) []Line {
+	if len(a) == 0 || len(b) == 0 || (len(a)+1)*(len(b)+1) > maxCells {
+		return appendLines(appendLines(nil, OpDelete, a), OpInsert, b)
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]Line, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, Line{Op: OpEqual, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, Line{Op: OpDelete, Text: a[i]})
+			i++
+		default:
+			lines = append(lines, Line{Op: OpInsert, Text: b[j]})
+			j++
+		}
+	}
+
+	return appendLines(appendLines(lines, OpDelete, a[i:]), OpInsert, b[j:])
+}
+
+func appendLines(lines []Line, op Op, texts []string) []Line {
+	for _, text := range texts {
+		lines = append(lines, Line{Op: op, Text: text})
+	}
+
+	return lines
+}
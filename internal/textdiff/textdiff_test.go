@@ -0,0 +1,44 @@
+package textdiff_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/trad3r/hskills/apirest/internal/textdiff"
+)
+
+func TestLines(t *testing.T) {
+	t.Parallel()
+
+	eq := func(s string) textdiff.Line { return textdiff.Line{Op: textdiff.OpEqual, Text: s} }
+	ins := func(s string) textdiff.Line { return textdiff.Line{Op: textdiff.OpInsert, Text: s} }
+	del := func(s string) textdiff.Line { return textdiff.Line{Op: textdiff.OpDelete, Text: s} }
+
+	testCases := []struct {
+		name     string
+		a, b     string
+		expected []textdiff.Line
+	}{
+		{name: "both empty", a: "", b: "", expected: []textdiff.Line{}},
+		{name: "same", a: "a\nb", b: "a\nb", expected: []textdiff.Line{eq("a"), eq("b")}},
+		{name: "added to empty", a: "", b: "a\nb", expected: []textdiff.Line{ins("a"), ins("b")}},
+		{name: "cleared", a: "a\nb", b: "", expected: []textdiff.Line{del("a"), del("b")}},
+		{name: "trailing newline is ignored", a: "a\n", b: "a", expected: []textdiff.Line{eq("a")}},
+		{name: "line changed", a: "a\nb\nc", b: "a\nx\nc", expected: []textdiff.Line{eq("a"), del("b"), ins("x"), eq("c")}},
+		{name: "line inserted", a: "a\nc", b: "a\nb\nc", expected: []textdiff.Line{eq("a"), ins("b"), eq("c")}},
+		{
+			name:     "lines moved",
+			a:        "a\nb\nc\nd",
+			b:        "b\nc\na\nd",
+			expected: []textdiff.Line{del("a"), eq("b"), eq("c"), ins("a"), eq("d")},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, textdiff.Lines(tc.a, tc.b))
+		})
+	}
+}
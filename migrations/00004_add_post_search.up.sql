@@ -0,0 +1,6 @@
+-- text search configuration is replaced by post_search_config in migration 00018
+ALTER TABLE post ADD COLUMN search tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('russian', coalesce(subject, '')), 'A') ||
+    setweight(to_tsvector('russian', coalesce(body, '')), 'B')
+) STORED;
+CREATE INDEX post_search_idx ON post USING GIN (search);
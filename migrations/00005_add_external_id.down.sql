@@ -0,0 +1,2 @@
+ALTER TABLE author DROP COLUMN external_id;
+ALTER TABLE post DROP COLUMN external_id;
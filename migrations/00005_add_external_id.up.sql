@@ -0,0 +1,2 @@
+ALTER TABLE author ADD COLUMN external_id VARCHAR(64) DEFAULT NULL UNIQUE;
+ALTER TABLE post ADD COLUMN external_id VARCHAR(64) DEFAULT NULL UNIQUE;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS author_deleted_at_idx;
+DROP INDEX IF EXISTS post_deleted_at_idx;
+ALTER TABLE author DROP COLUMN deleted_at;
+ALTER TABLE post DROP COLUMN deleted_at;
@@ -0,0 +1,4 @@
+ALTER TABLE author ADD COLUMN deleted_at TIMESTAMP DEFAULT NULL;
+ALTER TABLE post ADD COLUMN deleted_at TIMESTAMP DEFAULT NULL;
+CREATE INDEX author_deleted_at_idx ON author (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX post_deleted_at_idx ON post (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS post_audit ON post;
+DROP TRIGGER IF EXISTS author_audit ON author;
+DROP TABLE IF EXISTS audit_log;
+DROP FUNCTION IF EXISTS audit_log_append_only();
+DROP FUNCTION IF EXISTS audit_log_write();
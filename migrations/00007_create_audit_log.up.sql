@@ -0,0 +1,76 @@
+CREATE TABLE audit_log
+(
+    id         BIGSERIAL   NOT NULL PRIMARY KEY,
+    entity     VARCHAR(16) NOT NULL,
+    entity_id  INTEGER     NOT NULL,
+    action     VARCHAR(16) NOT NULL,
+    actor      VARCHAR(64) DEFAULT NULL,
+    request_id VARCHAR(64) DEFAULT NULL,
+    before     JSONB       DEFAULT NULL,
+    after      JSONB       DEFAULT NULL,
+    created_at TIMESTAMP   NOT NULL DEFAULT NOW()
+);
+CREATE INDEX audit_log_entity_idx ON audit_log (entity, entity_id, id);
+CREATE INDEX audit_log_created_at_idx ON audit_log (created_at);
+
+-- audit_log_write records row change, actor and request ID are set by the application
+-- with set_config in the writing transaction. Entity name is the trigger argument
+CREATE FUNCTION audit_log_write() RETURNS TRIGGER AS
+$$
+DECLARE
+    hidden     CONSTANT TEXT[] := ARRAY ['search'];
+    row_action VARCHAR(16);
+    old_row    JSONB;
+    new_row    JSONB;
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        old_row := to_jsonb(OLD) - hidden;
+    END IF;
+
+    IF TG_OP <> 'DELETE' THEN
+        new_row := to_jsonb(NEW) - hidden;
+    END IF;
+
+    row_action := CASE TG_OP WHEN 'INSERT' THEN 'create' WHEN 'DELETE' THEN 'purge' ELSE 'update' END;
+    IF TG_OP = 'UPDATE' THEN
+        IF old_row ->> 'deleted_at' IS NULL AND new_row ->> 'deleted_at' IS NOT NULL THEN
+            row_action := 'delete';
+        ELSIF old_row ->> 'deleted_at' IS NOT NULL AND new_row ->> 'deleted_at' IS NULL THEN
+            row_action := 'restore';
+        END IF;
+    END IF;
+
+    INSERT INTO audit_log (entity, entity_id, action, actor, request_id, before, after)
+    VALUES (TG_ARGV[0], (COALESCE(new_row, old_row) ->> 'id')::INTEGER, row_action,
+            NULLIF(current_setting('audit.actor', true), ''),
+            NULLIF(current_setting('audit.request_id', true), ''),
+            old_row, new_row);
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER author_audit
+    AFTER INSERT OR UPDATE OR DELETE
+    ON author
+    FOR EACH ROW
+EXECUTE FUNCTION audit_log_write('user');
+
+CREATE TRIGGER post_audit
+    AFTER INSERT OR UPDATE OR DELETE
+    ON post
+    FOR EACH ROW
+EXECUTE FUNCTION audit_log_write('post');
+
+CREATE FUNCTION audit_log_append_only() RETURNS TRIGGER AS
+$$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE OR TRUNCATE
+    ON audit_log
+    FOR EACH STATEMENT
+EXECUTE FUNCTION audit_log_append_only();
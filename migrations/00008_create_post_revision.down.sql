@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS post_revision ON post;
+DROP FUNCTION IF EXISTS post_revision_write();
+DROP TABLE IF EXISTS post_revision;
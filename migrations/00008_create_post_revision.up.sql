@@ -0,0 +1,41 @@
+CREATE TABLE post_revision
+(
+    post_id    INTEGER      NOT NULL,
+    revision   INTEGER      NOT NULL,
+    subject    VARCHAR(255),
+    body       TEXT                  DEFAULT NULL,
+    actor      VARCHAR(64)           DEFAULT NULL,
+    created_at TIMESTAMP    NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (post_id, revision),
+    CONSTRAINT fk_post FOREIGN KEY (post_id) REFERENCES post (id) ON DELETE CASCADE
+);
+
+INSERT INTO post_revision (post_id, revision, subject, body, created_at)
+SELECT id, 1, subject, body, COALESCE(updated_at, created_at, NOW())
+FROM post;
+
+-- post_revision_write stores subject and body of created or edited post as its next revision.
+-- Concurrent edits of a post are serialized by the post row lock
+CREATE FUNCTION post_revision_write() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        IF NEW.subject IS NOT DISTINCT FROM OLD.subject AND NEW.body IS NOT DISTINCT FROM OLD.body THEN
+            RETURN NULL;
+        END IF;
+    END IF;
+
+    INSERT INTO post_revision (post_id, revision, subject, body, actor)
+    SELECT NEW.id, COALESCE(MAX(revision), 0) + 1, NEW.subject, NEW.body, NULLIF(current_setting('audit.actor', true), '')
+    FROM post_revision
+    WHERE post_id = NEW.id;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER post_revision
+    AFTER INSERT OR UPDATE OF subject, body
+    ON post
+    FOR EACH ROW
+EXECUTE FUNCTION post_revision_write();
@@ -0,0 +1,16 @@
+CREATE TABLE tag
+(
+    id         SERIAL      NOT NULL PRIMARY KEY,
+    name       VARCHAR(32) NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE post_tag
+(
+    post_id INTEGER NOT NULL,
+    tag_id  INTEGER NOT NULL,
+    PRIMARY KEY (post_id, tag_id),
+    CONSTRAINT fk_post FOREIGN KEY (post_id) REFERENCES post (id) ON DELETE CASCADE,
+    CONSTRAINT fk_tag FOREIGN KEY (tag_id) REFERENCES tag (id) ON DELETE CASCADE
+);
+CREATE INDEX post_tag_tag_id_idx ON post_tag (tag_id);
@@ -0,0 +1,16 @@
+CREATE TABLE comment
+(
+    id         SERIAL    NOT NULL PRIMARY KEY,
+    post_id    INTEGER   NOT NULL,
+    parent_id  INTEGER            DEFAULT NULL,
+    author_id  INTEGER   NOT NULL,
+    body       TEXT      NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP          DEFAULT NULL,
+    version    INTEGER   NOT NULL DEFAULT 1,
+    CONSTRAINT fk_post FOREIGN KEY (post_id) REFERENCES post (id) ON DELETE CASCADE,
+    CONSTRAINT fk_parent FOREIGN KEY (parent_id) REFERENCES comment (id) ON DELETE CASCADE,
+    CONSTRAINT fk_author FOREIGN KEY (author_id) REFERENCES author (id) ON DELETE CASCADE
+);
+CREATE INDEX comment_post_id_idx ON comment (post_id, created_at, id) WHERE parent_id IS NULL;
+CREATE INDEX comment_parent_id_idx ON comment (parent_id, created_at, id);
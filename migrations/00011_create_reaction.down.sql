@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS reaction_count ON reaction;
+DROP FUNCTION IF EXISTS reaction_count_write();
+DROP TABLE IF EXISTS post_reaction_count;
+DROP TABLE IF EXISTS reaction;
@@ -0,0 +1,46 @@
+CREATE TABLE reaction
+(
+    post_id    INTEGER     NOT NULL,
+    user_id    INTEGER     NOT NULL,
+    type       VARCHAR(16) NOT NULL,
+    created_at TIMESTAMP   NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (post_id, user_id, type),
+    CONSTRAINT fk_post FOREIGN KEY (post_id) REFERENCES post (id) ON DELETE CASCADE,
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES author (id) ON DELETE CASCADE
+);
+CREATE INDEX reaction_user_id_idx ON reaction (user_id);
+
+-- counters are kept by reaction triggers, so cascaded deletes of users keep them right
+CREATE TABLE post_reaction_count
+(
+    post_id INTEGER     NOT NULL,
+    type    VARCHAR(16) NOT NULL,
+    count   INTEGER     NOT NULL DEFAULT 0 CHECK (count >= 0),
+    PRIMARY KEY (post_id, type),
+    CONSTRAINT fk_post FOREIGN KEY (post_id) REFERENCES post (id) ON DELETE CASCADE
+);
+CREATE INDEX post_reaction_count_type_idx ON post_reaction_count (type, count);
+
+CREATE FUNCTION reaction_count_write() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO post_reaction_count (post_id, type, count)
+        VALUES (NEW.post_id, NEW.type, 1)
+        ON CONFLICT (post_id, type) DO UPDATE SET count = post_reaction_count.count + 1;
+    ELSE
+        UPDATE post_reaction_count
+        SET count = count - 1
+        WHERE post_id = OLD.post_id
+          AND type = OLD.type;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER reaction_count
+    AFTER INSERT OR DELETE
+    ON reaction
+    FOR EACH ROW
+EXECUTE FUNCTION reaction_count_write();
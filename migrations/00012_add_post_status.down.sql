@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS post_scheduled_idx;
+
+ALTER TABLE post
+    DROP CONSTRAINT IF EXISTS post_scheduled_publish_at,
+    DROP COLUMN IF EXISTS publish_at,
+    DROP COLUMN IF EXISTS status;
@@ -0,0 +1,8 @@
+-- existing posts and rows inserted without status are published, the API creates drafts explicitly
+ALTER TABLE post
+    ADD COLUMN status     VARCHAR(16) NOT NULL DEFAULT 'published'
+        CHECK (status IN ('draft', 'scheduled', 'published', 'archived')),
+    ADD COLUMN publish_at TIMESTAMP            DEFAULT NULL,
+    ADD CONSTRAINT post_scheduled_publish_at CHECK (status <> 'scheduled' OR publish_at IS NOT NULL);
+
+CREATE INDEX post_scheduled_idx ON post (publish_at) WHERE status = 'scheduled' AND deleted_at IS NULL;
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS attachment_orphan ON attachment;
+DROP FUNCTION IF EXISTS attachment_orphan_write();
+DROP TABLE IF EXISTS attachment_orphan;
+DROP TABLE IF EXISTS attachment;
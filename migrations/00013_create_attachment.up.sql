@@ -0,0 +1,35 @@
+CREATE TABLE attachment
+(
+    id           SERIAL       NOT NULL PRIMARY KEY,
+    post_id      INTEGER      NOT NULL,
+    storage_key  VARCHAR(255) NOT NULL UNIQUE,
+    filename     VARCHAR(255) NOT NULL,
+    content_type VARCHAR(127) NOT NULL,
+    size         BIGINT       NOT NULL,
+    checksum     CHAR(64)     NOT NULL,
+    created_at   TIMESTAMP    NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_post FOREIGN KEY (post_id) REFERENCES post (id) ON DELETE CASCADE
+);
+CREATE INDEX attachment_post_id_idx ON attachment (post_id, id);
+
+-- objects of removed attachments, cascaded removals of purged posts included, wait here for the cleanup job
+CREATE TABLE attachment_orphan
+(
+    storage_key VARCHAR(255) NOT NULL PRIMARY KEY,
+    created_at  TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+CREATE FUNCTION attachment_orphan_write() RETURNS TRIGGER AS
+$$
+BEGIN
+    INSERT INTO attachment_orphan (storage_key) VALUES (OLD.storage_key) ON CONFLICT DO NOTHING;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER attachment_orphan
+    AFTER DELETE
+    ON attachment
+    FOR EACH ROW
+EXECUTE FUNCTION attachment_orphan_write();
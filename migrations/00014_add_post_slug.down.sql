@@ -0,0 +1,11 @@
+DROP TRIGGER IF EXISTS post_slug_history ON post;
+DROP TRIGGER IF EXISTS post_slug_default ON post;
+DROP FUNCTION IF EXISTS post_slug_history();
+DROP FUNCTION IF EXISTS post_slug_default();
+DROP FUNCTION IF EXISTS post_slug_allocate(TEXT, INTEGER);
+
+ALTER TABLE post
+    DROP CONSTRAINT IF EXISTS post_slug_unique,
+    DROP COLUMN IF EXISTS slug;
+
+DROP TABLE IF EXISTS post_slug;
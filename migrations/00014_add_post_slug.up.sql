@@ -0,0 +1,75 @@
+-- every slug a post ever had stays here, so old links keep resolving after subject changes
+CREATE TABLE post_slug
+(
+    slug       VARCHAR(96) NOT NULL PRIMARY KEY,
+    post_id    INTEGER     NOT NULL,
+    created_at TIMESTAMP   NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_post FOREIGN KEY (post_id) REFERENCES post (id) ON DELETE CASCADE
+);
+CREATE INDEX post_slug_post_id_idx ON post_slug (post_id);
+
+ALTER TABLE post
+    ADD COLUMN slug VARCHAR(96);
+
+-- post_slug_allocate returns base or the first free base-N not taken by other posts.
+-- The lock serializes allocations of the same base until the end of transaction
+CREATE FUNCTION post_slug_allocate(base TEXT, owner INTEGER) RETURNS TEXT AS
+$$
+DECLARE
+    candidate TEXT    := base;
+    n         INTEGER := 1;
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('post_slug'), hashtext(base));
+
+    WHILE EXISTS (SELECT 1 FROM post_slug WHERE slug = candidate AND post_id IS DISTINCT FROM owner)
+        OR EXISTS (SELECT 1 FROM post WHERE slug = candidate AND id IS DISTINCT FROM owner)
+        LOOP
+            n := n + 1;
+            candidate := base || '-' || n;
+        END LOOP;
+
+    RETURN candidate;
+END;
+$$ LANGUAGE plpgsql;
+
+-- rows inserted without slug, like imports from SQL, are addressable by post-<id>
+CREATE FUNCTION post_slug_default() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF NEW.slug IS NULL THEN
+        NEW.slug := post_slug_allocate('post-' || NEW.id, NEW.id);
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER post_slug_default
+    BEFORE INSERT OR UPDATE OF slug
+    ON post
+    FOR EACH ROW
+EXECUTE FUNCTION post_slug_default();
+
+CREATE FUNCTION post_slug_history() RETURNS TRIGGER AS
+$$
+BEGIN
+    INSERT INTO post_slug (slug, post_id) VALUES (NEW.slug, NEW.id) ON CONFLICT DO NOTHING;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER post_slug_history
+    AFTER INSERT OR UPDATE OF slug
+    ON post
+    FOR EACH ROW
+EXECUTE FUNCTION post_slug_history();
+
+-- existing posts get default slugs, the backfill is not an audited change
+ALTER TABLE post DISABLE TRIGGER post_audit;
+UPDATE post SET slug = NULL;
+ALTER TABLE post ENABLE TRIGGER post_audit;
+
+ALTER TABLE post
+    ALTER COLUMN slug SET NOT NULL,
+    ADD CONSTRAINT post_slug_unique UNIQUE (slug);
@@ -0,0 +1,43 @@
+DROP TABLE IF EXISTS refresh_token;
+DROP TABLE IF EXISTS auth_session;
+
+ALTER TABLE author
+    DROP CONSTRAINT IF EXISTS author_credentials,
+    DROP CONSTRAINT IF EXISTS author_login_unique,
+    DROP COLUMN IF EXISTS password_hash,
+    DROP COLUMN IF EXISTS login;
+
+CREATE OR REPLACE FUNCTION audit_log_write() RETURNS TRIGGER AS
+$$
+DECLARE
+    hidden     CONSTANT TEXT[] := ARRAY ['search'];
+    row_action VARCHAR(16);
+    old_row    JSONB;
+    new_row    JSONB;
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        old_row := to_jsonb(OLD) - hidden;
+    END IF;
+
+    IF TG_OP <> 'DELETE' THEN
+        new_row := to_jsonb(NEW) - hidden;
+    END IF;
+
+    row_action := CASE TG_OP WHEN 'INSERT' THEN 'create' WHEN 'DELETE' THEN 'purge' ELSE 'update' END;
+    IF TG_OP = 'UPDATE' THEN
+        IF old_row ->> 'deleted_at' IS NULL AND new_row ->> 'deleted_at' IS NOT NULL THEN
+            row_action := 'delete';
+        ELSIF old_row ->> 'deleted_at' IS NOT NULL AND new_row ->> 'deleted_at' IS NULL THEN
+            row_action := 'restore';
+        END IF;
+    END IF;
+
+    INSERT INTO audit_log (entity, entity_id, action, actor, request_id, before, after)
+    VALUES (TG_ARGV[0], (COALESCE(new_row, old_row) ->> 'id')::INTEGER, row_action,
+            NULLIF(current_setting('audit.actor', true), ''),
+            NULLIF(current_setting('audit.request_id', true), ''),
+            old_row, new_row);
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,66 @@
+-- authors without credentials can not log in, password_hash is an argon2id PHC string
+ALTER TABLE author
+    ADD COLUMN login         VARCHAR(64)  DEFAULT NULL,
+    ADD COLUMN password_hash VARCHAR(255) DEFAULT NULL,
+    ADD CONSTRAINT author_login_unique UNIQUE (login),
+    ADD CONSTRAINT author_credentials CHECK ((login IS NULL) = (password_hash IS NULL));
+
+-- session is one login, its refresh tokens are rotated on every refresh.
+-- Revoked session invalidates access tokens issued for it
+CREATE TABLE auth_session
+(
+    id         SERIAL    NOT NULL PRIMARY KEY,
+    author_id  INTEGER   NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMP          DEFAULT NULL,
+    CONSTRAINT fk_author FOREIGN KEY (author_id) REFERENCES author (id) ON DELETE CASCADE
+);
+CREATE INDEX auth_session_author_id_idx ON auth_session (author_id);
+
+-- refresh tokens are stored as SHA-256, used token presented again revokes its session
+CREATE TABLE refresh_token
+(
+    token_hash CHAR(64)  NOT NULL PRIMARY KEY,
+    session_id INTEGER   NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at    TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_session FOREIGN KEY (session_id) REFERENCES auth_session (id) ON DELETE CASCADE
+);
+CREATE INDEX refresh_token_session_id_idx ON refresh_token (session_id);
+
+-- logins and password hashes stay out of the audit log
+CREATE OR REPLACE FUNCTION audit_log_write() RETURNS TRIGGER AS
+$$
+DECLARE
+    hidden     CONSTANT TEXT[] := ARRAY ['search', 'login', 'password_hash'];
+    row_action VARCHAR(16);
+    old_row    JSONB;
+    new_row    JSONB;
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        old_row := to_jsonb(OLD) - hidden;
+    END IF;
+
+    IF TG_OP <> 'DELETE' THEN
+        new_row := to_jsonb(NEW) - hidden;
+    END IF;
+
+    row_action := CASE TG_OP WHEN 'INSERT' THEN 'create' WHEN 'DELETE' THEN 'purge' ELSE 'update' END;
+    IF TG_OP = 'UPDATE' THEN
+        IF old_row ->> 'deleted_at' IS NULL AND new_row ->> 'deleted_at' IS NOT NULL THEN
+            row_action := 'delete';
+        ELSIF old_row ->> 'deleted_at' IS NOT NULL AND new_row ->> 'deleted_at' IS NULL THEN
+            row_action := 'restore';
+        END IF;
+    END IF;
+
+    INSERT INTO audit_log (entity, entity_id, action, actor, request_id, before, after)
+    VALUES (TG_ARGV[0], (COALESCE(new_row, old_row) ->> 'id')::INTEGER, row_action,
+            NULLIF(current_setting('audit.actor', true), ''),
+            NULLIF(current_setting('audit.request_id', true), ''),
+            old_row, new_row);
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,16 @@
+-- keys of machine clients, the key itself is shown once and only its SHA-256 is stored
+CREATE TABLE api_key
+(
+    id           SERIAL        NOT NULL PRIMARY KEY,
+    name         VARCHAR(64)   NOT NULL,
+    prefix       VARCHAR(16)   NOT NULL UNIQUE,
+    key_hash     CHAR(64)      NOT NULL UNIQUE,
+    scopes       VARCHAR(16)[] NOT NULL,
+    created_by   INTEGER                DEFAULT NULL,
+    created_at   TIMESTAMP     NOT NULL DEFAULT NOW(),
+    expires_at   TIMESTAMP              DEFAULT NULL,
+    rotated_at   TIMESTAMP              DEFAULT NULL,
+    last_used_at TIMESTAMP              DEFAULT NULL,
+    revoked_at   TIMESTAMP              DEFAULT NULL,
+    CONSTRAINT fk_author FOREIGN KEY (created_by) REFERENCES author (id) ON DELETE SET NULL
+);
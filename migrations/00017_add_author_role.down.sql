@@ -0,0 +1,2 @@
+ALTER TABLE author
+    DROP COLUMN IF EXISTS role;
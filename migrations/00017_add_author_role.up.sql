@@ -0,0 +1,4 @@
+-- role decides what author may change besides own posts and profile
+ALTER TABLE author
+    ADD COLUMN role VARCHAR(16) NOT NULL DEFAULT 'author'
+        CHECK (role IN ('author', 'moderator', 'admin'));
@@ -0,0 +1,8 @@
+ALTER TABLE post DROP COLUMN search;
+ALTER TABLE post ADD COLUMN search tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('russian', coalesce(subject, '')), 'A') ||
+    setweight(to_tsvector('russian', coalesce(body, '')), 'B')
+) STORED;
+CREATE INDEX post_search_idx ON post USING GIN (search);
+
+DROP FUNCTION IF EXISTS post_search_config();
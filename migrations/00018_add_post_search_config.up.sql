@@ -0,0 +1,12 @@
+-- search column and queries take text search configuration from post_search_config,
+-- the app replaces the function and rebuilds the column when search.config setting changes
+CREATE OR REPLACE FUNCTION post_search_config() RETURNS regconfig
+    LANGUAGE sql IMMUTABLE AS
+$$ SELECT 'russian'::regconfig $$;
+
+ALTER TABLE post DROP COLUMN search;
+ALTER TABLE post ADD COLUMN search tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector(post_search_config(), coalesce(subject, '')), 'A') ||
+    setweight(to_tsvector(post_search_config(), coalesce(body, '')), 'B')
+) STORED;
+CREATE INDEX post_search_idx ON post USING GIN (search);